@@ -0,0 +1,28 @@
+package main
+
+import "strings"
+
+// cygpathLetters are the single-letter flags shared with cygpath, which cygpath
+// accepts combined into a single argument (e.g., "-aw").
+const cygpathLetters = "uwma"
+
+// CygpathArgs returns the given command-line arguments with each combination
+// of cygpath's single-letter flags (e.g., "-aw") split into separate flags
+// ("-a" "-w"), which the flag package otherwise rejects. Arguments following
+// the first non-flag argument or "--" are returned unchanged.
+func CygpathArgs(args []string) []string {
+	out := make([]string, 0, len(args))
+	for i, a := range args {
+		if a == "--" || len(a) < 2 || a[0] != '-' {
+			return append(out, args[i:]...)
+		}
+		if len(a) > 2 && a[1] != '-' && strings.Trim(a[1:], cygpathLetters) == "" {
+			for _, c := range a[1:] {
+				out = append(out, "-"+string(c))
+			}
+			continue
+		}
+		out = append(out, a)
+	}
+	return out
+}
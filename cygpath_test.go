@@ -0,0 +1,24 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCygpathArgs(t *testing.T) {
+	for _, c := range []struct{ in, want []string }{
+		{[]string{"-aw", "x"}, []string{"-a", "-w", "x"}},
+		{[]string{"-am", "-u"}, []string{"-a", "-m", "-u"}},
+		{[]string{"-u", "x"}, []string{"-u", "x"}},
+		{[]string{"--mixed", "-aw"}, []string{"--mixed", "-a", "-w"}},
+		{[]string{"-ax", "x"}, []string{"-ax", "x"}},
+		{[]string{"-", "-aw"}, []string{"-", "-aw"}},
+		{[]string{"--", "-aw"}, []string{"--", "-aw"}},
+		{[]string{"x", "-aw"}, []string{"x", "-aw"}},
+		{[]string{}, []string{}},
+	} {
+		if got := CygpathArgs(c.in); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("CygpathArgs(%q) = %q; want %q", c.in, got, c.want)
+		}
+	}
+}
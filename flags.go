@@ -0,0 +1,299 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ardnew/wslpath/wslpath"
+)
+
+// formatFlag implements flag.Value, parsing a Format name into f.
+type formatFlag struct{ f **wslpath.Format }
+
+func (formatFlag) String() string { return "" }
+
+func (v formatFlag) Set(s string) error {
+	f, err := wslpath.ParseFormat(s)
+	if nil != err {
+		return err
+	}
+	if wslpath.Any == f {
+		return fmt.Errorf("format must be %s or %s", wslpath.Windows, wslpath.Unix)
+	}
+	*v.f = &f
+	return nil
+}
+
+// abbrevList implements flag.Value for appending custom abbreviations.
+type abbrevList struct{ a *[]wslpath.Abbrev }
+
+func (l abbrevList) String() string { return "" }
+
+// Set parses an abbreviation of the form "PREFIX=SHORT".
+func (l abbrevList) Set(s string) error {
+	n := strings.LastIndex(s, "=")
+	if n <= 0 || n == len(s)-1 {
+		return fmt.Errorf("expected PREFIX=SHORT: %q", s)
+	}
+	*l.a = append(*l.a, wslpath.Abbrev{Prefix: s[:n], Short: s[n+1:]})
+	return nil
+}
+
+// psEscape implements flag.Value for selecting the QuoteStyle used with
+// PowerShellEscape. It may be given as a boolean flag, which selects
+// SingleQuote, or with an explicit style "single" or "double".
+type psEscape struct{ q *wslpath.QuoteStyle }
+
+func (p psEscape) IsBoolFlag() bool { return true }
+
+func (p psEscape) String() string {
+	if p.q != nil {
+		switch *p.q {
+		case wslpath.SingleQuote:
+			return "single"
+		case wslpath.DoubleQuote:
+			return "double"
+		}
+	}
+	return ""
+}
+
+func (p psEscape) Set(s string) error {
+	switch strings.ToLower(s) {
+	case "true", "single":
+		*p.q = wslpath.SingleQuote
+	case "false":
+		*p.q = wslpath.NoQuote
+	case "double":
+		*p.q = wslpath.DoubleQuote
+	default:
+		return fmt.Errorf("invalid quote style (single|double): %q", s)
+	}
+	return nil
+}
+
+// varFlag implements flag.Value, defining Windows environment variables of the
+// form NAME=VALUE used for expansion.
+type varFlag struct{ r *wslpath.Resolver }
+
+func (varFlag) String() string { return "" }
+
+func (v varFlag) Set(s string) error {
+	m := strings.SplitN(s, "=", 2)
+	if len(m) != 2 || len(m[0]) == 0 {
+		return fmt.Errorf("expected NAME=VALUE: %q", s)
+	}
+	v.r.SetVar(m[0], m[1])
+	return nil
+}
+
+// mapFileFlag implements flag.Value for loading map files into a Resolver.
+type mapFileFlag struct{ r *wslpath.Resolver }
+
+func (m mapFileFlag) String() string { return "" }
+
+func (m mapFileFlag) Set(s string) error { return m.r.LoadMapFile(s) }
+
+// uncFileFlag implements flag.Value for loading UNC mapping files into a
+// Resolver.
+type uncFileFlag struct{ r *wslpath.Resolver }
+
+func (u uncFileFlag) String() string { return "" }
+
+func (u uncFileFlag) Set(s string) error { return u.r.LoadUNCFile(s) }
+
+// driveVarOrder implements flag.Value for defining the ordered list of
+// environment variables consulted for a drive letter's mount point.
+type driveVarOrder struct{ r *wslpath.Resolver }
+
+func (d driveVarOrder) String() string { return "" }
+
+// Set parses a list of the form "[X=]VAR,VAR,...". If the drive letter X is
+// omitted, it is taken from the first identifier ending in NixPathEnvSuffix.
+func (d driveVarOrder) Set(s string) error {
+	var drive byte
+	if n := strings.IndexRune(s, '='); n != -1 {
+		if v := strings.TrimSuffix(s[:n], ":"); len(v) == 1 && isalpha(v[0]) {
+			drive = v[0]
+		} else {
+			return fmt.Errorf("invalid drive letter: %q", s[:n])
+		}
+		s = s[n+1:]
+	}
+	vars := []string{}
+	for _, e := range strings.Split(s, ",") {
+		if e = strings.TrimSpace(e); len(e) > 0 {
+			vars = append(vars, e)
+			if drive == 0 && strings.HasSuffix(e, wslpath.NixPathEnvSuffix) &&
+				len(e) == len(wslpath.NixPathEnvSuffix)+1 && isalpha(e[0]) {
+				drive = e[0]
+			}
+		}
+	}
+	if len(vars) == 0 {
+		return fmt.Errorf("empty variable list")
+	}
+	if drive == 0 {
+		return fmt.Errorf("cannot determine drive letter (use X=%s)", s)
+	}
+	d.r.SetDriveVars(drive, vars...)
+	return nil
+}
+
+// prefixMapList implements flag.Value for appending PrefixMaps to a Resolver.
+type prefixMapList struct{ r *wslpath.Resolver }
+
+func (p prefixMapList) String() string { return "" }
+
+// Set parses a PrefixMap of the form "FROM=>TO", where FROM and TO are path
+// prefixes given in opposite Formats.
+func (p prefixMapList) Set(s string) error {
+	m := strings.SplitN(s, "=>", 2)
+	if len(m) != 2 || len(m[0]) == 0 || len(m[1]) == 0 {
+		return fmt.Errorf("expected FROM=>TO: %q", s)
+	}
+	f, t := wslpath.Identify(m[0]), wslpath.Identify(m[1])
+	if f == wslpath.Any || t == wslpath.Any || f == t {
+		return fmt.Errorf("prefixes must be Windows and Unix paths: %q", s)
+	}
+	p.r.PrefixMaps = append(p.r.PrefixMaps, wslpath.PrefixMap{From: m[0], To: m[1]})
+	return nil
+}
+
+// relativeRootList implements flag.Value for appending RelativeRoots to a
+// Resolver.
+type relativeRootList struct{ r *wslpath.Resolver }
+
+func (p relativeRootList) String() string { return "" }
+
+// Set parses a RelativeRoot of the form "OLD=NEW", where OLD is a path prefix
+// in the source Format and NEW is a path prefix in the target Format.
+func (p relativeRootList) Set(s string) error {
+	m := strings.SplitN(s, "=", 2)
+	if len(m) != 2 || len(m[0]) == 0 || len(m[1]) == 0 {
+		return fmt.Errorf("expected OLD=NEW: %q", s)
+	}
+	f, t := wslpath.Identify(m[0]), wslpath.Identify(m[1])
+	if f == wslpath.Any || t == wslpath.Any || f == t {
+		return fmt.Errorf("prefixes must be Windows and Unix paths: %q", s)
+	}
+	p.r.RelativeRoots = append(p.r.RelativeRoots, wslpath.PrefixMap{From: m[0], To: m[1]})
+	return nil
+}
+
+// systemDriveFlag implements flag.Value for overriding a Resolver's
+// SystemDrive.
+type systemDriveFlag struct{ r *wslpath.Resolver }
+
+func (d systemDriveFlag) String() string { return "" }
+
+func (d systemDriveFlag) Set(s string) error {
+	s = strings.TrimRight(s, `:\/`)
+	if len(s) != 1 || !isalpha(s[0]) {
+		return fmt.Errorf("invalid drive letter: %q", s)
+	}
+	d.r.SystemDrive = strings.ToUpper(s[:1]) + ":"
+	return nil
+}
+
+// substFlag implements flag.Value for declaring subst drives in a Resolver.
+type substFlag struct{ r *wslpath.Resolver }
+
+func (f substFlag) String() string { return "" }
+
+// Set parses a declaration of the form "X:=TARGET".
+func (f substFlag) Set(s string) error {
+	m := strings.SplitN(s, "=", 2)
+	d := strings.TrimRight(m[0], `:\`)
+	if len(m) != 2 || len(d) != 1 || !isalpha(d[0]) {
+		return fmt.Errorf("expected X:=TARGET: %q", s)
+	}
+	if !wslpath.Windows.IsAbs(m[1]) {
+		return fmt.Errorf("subst target is not an absolute Windows path: %q", m[1])
+	}
+	f.r.SetSubst(d[0], m[1])
+	return nil
+}
+
+// driveRemapFlag implements flag.Value for declaring drive remaps in a
+// Resolver.
+type driveRemapFlag struct{ r *wslpath.Resolver }
+
+func (f driveRemapFlag) String() string { return "" }
+
+// Set parses a declaration of the form "X:=Y:".
+func (f driveRemapFlag) Set(s string) error {
+	m := strings.SplitN(s, "=", 2)
+	if len(m) == 2 {
+		from, to := strings.TrimRight(m[0], `:\`), strings.TrimRight(m[1], `:\`)
+		if len(from) == 1 && isalpha(from[0]) && len(to) == 1 && isalpha(to[0]) {
+			f.r.SetDriveRemap(from[0], to[0])
+			return nil
+		}
+	}
+	return fmt.Errorf("expected X:=Y: %q", s)
+}
+
+// volumeStyle implements flag.Value, parsing a VolumeStyle name ("drive" or
+// "unc") into v.
+type volumeStyle struct{ v *wslpath.VolumeStyle }
+
+func (volumeStyle) String() string { return "" }
+
+func (v volumeStyle) Set(s string) error {
+	switch strings.ToLower(s) {
+	case "drive":
+		*v.v = wslpath.DriveStyle
+	case "unc":
+		*v.v = wslpath.UNCStyle
+	default:
+		return fmt.Errorf("unrecognized volume style: %q (must be drive or unc)", s)
+	}
+	return nil
+}
+
+// volumeGUIDFlag implements flag.Value for associating volume GUIDs with drive
+// letters in a Resolver.
+type volumeGUIDFlag struct{ r *wslpath.Resolver }
+
+func (v volumeGUIDFlag) String() string { return "" }
+
+// Set parses an association of the form "GUID=X:".
+func (v volumeGUIDFlag) Set(s string) error {
+	m := strings.SplitN(s, "=", 2)
+	if len(m) != 2 || len(m[0]) == 0 {
+		return fmt.Errorf("expected GUID=X: %q", s)
+	}
+	d := strings.TrimRight(m[1], `:\`)
+	if len(d) != 1 || !isalpha(d[0]) {
+		return fmt.Errorf("invalid drive letter: %q", m[1])
+	}
+	v.r.SetVolumeGUID(m[0], d)
+	return nil
+}
+
+// volumeFlag implements flag.Value for defining a Resolver's Volume.
+type volumeFlag struct{ r *wslpath.Resolver }
+
+func (volumeFlag) String() string { return "" }
+
+// Set parses a drive (e.g., "D:") or an absolute Windows path, such as a UNC
+// volume (e.g., `\\host\share`).
+func (v volumeFlag) Set(s string) error {
+	vol, _ := wslpath.Windows.SplitVolume(s)
+	if vol == "" || (len(vol) == 2 && !isalpha(vol[0])) ||
+		(len(s) > 2 && !wslpath.Windows.IsAbs(s)) {
+		return fmt.Errorf(`expected X: or an absolute path (e.g., \\HOST\SHARE): %q`, s)
+	}
+	if len(s) == 2 {
+		// a bare drive refers to its root, not its current directory
+		s += `\`
+	}
+	v.r.Volume = wslpath.Windows.Clean(s)
+	return nil
+}
+
+// isalpha returns true if and only if the given byte is an ASCII letter.
+func isalpha(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
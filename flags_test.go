@@ -0,0 +1,257 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ardnew/wslpath/wslpath"
+)
+
+func TestDriveVarOrder(t *testing.T) {
+	for _, c := range []struct {
+		in    string
+		drive byte
+		vars  []string
+	}{
+		{"C_VOLUME_PATH,C_MOUNT,WINDOWS_C", 'C', []string{"C_VOLUME_PATH", "C_MOUNT", "WINDOWS_C"}},
+		{"C_MOUNT, c_VOLUME_PATH", 'C', []string{"C_MOUNT", "c_VOLUME_PATH"}},
+		{"d=WINDOWS_D,D_MOUNT", 'D', []string{"WINDOWS_D", "D_MOUNT"}},
+		{"E:=DATA", 'E', []string{"DATA"}},
+		{"WINDOWS_C", 0, nil},
+		{"CD=WINDOWS_C", 0, nil},
+		{"C=", 0, nil},
+		{"C= , ", 0, nil},
+	} {
+		r := &wslpath.Resolver{}
+		err := driveVarOrder{r}.Set(c.in)
+		if c.drive == 0 {
+			if err == nil {
+				t.Errorf("Set(%q) = %v; want error", c.in, r.DriveVars)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(r.DriveVars[c.drive], c.vars) {
+			t.Errorf("Set(%q) = %v, %v; want %c: %q", c.in, r.DriveVars, err, c.drive, c.vars)
+		}
+	}
+}
+
+func TestPrefixMapList(t *testing.T) {
+	for _, c := range []struct {
+		in   string
+		want *wslpath.PrefixMap
+	}{
+		{`/old/base=>C:\new\base`, &wslpath.PrefixMap{From: "/old/base", To: `C:\new\base`}},
+		{`C:\src=>/workspace`, &wslpath.PrefixMap{From: `C:\src`, To: "/workspace"}},
+		{`/a=>/b`, nil},
+		{`/a=>`, nil},
+		{`=>C:\a`, nil},
+		{`/a=C:\a`, nil},
+	} {
+		r := &wslpath.Resolver{}
+		err := prefixMapList{r}.Set(c.in)
+		if c.want == nil {
+			if err == nil {
+				t.Errorf("Set(%q) = %v; want error", c.in, r.PrefixMaps)
+			}
+		} else if err != nil || len(r.PrefixMaps) != 1 || r.PrefixMaps[0] != *c.want {
+			t.Errorf("Set(%q) = %v, %v; want %v", c.in, r.PrefixMaps, err, *c.want)
+		}
+	}
+}
+
+func TestPSEscape(t *testing.T) {
+	for _, c := range []struct {
+		in   string
+		want wslpath.QuoteStyle
+		ok   bool
+	}{
+		{"true", wslpath.SingleQuote, true},
+		{"single", wslpath.SingleQuote, true},
+		{"Double", wslpath.DoubleQuote, true},
+		{"false", wslpath.NoQuote, true},
+		{"backtick", wslpath.NoQuote, false},
+	} {
+		var q wslpath.QuoteStyle
+		if err := (psEscape{&q}).Set(c.in); (err == nil) != c.ok || q != c.want {
+			t.Errorf("Set(%q) = %d, %v; want %d", c.in, q, err, c.want)
+		}
+	}
+}
+
+func TestSystemDriveFlag(t *testing.T) {
+	for _, c := range []struct{ in, want string }{
+		{"D", "D:"},
+		{"d:", "D:"},
+		{`e:\`, "E:"},
+		{"1:", ""},
+		{"CD:", ""},
+		{"", ""},
+	} {
+		r := &wslpath.Resolver{}
+		err := systemDriveFlag{r}.Set(c.in)
+		if (err == nil) != (c.want != "") || r.SystemDrive != c.want {
+			t.Errorf("Set(%q) = %q, %v; want %q", c.in, r.SystemDrive, err, c.want)
+		}
+	}
+}
+
+func TestVolumeGUIDFlag(t *testing.T) {
+	for _, c := range []struct {
+		in, guid, want string
+	}{
+		{"{0B0FD6A2-55E1}=d:", "0b0fd6a2-55e1", "D:"},
+		{`0b0fd6a2-55e1=E:\`, "0b0fd6a2-55e1", "E:"},
+		{"0b0fd6a2-55e1=CD:", "", ""},
+		{"0b0fd6a2-55e1", "", ""},
+		{"=D:", "", ""},
+	} {
+		r := &wslpath.Resolver{}
+		err := volumeGUIDFlag{r}.Set(c.in)
+		if got := r.VolumeGUIDs[c.guid]; (err == nil) != (c.want != "") || got != c.want {
+			t.Errorf("Set(%q) = %v, %v; want %s=%s", c.in, r.VolumeGUIDs, err, c.guid, c.want)
+		}
+	}
+}
+
+func TestSubstFlag(t *testing.T) {
+	for _, c := range []struct{ in, want string }{
+		{`D:=C:\data`, `C:\data`},
+		{`d=C:\data\`, `C:\data`},
+		{`D:=data`, ""},
+		{`DD:=C:\data`, ""},
+		{`D:`, ""},
+	} {
+		r := &wslpath.Resolver{}
+		err := substFlag{r}.Set(c.in)
+		if got := r.Substs['D']; (err == nil) != (c.want != "") || got != c.want {
+			t.Errorf("Set(%q) = %q, %v; want %q", c.in, got, err, c.want)
+		}
+	}
+}
+
+func TestFormatFlag(t *testing.T) {
+	for _, c := range []struct {
+		in   string
+		want wslpath.Format
+		ok   bool
+	}{
+		{"windows", wslpath.Windows, true},
+		{"Unix", wslpath.Unix, true},
+		{"any", wslpath.Any, false},
+		{"dos", wslpath.Any, false},
+	} {
+		var f *wslpath.Format
+		err := formatFlag{&f}.Set(c.in)
+		if (err == nil) != c.ok || (c.ok && *f != c.want) || (!c.ok && f != nil) {
+			t.Errorf("Set(%q) = %v, %v; want %s", c.in, f, err, c.want)
+		}
+	}
+}
+
+func TestVarFlag(t *testing.T) {
+	for _, c := range []struct {
+		in, key, want string
+		ok            bool
+	}{
+		{`TEMP=C:\Temp`, "TEMP", `C:\Temp`, true},
+		{`appdata=C:\a=b`, "APPDATA", `C:\a=b`, true},
+		{`EMPTY=`, "EMPTY", "", true},
+		{`TEMP`, "TEMP", "", false},
+		{`=C:\Temp`, "", "", false},
+	} {
+		r := &wslpath.Resolver{}
+		err := varFlag{r}.Set(c.in)
+		got, ok := r.Vars[c.key]
+		if (err == nil) != c.ok || ok != c.ok || got != c.want {
+			t.Errorf("Set(%q) = %v, %v; want %s=%q", c.in, r.Vars, err, c.key, c.want)
+		}
+	}
+}
+
+func TestVolumeStyleFlag(t *testing.T) {
+	for _, c := range []struct {
+		in   string
+		want wslpath.VolumeStyle
+		ok   bool
+	}{
+		{"drive", wslpath.DriveStyle, true},
+		{"UNC", wslpath.UNCStyle, true},
+		{"share", wslpath.DriveStyle, false},
+		{"", wslpath.DriveStyle, false},
+	} {
+		v := wslpath.DriveStyle
+		if err := (volumeStyle{&v}).Set(c.in); (err == nil) != c.ok || v != c.want {
+			t.Errorf("Set(%q) = %d, %v; want %d", c.in, v, err, c.want)
+		}
+	}
+}
+
+func TestVolumeFlag(t *testing.T) {
+	for _, c := range []struct {
+		in, want string
+		ok       bool
+	}{
+		{`\\host\share`, `\\host\share\`, true},
+		{`\\host\share\dir\`, `\\host\share\dir`, true},
+		{`d:`, `d:\`, true},
+		{`D:\work`, `D:\work`, true},
+		{`\\host`, "", false},
+		{`1:`, "", false},
+		{`D:work`, "", false},
+		{`work`, "", false},
+	} {
+		r := &wslpath.Resolver{}
+		if err := (volumeFlag{r}).Set(c.in); (err == nil) != c.ok || r.Volume != c.want {
+			t.Errorf("Set(%q) = %q, %v; want %q", c.in, r.Volume, err, c.want)
+		}
+	}
+}
+
+func TestRelativeRootList(t *testing.T) {
+	for _, c := range []struct {
+		in   string
+		want *wslpath.PrefixMap
+	}{
+		{`/mnt/c/proj=D:\build`, &wslpath.PrefixMap{From: "/mnt/c/proj", To: `D:\build`}},
+		{`C:\src=/workspace`, &wslpath.PrefixMap{From: `C:\src`, To: "/workspace"}},
+		{`/a=/b`, nil},
+		{`C:\a=D:\b`, nil},
+		{`/a=`, nil},
+		{`/a`, nil},
+	} {
+		r := &wslpath.Resolver{}
+		err := relativeRootList{r}.Set(c.in)
+		if c.want == nil {
+			if err == nil {
+				t.Errorf("Set(%q) = %v; want error", c.in, r.RelativeRoots)
+			}
+		} else if err != nil || len(r.RelativeRoots) != 1 || r.RelativeRoots[0] != *c.want {
+			t.Errorf("Set(%q) = %v, %v; want %v", c.in, r.RelativeRoots, err, *c.want)
+		}
+	}
+}
+
+func TestDriveRemapFlag(t *testing.T) {
+	for _, c := range []struct {
+		in       string
+		from, to byte
+	}{
+		{"C:=D:", 'C', 'D'},
+		{`c:\=d:\`, 'C', 'D'},
+		{"e=f", 'E', 'F'},
+		{"C:", 0, 0},
+		{"C:=DD:", 0, 0},
+		{"1:=D:", 0, 0},
+	} {
+		r := &wslpath.Resolver{}
+		err := driveRemapFlag{r}.Set(c.in)
+		if c.from == 0 {
+			if err == nil {
+				t.Errorf("Set(%q) = %v; want error", c.in, r.DriveRemaps)
+			}
+		} else if err != nil || len(r.DriveRemaps) != 1 || r.DriveRemaps[c.from] != c.to {
+			t.Errorf("Set(%q) = %v, %v; want %c:=%c:", c.in, r.DriveRemaps, err, c.from, c.to)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// VolumeGroups buffers output paths grouped by the Windows volume (e.g., "C:"
+// or `\\host\share`) of their source path, for printing each group under a
+// header once all paths are processed.
+type VolumeGroups struct {
+	label map[string]string
+	paths map[string][]string
+}
+
+// NewVolumeGroups returns an empty VolumeGroups.
+func NewVolumeGroups() *VolumeGroups {
+	return &VolumeGroups{label: map[string]string{}, paths: map[string][]string{}}
+}
+
+// Add appends the given output path s to the group of the given volume.
+// Volumes are grouped case-insensitively, as Windows compares them, and each
+// group is labeled by the volume as first added.
+func (g *VolumeGroups) Add(volume, s string) {
+	key := strings.ToUpper(volume)
+	if _, ok := g.label[key]; !ok {
+		g.label[key] = volume
+	}
+	g.paths[key] = append(g.paths[key], s)
+}
+
+// Write writes each group, in order of volume, as a header written to hdr,
+// terminated by hsep, followed by the group's paths in sorted order written to
+// out, each terminated by sep. Paths without a volume (e.g., relative paths)
+// are grouped last.
+func (g *VolumeGroups) Write(out io.Writer, sep string, hdr io.Writer, hsep string) {
+	keys := make([]string, 0, len(g.paths))
+	for k := range g.paths {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if (keys[i] == "") != (keys[j] == "") {
+			return keys[j] == ""
+		}
+		return keys[i] < keys[j]
+	})
+	for _, k := range keys {
+		label := g.label[k]
+		if label == "" {
+			label = "no volume"
+		}
+		fmt.Fprint(hdr, "[", label, "]", hsep)
+		p := g.paths[k]
+		sort.Strings(p)
+		for _, s := range p {
+			fmt.Fprint(out, s, sep)
+		}
+	}
+}
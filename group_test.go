@@ -0,0 +1,35 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestVolumeGroups(t *testing.T) {
+	g := NewVolumeGroups()
+	for _, p := range []struct{ volume, s string }{
+		{"D:", "/mnt/d/b"},
+		{"C:", "/mnt/c/z"},
+		{"", "rel/x"},
+		{`\\host\share`, "/mnt/share/x"},
+		{"c:", "/mnt/c/a"},
+		{"D:", "/mnt/d/a"},
+	} {
+		g.Add(p.volume, p.s)
+	}
+	var out, hdr bytes.Buffer
+	g.Write(&out, "\n", &hdr, "\n")
+	if want := "[C:]\n[D:]\n[\\\\host\\share]\n[no volume]\n"; hdr.String() != want {
+		t.Errorf("headers = %q; want %q", hdr.String(), want)
+	}
+	if want := "/mnt/c/a\n/mnt/c/z\n/mnt/d/a\n/mnt/d/b\n/mnt/share/x\nrel/x\n"; out.String() != want {
+		t.Errorf("paths = %q; want %q", out.String(), want)
+	}
+	// headers and paths interleave on a single writer
+	out.Reset()
+	g.Write(&out, "\x00", &out, "\x00")
+	if want := "[C:]\x00/mnt/c/a\x00/mnt/c/z\x00[D:]\x00/mnt/d/a\x00/mnt/d/b\x00" +
+		"[\\\\host\\share]\x00/mnt/share/x\x00[no volume]\x00rel/x\x00"; out.String() != want {
+		t.Errorf("output = %q; want %q", out.String(), want)
+	}
+}
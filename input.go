@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// IsTerminal returns true if and only if the given file is a character device,
+// such as an interactive terminal.
+func IsTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && (fi.Mode()&os.ModeCharDevice) != 0
+}
+
+// timeoutReader wraps an io.Reader, returning an error from Read if no data is
+// received within a given duration.
+type timeoutReader struct {
+	r io.Reader
+	d time.Duration
+	c chan readResult
+}
+
+type readResult struct {
+	n   int
+	err error
+	buf []byte
+}
+
+// TimeoutReader returns an io.Reader that reads from r, failing with an error
+// if any single Read does not complete within the given duration d. If d is
+// not positive, r is returned unmodified.
+func TimeoutReader(r io.Reader, d time.Duration) io.Reader {
+	if d <= 0 {
+		return r
+	}
+	return &timeoutReader{r: r, d: d}
+}
+
+func (t *timeoutReader) Read(p []byte) (int, error) {
+	// a previous Read may have timed out while the underlying Read remained
+	// pending; only start a new one if none is outstanding.
+	if t.c == nil {
+		t.c = make(chan readResult, 1)
+		go func(c chan readResult, n int) {
+			buf := make([]byte, n)
+			n, err := t.r.Read(buf)
+			c <- readResult{n: n, err: err, buf: buf}
+		}(t.c, len(p))
+	}
+	select {
+	case res := <-t.c:
+		t.c = nil
+		return copy(p, res.buf[:res.n]), res.err
+	case <-time.After(t.d):
+		return 0, fmt.Errorf("no input received within %v", t.d)
+	}
+}
+
+// ScanNull is a bufio.SplitFunc that returns each NUL-delimited record of its
+// input, with the delimiter removed. Unlike bufio.ScanLines, no other bytes
+// (e.g., newlines or carriage returns) are special.
+//
+// A trailing NUL is optional: the final record is returned whether or not it
+// is followed by a delimiter, so the output of "find -print0" and hand-crafted
+// input without a trailing NUL produce the same records. An empty final
+// record (i.e., input ending with NUL, or empty input) is not returned.
+func ScanNull(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsTerminal(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	if IsTerminal(r) {
+		t.Error("IsTerminal(pipe) = true; want false")
+	}
+	tty, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tty.Close()
+	if !IsTerminal(tty) {
+		t.Errorf("IsTerminal(%s) = false; want true", os.DevNull)
+	}
+}
+
+func TestTimeoutReader(t *testing.T) {
+	if r := strings.NewReader("x"); TimeoutReader(r, 0) != io.Reader(r) {
+		t.Error("TimeoutReader(r, 0) != r")
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	in := TimeoutReader(r, 10*time.Millisecond)
+	p := make([]byte, 8)
+	if n, err := in.Read(p); err == nil {
+		t.Errorf("Read() = %d, nil; want error", n)
+	}
+	// the pending Read completes once input arrives
+	w.WriteString("C:\\x\n")
+	w.Close()
+	got, err := ioutil.ReadAll(TimeoutReader(in, time.Second))
+	if err != nil || string(got) != "C:\\x\n" {
+		t.Errorf("ReadAll() = %q, %v; want %q", got, err, "C:\\x\n")
+	}
+}
+
+func TestStdinNotice(t *testing.T) {
+	const notice = "reading paths from STDIN"
+	tty, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tty.Close()
+	for _, c := range []struct {
+		name  string
+		stdin io.Reader
+		args  []string
+		want  bool
+	}{
+		{"tty", tty, nil, true},
+		{"tty with timeout", tty, []string{"--stdin-timeout", "1s"}, false},
+		{"pipe", strings.NewReader(""), nil, false},
+	} {
+		var stderr bytes.Buffer
+		cmd := command(nil, c.args...)
+		cmd.Stdin, cmd.Stderr = c.stdin, &stderr
+		cmd.Run()
+		if got := strings.Contains(stderr.String(), notice); got != c.want {
+			t.Errorf("%s: notice printed = %t; want %t (%q)", c.name, got, c.want, stderr.String())
+		}
+	}
+}
+
+func TestScanNull(t *testing.T) {
+	for _, c := range []struct {
+		in   string
+		want []string
+	}{
+		{"C:\\a\x00/mnt/c/b\x00", []string{"C:\\a", "/mnt/c/b"}},
+		{"C:\\a\x00/mnt/c/b", []string{"C:\\a", "/mnt/c/b"}},
+		{"a\nb\r\x00c", []string{"a\nb\r", "c"}},
+		{"a\x00\x00b\x00", []string{"a", "", "b"}},
+		{"\x00", []string{""}},
+		{"", nil},
+	} {
+		s := bufio.NewScanner(strings.NewReader(c.in))
+		s.Split(ScanNull)
+		var got []string
+		for s.Scan() {
+			got = append(got, s.Text())
+		}
+		if err := s.Err(); err != nil || !reflect.DeepEqual(got, c.want) {
+			t.Errorf("ScanNull(%q) = %q, %v; want %q", c.in, got, err, c.want)
+		}
+	}
+}
+
+func TestNullInput(t *testing.T) {
+	env := []string{"C_VOLUME_PATH=/mnt/c"}
+	for _, in := range []string{"C:\\a\x00C:\\b c\x00", "C:\\a\x00C:\\b c"} {
+		got, stderr, _ := run(t, env, in, "-x", "-0")
+		if want := "/mnt/c/a\x00/mnt/c/b c\x00"; got != want {
+			t.Errorf("input %q: got %q; want %q (%s)", in, got, want, stderr)
+		}
+	}
+}
+
+func TestNullNewline(t *testing.T) {
+	env := []string{"C_VOLUME_PATH=/mnt/c"}
+	for _, c := range []struct {
+		args  []string
+		input string
+		want  string
+	}{
+		{[]string{"-w", "-0"}, "/mnt/c/a\nb\x00/mnt/c/c\x00", "C:\\a\nb\x00C:\\c\x00"},
+		{[]string{"-x", "-0"}, "C:\\a\nb\x00", "/mnt/c/a\nb\x00"},
+		// arguments are joined with NUL, so they may contain newlines
+		{[]string{"-w", "-0", "/mnt/c/a\nb", "/mnt/c/c"}, "", "C:\\a\nb\x00C:\\c\x00"},
+	} {
+		if got, stderr, _ := run(t, env, c.input, c.args...); got != c.want {
+			t.Errorf("%q: got %q; want %q (%s)", c.args, got, c.want, stderr)
+		}
+	}
+}
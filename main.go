@@ -1,3 +1,9 @@
+// Command wslpath converts file paths between Windows and the Windows
+// Subsystem for Linux (WSL).
+//
+// The conversion engine is provided by package
+// github.com/ardnew/wslpath/wslpath; package main only wires command-line flags
+// and I/O.
 package main
 
 import (
@@ -7,76 +13,107 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
-)
-
-const version = "0.1.1"
+	"time"
 
-// Format represents an enumeration of possible file path formats.
-type Format int
-
-const (
-	// Windows file paths may contain a volume prefix as either a drive
-	// letter "C:" or a UNC path "\\host\share". Following a drive letter,
-	// an absolute or relative path may be specified, where the former is
-	// expressed with a leading "\", which indicates the path is anchored to
-	// the root of the volume. Relative paths are anchored to the current
-	// directory. UNC paths must always be fully-qualified, absolute file
-	// paths. For more info, see:
-	//
-	//   https://docs.microsoft.com/en-us/dotnet/standard/io/file-path-formats
-	//
-	// Both types of volumes, drive letter or UNC path, are mounted within
-	// the WSL user space as any ordinary mount point, and therefore can be
-	// converted to traditional Unix file paths. Both types of volumes are
-	// mounted in WSL using the "drvfs" file system driver.
-	//
-	// The Windows directory separator is always "\".
-	Windows Format = iota
-
-	// Unix file paths do not distinguish the volume from which a given
-	// file system is provided. Instead, volumes are mounted at any regular
-	// directory path within a single/common file system. This file system
-	// uses "/" as its root path. Any file path containing a leading "/"
-	// is interpreted as an absolute file path; all others are considered
-	// relative file paths.
-	//
-	// Currently, the WSL file system is stored on top of the Windows host
-	// (NTFS) file system. However, accessing files stored in the WSL file
-	// system from the Windows host context is not supported and may lead to
-	// file system corruption. Read operations are considered relatively
-	// safe, but write operations are not. The user must ensure any Windows
-	// application used to read files from the WSL container does not also
-	// write to that file system (e.g., cache or temporary files).
-	//
-	// The Unix directory separator is always "/".
-	Unix
-
-	// Any file paths are simple file names that do not contain a directory
-	// separator, which are valid on both Windows and Unix file systems.
-	Any
+	"github.com/ardnew/wslpath/wslpath"
 )
 
-const (
-	// NixPathEnvSuffix defines the suffix of WSL environment variable
-	// identifiers for variables holding paths to Windows volumes mounted
-	// within WSL user space. The prefix of these identifiers is constructed
-	// dynamically based on the volume specified by a Windows absolute path.
-	NixPathEnvSuffix = "_VOLUME_PATH" // e.g., C_VOLUME_PATH="/mnt/c"
-	// WslRootfsEnvVar holds the Windows-formatted absolute path to the
-	// active WSL distribution's rootfs directory (content is read-only).
-	// For files that exist solely in the WSL virtual rootfs (they are not
-	// stored physically on any host file system), the following environment
-	// variable is used as the path prefix.
-	WslRootfsEnvVar = "WSL_ROOTFS_PATH"
-	UncPathEnvVar   = "WSL_UNC_PATH"
-)
+const version = "0.1.1"
 
 const (
 	toWinFlagDesc = "Convert Unix to Windows file path(s)"
 	toNixFlagDesc = "Convert Windows to Unix file path(s)"
+	trailFlagDesc = "Preserve a trailing separator (e.g., /mnt/c/Users/ to C:\\Users\\)"
+	logclFlagDesc = "Do not resolve symbolic links in Unix paths (default)"
+	physcFlagDesc = "Resolve symbolic links in Unix paths"
+	mixedFlagDesc = "Convert Unix to Windows file path(s) with forward slashes (e.g., C:/Users)"
 	existFlagDesc = "Do not translate paths found only in WSL rootfs"
 	svNumFlagDesc = "Print version number and exit"
+	bArryFlagDesc = "Print all converted paths as a single quoted bash array literal"
+	pArryFlagDesc = "Print all converted paths as a single PowerShell array literal"
+	nUniFlagDesc  = "Replace fullwidth and lookalike path characters (e.g., \"：\") with ASCII"
+	grpVoFlagDesc = "Print paths sorted and grouped under a header of their Windows volume"
+	grpErFlagDesc = "Write the headers of -group-by-volume to STDERR instead of STDOUT"
+	extLnFlagDesc = "Prefix Windows paths longer than MAX_PATH (260) with \\\\?\\"
+	inTxtFlagDesc = "Convert only the quoted (\"...\" or '...') paths within each input line"
+	rsLnkFlagDesc = "Convert the target of each Windows shortcut (.lnk) instead of the file"
+	skBlkFlagDesc = "Print blank input lines unchanged instead of converting them"
+	skCmtFlagDesc = "Print input lines beginning with PREFIX (e.g., #) unchanged"
+	drSkpFlagDesc = "Omit lines skipped by -skip-blank or -skip-comment from output"
+	wStatFlagDesc = "Append a tab and the status (exists, missing, unchecked) of each result"
+	chkMtFlagDesc = "Warn when a configured drive mount point is not drvfs in " + wslpath.DefaultMountTable
+	uncMrFlagDesc = "Map UNC volumes not in $WSL_UNC_PATH (\\\\HOST\\SHARE) to DIR/HOST/SHARE"
+	rfUNCFlagDesc = "Address WSL rootfs paths as \\\\HOST\\$WSL_DISTRO_NAME (e.g., HOST=wsl$)"
+	nullFlagDesc  = "Read and write NUL-terminated paths (e.g., with find -print0)"
+	svFulFlagDesc = "Print version number with Go toolchain and build metadata and exit"
+	drvOrFlagDesc = "Ordered list of environment variables holding a drive's mount point"
+	rRootFlagDesc = "Reanchor paths under prefix OLD (source format) under NEW (target format)"
+	pxMapFlagDesc = "Rewrite paths matching prefix FROM with prefix TO"
+	chgOnFlagDesc = "Only print paths whose conversion differs from the input"
+	prgrsFlagDesc = "Report the number of paths processed to STDERR once per second"
+	psEscFlagDesc = "Escape output for a PowerShell single- or double-quoted string"
+	assrtFlagDesc = "Fail on any input whose detected format is not the given format"
+	pListFlagDesc = "Convert each input as a list of paths (e.g., $PATH or %PATH%)"
+	dropUFlagDesc = "Omit list entries that exist only in WSL rootfs with -path-list or -line-list"
+	lnLstFlagDesc = "Convert each input line as a list of paths separated by -list-sep"
+	lnSepFlagDesc = "Separator of list entries in input lines with -line-list (default \";\")"
+	oSepFlagDesc  = "Terminate each output record with LF (default) or CRLF"
+	canonFlagDesc = "Resolve symlinks, real case, and an absolute, clean path (see below)"
+	expHmFlagDesc = "Expand a leading \"~\" in Unix paths to the user's home directory"
+	tldfyFlagDesc = "Abbreviate Unix output under $HOME as ~/... (not a valid path)"
+	wConfFlagDesc = "Warn on STDERR when mapping sources disagree on a conversion"
+	ancstFlagDesc = "Also print each parent directory of each path up to its volume root"
+	equalFlagDesc = "Exit 0 if the two given paths are equivalent, otherwise exit 1"
+	depthFlagDesc = "Print the number of elements below the volume root of each path"
+	wnVarFlagDesc = "Define Windows environment variable NAME for -expand"
+	colpsFlagDesc = "Remove a drive designator preceding its own mount point (C:/mnt/c)"
+	mkEscFlagDesc = "Escape spaces and \"$\" in output for use in a Makefile"
+	cwdOnFlagDesc = "Print the Unix path of the current directory on Windows drive X:"
+	strctFlagDesc = "Fail if mount points of different drives match a path equally"
+	rlTxtFlagDesc = "Translate relative Unix paths textually without inspecting the file system"
+	mntCIFlagDesc = "Match mount points in Unix paths case-insensitively (e.g., /MNT/C)"
+	eFmtFlagDesc  = "Write errors to STDERR as plain text (default) or lines of JSON"
+	baseFlagDesc  = "Resolve relative paths against directory DIR, given in the source format"
+	drVolFlagDesc = "Anchor Windows paths without a volume to the given volume or directory"
+	infVoFlagDesc = "Anchor Windows paths without a volume to the working directory"
+	uncOnFlagDesc = "Only print paths whose Windows form is a UNC network path"
+	quoteFlagDesc = "Quote output for a POSIX shell (e.g., the \"$\" in \\\\wsl$)"
+	chrtFlagDesc  = "Resolve absolute Unix paths within directory DIR as the root"
+	vStylFlagDesc = "Address drives in Windows output by letter (drive) or UNC share (unc)"
+	timngFlagDesc = "Report conversion time to STDERR after all paths are processed"
+	limtFlagDesc  = "Stop after processing N paths, warning if input remains"
+	psAnyFlagDesc = "Print bare file names (e.g., file.txt) unchanged, even with -w or -x"
+	errAnFlagDesc = "Treat bare file names (e.g., file.txt) as an error"
+	shortFlagDesc = "Print the 8.3 short form of each converted Windows path"
+	stdToFlagDesc = "Fail if no input is read from STDIN within the given duration"
+	expndFlagDesc = "Expand %VAR% references and anchor rooted paths in Windows paths"
+	sysDrFlagDesc = "Override the Windows system drive used by -expand"
+	trJsnFlagDesc = "Write each conversion step to STDERR as a line of JSON"
+	uncFlFlagDesc = "Read UNC volume mappings (UNC=MOUNT) from FILE (default $WSL_UNC_FILE)"
+	mpFilFlagDesc = "Read volume mappings (DRIVE=MOUNT or UNC=MOUNT) from FILE"
+	rSubsFlagDesc = "Replace subst drives with their target before mapping"
+	substFlagDesc = "Declare drive X: as a subst of Windows path TARGET"
+	mxDDtFlagDesc = "Maximum number of \"..\" elements permitted in a path"
+	volIdFlagDesc = "Associate Windows volume GUID with drive letter X:"
+	clsfyFlagDesc = "Print the accessibility (drvfs-rw, rootfs-ro, none) of each path"
+	vscodFlagDesc = "Print a VS Code URI (vscode-remote:// or file://) for each path"
+	dtOnlFlagDesc = "Print the detected format (windows, unix, any) of each path"
+	bsnmeFlagDesc = "Print only the last element of each converted path"
+	drnmeFlagDesc = "Print only the parent directory of each converted path"
+	cmpctFlagDesc = "Abbreviate mount prefixes for display (output is not a valid path)"
+	abbrvFlagDesc = "Abbreviate prefix PREFIX as SHORT with -compact"
+	rCaseFlagDesc = "Correct the case of each path element to match the file system"
+	cntFlFlagDesc = "Prefix errors with their input and exit with the number of failed paths"
+	abortFlagDesc = "Stop at the first path that fails to convert, ignoring remaining input"
+	jsOutFlagDesc = "Print each conversion or error as a line of JSON, even with -0 (see below)"
+	rmDrvFlagDesc = "Replace drive X: with drive Y: in Windows output, as a final step"
+	absltFlagDesc = "Print absolute paths, anchoring relative paths to the working directory"
+	cygUxFlagDesc = "Same as -x, for compatibility with cygpath"
+	cygWnFlagDesc = "Same as -w, for compatibility with cygpath"
+	cygMxFlagDesc = "Same as -m, for compatibility with cygpath"
+	dlOnlFlagDesc = "Interpret a bare letter (e.g., \"C\" or \"C/foo\") as a Windows drive"
 )
 
 func Usage() {
@@ -84,27 +121,231 @@ func Usage() {
 		filepath.Base(os.Args[0]) + " version " + version,
 		"",
 		"Usage:",
-		"\t" + os.Args[0] + " [-w|-x] [-e] [PATH ...]",
+		"\t" + os.Args[0] + " [-w|-x|-m] [-e] [-drive-var-order LIST] [-prefix-map MAP] [PATH ...]",
 		"",
 		"Options:",
 		"\t-w    " + toWinFlagDesc,
 		"\t-x    " + toNixFlagDesc,
+		"\t-m    " + mixedFlagDesc,
+		"\t-t    " + trailFlagDesc,
+		"\t-L    " + logclFlagDesc,
+		"\t-P    " + physcFlagDesc,
 		"\t-e    " + existFlagDesc,
 		"\t-v    " + svNumFlagDesc,
+		"\t-0    " + nullFlagDesc,
+		"",
+		"\t-abbrev PREFIX=SHORT",
+		"\t      " + abbrvFlagDesc,
+		"\t-absolute, -a",
+		"\t      " + absltFlagDesc,
+		"\t-ancestors",
+		"\t      " + ancstFlagDesc,
+		"\t-assert FORMAT",
+		"\t      " + assrtFlagDesc,
+		"\t-base DIR",
+		"\t      " + baseFlagDesc,
+		"\t-basename",
+		"\t      " + bsnmeFlagDesc,
+		"\t-bash-array",
+		"\t      " + bArryFlagDesc,
+		"\t-canonical",
+		"\t      " + canonFlagDesc,
+		"\t-changed-only",
+		"\t      " + chgOnFlagDesc,
+		"\t-chroot DIR",
+		"\t      " + chrtFlagDesc,
+		"\t-classify",
+		"\t      " + clsfyFlagDesc,
+		"\t-collapse-redundant-volume",
+		"\t      " + colpsFlagDesc,
+		"\t-compact",
+		"\t      " + cmpctFlagDesc,
+		"\t-count-failures",
+		"\t      " + cntFlFlagDesc,
+		"\t-cwd-on X:",
+		"\t      " + cwdOnFlagDesc,
+		"\t-depth",
+		"\t      " + depthFlagDesc,
+		"\t-detect-only, -echo-format",
+		"\t      " + dtOnlFlagDesc,
+		"\t-dirname",
+		"\t      " + drnmeFlagDesc,
+		"\t-drop-skipped",
+		"\t      " + drSkpFlagDesc,
+		"\t-drop-unconvertible",
+		"\t      " + dropUFlagDesc,
+		"\t-drive X:|PATH",
+		"\t      " + drVolFlagDesc,
+		"\t-drive-letter-only",
+		"\t      " + dlOnlFlagDesc,
+		"\t-drive-var-order [X=]VAR,VAR,...",
+		"\t      " + drvOrFlagDesc,
+		"\t-equal A B",
+		"\t      " + equalFlagDesc,
+		"\t-error-format plain|json",
+		"\t      " + eFmtFlagDesc,
+		"\t-error-any",
+		"\t      " + errAnFlagDesc,
+		"\t-expand",
+		"\t      " + expndFlagDesc,
+		"\t-expand-home",
+		"\t      " + expHmFlagDesc,
+		"\t-extended-length",
+		"\t      " + extLnFlagDesc,
+		"\t-group-by-volume",
+		"\t      " + grpVoFlagDesc,
+		"\t-group-headers-stderr",
+		"\t      " + grpErFlagDesc,
+		"\t-in-text",
+		"\t      " + inTxtFlagDesc,
+		"\t-infer-volume",
+		"\t      " + infVoFlagDesc,
+		"\t-json",
+		"\t      " + jsOutFlagDesc,
+		"\t-limit N",
+		"\t      " + limtFlagDesc,
+		"\t-line-list",
+		"\t      " + lnLstFlagDesc,
+		"\t-list-sep SEP",
+		"\t      " + lnSepFlagDesc,
+		"\t-make-escape",
+		"\t      " + mkEscFlagDesc,
+		"\t-map-file FILE",
+		"\t      " + mpFilFlagDesc,
+		"\t-max-dotdot N",
+		"\t      " + mxDDtFlagDesc,
+		"\t-mixed",
+		"\t      " + cygMxFlagDesc,
+		"\t-mount-case-insensitive",
+		"\t      " + mntCIFlagDesc,
+		"\t-normalize-unicode",
+		"\t      " + nUniFlagDesc,
+		"\t-output-sep lf|crlf",
+		"\t      " + oSepFlagDesc,
+		"\t-output-volume-style drive|unc",
+		"\t      " + vStylFlagDesc,
+		"\t-passthrough-any",
+		"\t      " + psAnyFlagDesc,
+		"\t-path-list",
+		"\t      " + pListFlagDesc,
+		"\t-prefix-map FROM=>TO",
+		"\t      " + pxMapFlagDesc,
+		"\t-progress",
+		"\t      " + prgrsFlagDesc,
+		"\t-ps-array",
+		"\t      " + pArryFlagDesc,
+		"\t-ps-escape[=single|double]",
+		"\t      " + psEscFlagDesc,
+		"\t-quote",
+		"\t      " + quoteFlagDesc,
+		"\t-real-case",
+		"\t      " + rCaseFlagDesc,
+		"\t-relative-root OLD=NEW",
+		"\t      " + rRootFlagDesc,
+		"\t-relative-textual",
+		"\t      " + rlTxtFlagDesc,
+		"\t-remap-drive X:=Y:",
+		"\t      " + rmDrvFlagDesc,
+		"\t-resolve-against-mount-table",
+		"\t      " + chkMtFlagDesc,
+		"\t-resolve-lnk",
+		"\t      " + rsLnkFlagDesc,
+		"\t-resolve-subst",
+		"\t      " + rSubsFlagDesc,
+		"\t-rootfs-unc HOST",
+		"\t      " + rfUNCFlagDesc,
+		"\t-short",
+		"\t      " + shortFlagDesc,
+		"\t-skip-blank",
+		"\t      " + skBlkFlagDesc,
+		"\t-skip-comment PREFIX",
+		"\t      " + skCmtFlagDesc,
+		"\t-stdin-timeout DURATION",
+		"\t      " + stdToFlagDesc,
+		"\t-strict",
+		"\t      " + abortFlagDesc,
+		"\t-strict-match",
+		"\t      " + strctFlagDesc,
+		"\t-subst X:=TARGET",
+		"\t      " + substFlagDesc,
+		"\t-system-drive X:",
+		"\t      " + sysDrFlagDesc,
+		"\t-tildify",
+		"\t      " + tldfyFlagDesc,
+		"\t-timing",
+		"\t      " + timngFlagDesc,
+		"\t-trace-json",
+		"\t      " + trJsnFlagDesc,
+		"\t-unc-file FILE",
+		"\t      " + uncFlFlagDesc,
+		"\t-unc-mount-root DIR",
+		"\t      " + uncMrFlagDesc,
+		"\t-unc-only",
+		"\t      " + uncOnFlagDesc,
+		"\t-unix, -u",
+		"\t      " + cygUxFlagDesc,
+		"\t-var NAME=VALUE",
+		"\t      " + wnVarFlagDesc,
+		"\t-version-full",
+		"\t      " + svFulFlagDesc,
+		"\t-vscode",
+		"\t      " + vscodFlagDesc,
+		"\t-volume-guid GUID=X:",
+		"\t      " + volIdFlagDesc,
+		"\t-warn-conflicts",
+		"\t      " + wConfFlagDesc,
+		"\t-windows",
+		"\t      " + cygWnFlagDesc,
+		"\t-with-status",
+		"\t      " + wStatFlagDesc,
 		"",
 		"\tIf no option specifying the target file path(s) format is given,",
 		"\tthen the format is automatically determined by analyzing each given",
 		"\tpath individually and using the opposite format(s), respectively.",
 		"",
+		"\tThe -canonical flag produces the definitive form of each Unix path",
+		"\t(input or output) by applying, in order: anchoring relative paths",
+		"\tto the current directory, correcting case as with -real-case,",
+		"\tresolving symlinks in the longest existing prefix, and cleaning.",
+		"",
+		"\tThe flags -u, -w, -m, and -a (or -unix, -windows, -mixed, and",
+		"\t-absolute) are accepted as with cygpath, including combined (e.g.,",
+		"\t-aw). Unlike cygpath, relative paths are converted to relative",
+		"\tpaths where meaningful in both contexts (i.e., on a mounted Windows",
+		"\tvolume), unless -a is given. Bare file names (e.g., file.txt) are",
+		"\tonly converted when the target format is given.",
+		"",
+		"\tWith -json, each input is printed as a single line of JSON holding",
+		"\tthe input, output, format of the output, whether the output is in",
+		"\tthe read-only WSL rootfs, and the error message, in place of STDERR:",
+		"",
+		"\t    {\"input\":\"/etc\",\"output\":\"\\\\\\\\wsl$\\\\Ubuntu\\\\etc\",\"format\":\"windows\",\"rootfs\":true,\"error\":null}",
+		"",
+		"\tThe exit status is 0 if all paths are converted, and 1 otherwise.",
+		"\tWith -count-failures, it is instead the number of paths that failed",
+		"\t(at most 125), followed by a summary on STDERR. This is distinct from",
+		"\tstatus 100 (invalid arguments) and 127 (failure reading input).",
+		"",
 		"Environment:",
 		"\tTranslating absolute file paths from one filesystem to the other",
 		"\trequires the definition of environment variable(s) associating",
 		"\tWindows volumes with WSL mount points.",
 		"",
 		"\tThese environment variables are named according to their Windows",
-		"\tvolume name in all uppercase, appended with \"" + NixPathEnvSuffix + "\".",
+		"\tvolume name in all uppercase, appended with \"" + wslpath.NixPathEnvSuffix + "\".",
 		"\tFor example, converting \"C:\\Windows\" will look for an environment",
-		"\tvariable such as: C" + NixPathEnvSuffix + "=\"/mnt/c\".",
+		"\tvariable such as: C" + wslpath.NixPathEnvSuffix + "=\"/mnt/c\".",
+		"",
+		"\tA drive whose mount point is not defined in the environment is",
+		"\tfound among the drvfs mounts listed in " + wslpath.DefaultMountTable + ", if mounted.",
+		"",
+		"\tThe identifiers consulted for a given drive can be replaced with an",
+		"\tordered list using the -drive-var-order flag, which may be given",
+		"\tonce for each drive. The first variable defined is used. The drive",
+		"\tletter X may be omitted if the list contains an identifier named",
+		"\taccording to the above convention, for example:",
+		"",
+		"\t    -drive-var-order 'C" + wslpath.NixPathEnvSuffix + ",C_MOUNT,WINDOWS_C'",
 		"",
 		"\tIf a UNC path is provided, a special environment variable named",
 		"\tWSL_UNC_PATH is read containing a list of all UNC path to mount",
@@ -112,26 +353,67 @@ func Usage() {
 		"",
 		"\t    WSL_UNC_PATH='\\h1\\v1\\rp1=/lp1;\\h2\\v2\\rp2=/lp2'",
 		"",
+		"\tUNC mappings may instead be listed one per line (blank lines and #",
+		"\tcomments are ignored) in a file named by WSL_UNC_FILE or given with",
+		"\tthe flag -unc-file. These take precedence over WSL_UNC_PATH, and the",
+		"\tmapping of a volume loaded last takes precedence over earlier ones.",
+		"",
 		"\tThese same rules are applied in reverse when converting Unix file",
 		"\tpaths to Windows as well. The user's environment is inspected for",
 		"\tall variables with the mentioned suffix and using whichever matches",
 		"\tthe longest substring of the given path.",
 		"",
+		"\tVolume mappings may also be read from a file given with the flag",
+		"\t-map-file, which contains one mapping per line of the form",
+		"\tDRIVE=MOUNT or UNC=MOUNT (blank lines and # comments are ignored):",
+		"",
+		"\t    C:=/mnt/c",
+		"\t    \\\\host\\share=/mnt/share",
+		"",
+		"\tMappings read from a file take precedence over the environment.",
+		"",
+		"\tArbitrary path prefixes, such as container bind mounts that do not",
+		"\tfollow any drive convention, can be rewritten with the -prefix-map",
+		"\tflag, which may be given multiple times. Each map FROM=>TO pairs a",
+		"\tWindows prefix with a Unix prefix and is applied in either direction.",
+		"\tPrefix maps take precedence over the environment, and the map with",
+		"\tthe longest matching prefix is used.",
+		"",
+		"\tIn summary, mapping sources are consulted in the following order of",
+		"\tprecedence: -prefix-map, -map-file, environment variables, and then",
+		"\tthe drvfs mounts listed in " + wslpath.DefaultMountTable + ". The -warn-conflicts",
+		"\tflag reports each path for which more than one source matches with",
+		"\tdifferent results.",
+		"",
+		"\tWindows volume GUID paths (e.g., \"\\\\?\\Volume{GUID}\\path\") are first",
+		"\tresolved to the drive letter on which the volume is mounted, either",
+		"\tas given with the -volume-guid flag or as reported by mountvol.exe.",
+		"",
+		"\tWith -expand, references to per-user Windows variables such as",
+		"\t%TEMP%, %APPDATA%, and %LOCALAPPDATA% are expanded from -var, or",
+		"\tfrom the environment if shared with WSL (e.g., WSLENV=APPDATA).",
+		"\tValues holding Unix paths (e.g., TEMP=/tmp) are ignored, and such",
+		"\treferences that cannot be expanded are an error.",
+		"",
 		"\tIf the given Unix file path does not exist on any Windows file",
 		"\tsystem (the above search will fail to find a corresponding key in",
 		"\tthe user's environment), then the path is assumed to exist only on",
 		"\tthe virtual Linux file system. In this case, a special environment",
-		"\tvariable named " + WslRootfsEnvVar + " is consulted to resolve the Windows",
+		"\tvariable named " + wslpath.WslRootfsEnvVar + " is consulted to resolve the Windows",
 		"\tabsolute file path by appending the absolute Unix file path to the",
 		"\tvalue of this environment variable. If the command-line flag -e is",
 		"\tprovided, then this fallback is not performed, and any paths given",
 		"\tthat do not have a corresponding mapping in the environment will",
 		"\treturn an error.",
 		"",
+		"\tWindows UNC paths into a WSL distribution (e.g., \\\\wsl$\\Ubuntu\\etc",
+		"\tor \\\\wsl.localhost\\Ubuntu\\etc) are translated directly to Unix paths,",
+		"\tprovided the distribution is the active one (WSL_DISTRO_NAME).",
+		"",
 		"WARNING:",
 		"\tWSL does not currently support writing to virtual Linux file",
 		"\tsystems from a Windows context. Therefore, any paths resolved",
-		"\tusing the path referenced in the " + WslRootfsEnvVar + " environment",
+		"\tusing the path referenced in the " + wslpath.WslRootfsEnvVar + " environment",
 		"\tvariable should only be used for read-only operations. Writing",
 		"\tto these paths could potentially corrupt a WSL file system!",
 		"",
@@ -144,472 +426,620 @@ func main() {
 
 	var (
 		toWinFlag, toNixFlag, existFlag, svNumFlag bool
+		mixedFlag                                  bool
+		nullFlag, bArryFlag, pArryFlag, nUniFlag   bool
+		chkMtFlag, wStatFlag                       bool
+		skBlkFlag, drSkpFlag                       bool
+		skCmtFlag                                  string
+		rsLnkFlag, grpVoFlag, grpErFlag            bool
+		logclFlag, physcFlag, inTxtFlag            bool
+		chgOnFlag, cntFlFlag, abortFlag, jsOutFlag bool
+		psEscFlag                                  wslpath.QuoteStyle
+		stdToFlag                                  time.Duration
+		trJsnFlag, dlOnlFlag, rCaseFlag            bool
+		cmpctFlag, clsfyFlag, vscodFlag, dtOnlFlag bool
+		abbrvFlag                                  []wslpath.Abbrev
+		prgrsFlag, bsnmeFlag, drnmeFlag, shortFlag bool
+		assrtFlag                                  *wslpath.Format
+		pListFlag, dropUFlag                       bool
+		oSepFlag                                   = LF
+		canonFlag, expHmFlag, tldfyFlag, wConfFlag bool
+		ancstFlag, equalFlag, depthFlag, colpsFlag bool
+		mkEscFlag                                  bool
+		cwdOnFlag                                  string
+		uncOnFlag, quoteFlag, timngFlag            bool
+		eFmtFlag                                   = "plain"
+		limtFlag                                   int
+		psAnyFlag, errAnFlag                       bool
+		lnLstFlag                                  bool
+		lnSepFlag                                  = ";"
+		svFulFlag                                  bool
 	)
 	flag.BoolVar(&toWinFlag, "w", false, toWinFlagDesc)
 	flag.BoolVar(&toNixFlag, "x", false, toNixFlagDesc)
+	flag.BoolVar(&mixedFlag, "m", false, mixedFlagDesc)
+	flag.BoolVar(&toNixFlag, "u", false, cygUxFlagDesc)
+	flag.BoolVar(&toNixFlag, "unix", false, cygUxFlagDesc)
+	flag.BoolVar(&toWinFlag, "windows", false, cygWnFlagDesc)
+	flag.BoolVar(&mixedFlag, "mixed", false, cygMxFlagDesc)
+	flag.BoolVar(&wslpath.DefaultResolver.Absolute, "a", false, absltFlagDesc)
+	flag.BoolVar(&wslpath.DefaultResolver.Absolute, "absolute", false, absltFlagDesc)
+	flag.BoolVar(&wslpath.DefaultResolver.TrailingSep, "t", false, trailFlagDesc)
+	flag.BoolVar(&logclFlag, "L", false, logclFlagDesc)
+	flag.BoolVar(&physcFlag, "P", false, physcFlagDesc)
 	flag.BoolVar(&existFlag, "e", false, existFlagDesc)
 	flag.BoolVar(&svNumFlag, "v", false, svNumFlagDesc)
+	flag.BoolVar(&nullFlag, "0", false, nullFlagDesc)
+	flag.BoolVar(&bArryFlag, "bash-array", false, bArryFlagDesc)
+	flag.BoolVar(&pArryFlag, "ps-array", false, pArryFlagDesc)
+	flag.BoolVar(&nUniFlag, "normalize-unicode", false, nUniFlagDesc)
+	flag.BoolVar(&chkMtFlag, "resolve-against-mount-table", false, chkMtFlagDesc)
+	flag.BoolVar(&wStatFlag, "with-status", false, wStatFlagDesc)
+	flag.BoolVar(&skBlkFlag, "skip-blank", false, skBlkFlagDesc)
+	flag.BoolVar(&rsLnkFlag, "resolve-lnk", false, rsLnkFlagDesc)
+	flag.BoolVar(&inTxtFlag, "in-text", false, inTxtFlagDesc)
+	flag.BoolVar(&jsOutFlag, "json", false, jsOutFlagDesc)
+	flag.BoolVar(&wslpath.DefaultResolver.ExtendedLength, "extended-length", false, extLnFlagDesc)
+	flag.BoolVar(&grpVoFlag, "group-by-volume", false, grpVoFlagDesc)
+	flag.BoolVar(&grpErFlag, "group-headers-stderr", false, grpErFlagDesc)
+	flag.StringVar(&skCmtFlag, "skip-comment", "", skCmtFlagDesc)
+	flag.BoolVar(&drSkpFlag, "drop-skipped", false, drSkpFlagDesc)
+	flag.BoolVar(&svFulFlag, "version-full", false, svFulFlagDesc)
+	flag.BoolVar(&chgOnFlag, "changed-only", false, chgOnFlagDesc)
+	flag.BoolVar(&cntFlFlag, "count-failures", false, cntFlFlagDesc)
+	flag.BoolVar(&abortFlag, "strict", false, abortFlagDesc)
+	flag.Var(psEscape{&psEscFlag}, "ps-escape", psEscFlagDesc)
+	flag.DurationVar(&stdToFlag, "stdin-timeout", 0, stdToFlagDesc)
+	flag.BoolVar(&wslpath.DefaultResolver.Expand, "expand", false, expndFlagDesc)
+	flag.Var(systemDriveFlag{wslpath.DefaultResolver}, "system-drive", sysDrFlagDesc)
+	flag.BoolVar(&trJsnFlag, "trace-json", false, trJsnFlagDesc)
+	flag.BoolVar(&dlOnlFlag, "drive-letter-only", false, dlOnlFlagDesc)
+	flag.BoolVar(&rCaseFlag, "real-case", false, rCaseFlagDesc)
+	flag.BoolVar(&cmpctFlag, "compact", false, cmpctFlagDesc)
+	flag.BoolVar(&clsfyFlag, "classify", false, clsfyFlagDesc)
+	flag.BoolVar(&vscodFlag, "vscode", false, vscodFlagDesc)
+	flag.BoolVar(&dtOnlFlag, "detect-only", false, dtOnlFlagDesc)
+	flag.BoolVar(&prgrsFlag, "progress", false, prgrsFlagDesc)
+	flag.BoolVar(&bsnmeFlag, "basename", false, bsnmeFlagDesc)
+	flag.BoolVar(&drnmeFlag, "dirname", false, drnmeFlagDesc)
+	flag.BoolVar(&shortFlag, "short", false, shortFlagDesc)
+	flag.BoolVar(&pListFlag, "path-list", false, pListFlagDesc)
+	flag.BoolVar(&dropUFlag, "drop-unconvertible", false, dropUFlagDesc)
+	flag.BoolVar(&canonFlag, "canonical", false, canonFlagDesc)
+	flag.BoolVar(&expHmFlag, "expand-home", false, expHmFlagDesc)
+	flag.BoolVar(&tldfyFlag, "tildify", false, tldfyFlagDesc)
+	flag.BoolVar(&wConfFlag, "warn-conflicts", false, wConfFlagDesc)
+	flag.BoolVar(&ancstFlag, "ancestors", false, ancstFlagDesc)
+	flag.BoolVar(&equalFlag, "equal", false, equalFlagDesc)
+	flag.BoolVar(&depthFlag, "depth", false, depthFlagDesc)
+	flag.BoolVar(&colpsFlag, "collapse-redundant-volume", false, colpsFlagDesc)
+	flag.BoolVar(&mkEscFlag, "make-escape", false, mkEscFlagDesc)
+	flag.StringVar(&cwdOnFlag, "cwd-on", "", cwdOnFlagDesc)
+	flag.BoolVar(&uncOnFlag, "unc-only", false, uncOnFlagDesc)
+	flag.BoolVar(&quoteFlag, "quote", false, quoteFlagDesc)
+	flag.BoolVar(&timngFlag, "timing", false, timngFlagDesc)
+	flag.IntVar(&limtFlag, "limit", 0, limtFlagDesc)
+	flag.BoolVar(&psAnyFlag, "passthrough-any", false, psAnyFlagDesc)
+	flag.BoolVar(&errAnFlag, "error-any", false, errAnFlagDesc)
+	flag.BoolVar(&lnLstFlag, "line-list", false, lnLstFlagDesc)
+	flag.StringVar(&lnSepFlag, "list-sep", lnSepFlag, lnSepFlagDesc)
+	flag.StringVar(&wslpath.DefaultResolver.Root, "chroot", "", chrtFlagDesc)
+	flag.StringVar(&wslpath.DefaultResolver.UNCMountRoot, "unc-mount-root", "", uncMrFlagDesc)
+	flag.StringVar(&wslpath.DefaultResolver.RootfsHost, "rootfs-unc", "", rfUNCFlagDesc)
+	flag.Var(volumeFlag{wslpath.DefaultResolver}, "drive", drVolFlagDesc)
+	flag.StringVar(&wslpath.DefaultResolver.Base, "base", "", baseFlagDesc)
+	flag.BoolVar(&wslpath.DefaultResolver.InferVolume, "infer-volume", false, infVoFlagDesc)
+	flag.BoolVar(&wslpath.DefaultResolver.MountCaseInsensitive, "mount-case-insensitive", false, mntCIFlagDesc)
+	flag.BoolVar(&wslpath.DefaultResolver.RelativeTextual, "relative-textual", false, rlTxtFlagDesc)
+	flag.BoolVar(&wslpath.DefaultResolver.StrictMatch, "strict-match", false, strctFlagDesc)
+	flag.BoolVar(&dtOnlFlag, "echo-format", false, dtOnlFlagDesc)
+	flag.Var(abbrevList{&abbrvFlag}, "abbrev", abbrvFlagDesc)
+	flag.Var(formatFlag{&assrtFlag}, "assert", assrtFlagDesc)
+	flag.Var(outputSep{&oSepFlag}, "output-sep", oSepFlagDesc)
+	flag.Var(varFlag{wslpath.DefaultResolver}, "var", wnVarFlagDesc)
+	flag.Var(errorFormat{&eFmtFlag}, "error-format", eFmtFlagDesc)
+	flag.Var(volumeStyle{&wslpath.DefaultResolver.VolumeStyle}, "output-volume-style", vStylFlagDesc)
+	flag.Var(volumeGUIDFlag{wslpath.DefaultResolver}, "volume-guid", volIdFlagDesc)
+	flag.Var(mapFileFlag{wslpath.DefaultResolver}, "map-file", mpFilFlagDesc)
+	flag.Var(uncFileFlag{wslpath.DefaultResolver}, "unc-file", uncFlFlagDesc)
+	flag.BoolVar(&wslpath.DefaultResolver.ResolveSubst, "resolve-subst", false, rSubsFlagDesc)
+	flag.Var(substFlag{wslpath.DefaultResolver}, "subst", substFlagDesc)
+	flag.Var(driveRemapFlag{wslpath.DefaultResolver}, "remap-drive", rmDrvFlagDesc)
+	flag.IntVar(&wslpath.DefaultResolver.MaxDotDot, "max-dotdot", wslpath.DefaultMaxDotDot, mxDDtFlagDesc)
+	flag.Var(driveVarOrder{wslpath.DefaultResolver}, "drive-var-order", drvOrFlagDesc)
+	flag.Var(prefixMapList{wslpath.DefaultResolver}, "prefix-map", pxMapFlagDesc)
+	flag.Var(relativeRootList{wslpath.DefaultResolver}, "relative-root", rRootFlagDesc)
+
+	// mappings in the file named in the environment are loaded before any
+	// given on the command line, which replace them.
+	if name := os.Getenv(wslpath.UncFileEnvVar); "" != name {
+		if err := wslpath.DefaultResolver.LoadUNCFile(name); nil != err {
+			fmt.Fprintf(os.Stderr, "error: %s: %v\n", wslpath.UncFileEnvVar, err)
+			os.Exit(100)
+		}
+	}
 
 	flag.Usage = Usage
-	flag.Parse()
+	// cygpath accepts its single-letter flags combined (e.g., -aw)
+	_ = flag.CommandLine.Parse(CygpathArgs(os.Args[1:]))
 
+	if svFulFlag {
+		WriteVersion(os.Stdout, filepath.Base(os.Args[0]), true)
+		os.Exit(0)
+	}
 	if svNumFlag {
-		fmt.Println(filepath.Base(os.Args[0]), "version", version)
+		WriteVersion(os.Stdout, filepath.Base(os.Args[0]), false)
+		os.Exit(0)
 	}
 
-	if toWinFlag && toNixFlag {
-		fmt.Fprintln(os.Stderr, "error: invalid arguments: -w and -x are mutually exclusive")
-		os.Exit(100)
+	if trJsnFlag {
+		wslpath.DefaultResolver.Trace = wslpath.JSONTrace(os.Stderr)
 	}
 
-	exitCode := 0
-
-	// read from command line args if provided, otherwise STDIN
-	s := bufio.NewScanner(InputReader(flag.Args()...))
-	for s.Scan() {
-
-		var err error
-		text := s.Text()
-		form := ""
-
-		// use command line flag as target format if provided
-		switch {
-		case toWinFlag:
-			form, _, err = Unix.Format(Windows, text, existFlag, 0)
-		case toNixFlag:
-			form, _, err = Windows.Format(Unix, text, existFlag, 0)
-		default:
-			// otherwise, no command line flag, try to detect the
-			// given format and use the opposite as target format
-			switch Identify(text) {
-			case Windows:
-				form, _, err = Windows.Format(Unix, text, existFlag, 0)
-			case Unix:
-				form, _, err = Unix.Format(Windows, text, existFlag, 0)
-			case Any:
-				form = Any.Clean(text)
-			}
-		}
-		if nil != err {
-			fmt.Fprintln(os.Stderr, "error: Format():", err)
-			exitCode = 1
-			continue
-		}
-		fmt.Println(form)
+	if btoi(toWinFlag)+btoi(toNixFlag)+btoi(mixedFlag) > 1 {
+		fmt.Fprintln(os.Stderr, "error: invalid arguments: -m, -w, and -x are mutually exclusive")
+		os.Exit(100)
 	}
-
-	if err := s.Err(); nil != err {
-		fmt.Fprintln(os.Stderr, "error: Scan():", err)
-		os.Exit(127)
+	if logclFlag && physcFlag {
+		fmt.Fprintln(os.Stderr, "error: invalid arguments: -L and -P are mutually exclusive")
+		os.Exit(100)
 	}
-
-	os.Exit(exitCode)
-}
-
-// InputReader returns an io.Reader that reads all given arguments if provided,
-// otherwise it reads from STDIN.
-func InputReader(args ...string) io.Reader {
-	if len(args) > 0 {
-		return strings.NewReader(strings.Join(args, "\n"))
+	wslpath.DefaultResolver.Logical = !physcFlag
+	if wslpath.DefaultResolver.Absolute && wslpath.DefaultResolver.RelativeTextual {
+		fmt.Fprintln(os.Stderr, "error: invalid arguments: -absolute and -relative-textual are mutually exclusive")
+		os.Exit(100)
 	}
-	return os.Stdin
-}
 
-// Identify automatically detects and returns the file path Format of a given
-// string, by scanning for the first directory path separator.
-// If no separator exists, such as a simple file name, then the path is
-// checked if it contains a Windows drive letter prefix (a volume-anchored
-// relative path, e.g., "D:foo.dat" refers to "foo.dat" in the current
-// working path on the "D:" volume, regardless of the current volume).
-// Otherwise, the path is valid for both systems, and the special Format
-// value Any is returned.
-func Identify(s string) Format {
-	for _, c := range s {
-		if c == '\\' {
-			return Windows
-		}
-		if c == '/' {
-			return Unix
+	// mixed mode converts as -w, writing separators only on output
+	toWinFlag = toWinFlag || mixedFlag
+	if b := wslpath.DefaultResolver.Base; "" != b {
+		switch f := wslpath.Identify(b); {
+		case toWinFlag && wslpath.Windows == f, toNixFlag && wslpath.Unix == f:
+			fmt.Fprintln(os.Stderr, "error: invalid arguments: -base: directory must be given in the source format:", b)
+			os.Exit(100)
+		case wslpath.DefaultResolver.RelativeTextual && wslpath.Windows != f:
+			fmt.Fprintln(os.Stderr, "error: invalid arguments: -base and -relative-textual are mutually exclusive")
+			os.Exit(100)
 		}
 	}
-	// No directory separator, check if it contains a drive letter prefix
-	if len(s) > 1 {
-		if d := s[0]; (s[1] == ':') &&
-			(('a' <= d && d <= 'z') || ('A' <= d && d <= 'Z')) {
-			return Windows
+	if nullFlag {
+		if LF != oSepFlag {
+			fmt.Fprintln(os.Stderr, "error: invalid arguments: -0 and -output-sep are mutually exclusive")
+			os.Exit(100)
 		}
+		oSepFlag = NUL
 	}
-	return Any
-}
-
-// SplitVolume separates the given file path in Windows Format into volume and
-// path components. Volume may be either a drive letter or a UNC host+share
-// expression. If a volume expression does not exist, or Format is not Windows,
-// then the returned volume is the empty string and path is unchanged.
-func (f Format) SplitVolume(s string) (volume, path string) {
-
-	// Windows is the only Format that uses volume prefixes
-	if Windows != f {
-		return "", s
+	if psAnyFlag && errAnFlag {
+		fmt.Fprintln(os.Stderr, "error: invalid arguments: -passthrough-any and -error-any are mutually exclusive")
+		os.Exit(100)
+	}
+	if btoi(pListFlag)+btoi(lnLstFlag)+btoi(inTxtFlag) > 1 {
+		fmt.Fprintln(os.Stderr, "error: invalid arguments: -in-text, -line-list, and -path-list are mutually exclusive")
+		os.Exit(100)
+	}
+	if lnLstFlag && "" == lnSepFlag {
+		fmt.Fprintln(os.Stderr, "error: invalid arguments: -list-sep: empty separator")
+		os.Exit(100)
+	}
+	if n := btoi(mkEscFlag) + btoi(quoteFlag) + btoi(psEscFlag != wslpath.NoQuote) +
+		btoi(bArryFlag) + btoi(pArryFlag); n > 1 {
+		fmt.Fprintln(os.Stderr, "error: invalid arguments: -bash-array, -make-escape, -ps-array, -ps-escape, and -quote are mutually exclusive")
+		os.Exit(100)
 	}
-	// test if we have a drive letter X: prefix
-	if len(s) < 2 {
-		return "", s
+	if grpVoFlag && (bArryFlag || pArryFlag || pListFlag || lnLstFlag) {
+		fmt.Fprintln(os.Stderr, "error: invalid arguments: -group-by-volume cannot be combined with -bash-array, -line-list, -path-list, or -ps-array")
+		os.Exit(100)
 	}
-	if d := s[0]; (s[1] == ':') &&
-		(('a' <= d && d <= 'z') || ('A' <= d && d <= 'Z')) {
-		return s[:2], s[2:]
+	if wStatFlag && (ancstFlag || depthFlag || bArryFlag || pArryFlag) {
+		fmt.Fprintln(os.Stderr, "error: invalid arguments: -with-status cannot be combined with -ancestors, -bash-array, -depth, or -ps-array")
+		os.Exit(100)
 	}
 
-	// test if we have a UNC \\host\share prefix
-	if len(s) < 5 {
-		return "", s
+	if jsOutFlag && (ancstFlag || bArryFlag || clsfyFlag || dtOnlFlag || grpVoFlag ||
+		mkEscFlag || pArryFlag || psEscFlag != wslpath.NoQuote || quoteFlag || vscodFlag || wStatFlag) {
+		fmt.Fprintln(os.Stderr, "error: invalid arguments: -json cannot be combined with -ancestors, -bash-array, -classify, -detect-only, -group-by-volume, -make-escape, -ps-array, -ps-escape, -quote, -vscode, or -with-status")
+		os.Exit(100)
 	}
-	// verify we have leading slashes
-	if s[:2] == `\\` && s[2] != '\\' && s[2] != '.' {
-		for n := 3; n < len(s)-1; n++ {
-			// walk over server name until we reach volume separator
-			if s[n] == '\\' {
-				n++
-				if s[n] != '\\' {
-					if s[n] == '.' {
-						break
-					}
-					// we are in volume name,
-					//   take remaining chars up to EOS or next separator
-					for ; n < len(s); n++ {
-						if s[n] == '\\' {
-							break
-						}
-					}
-					return s[:n], s[n:]
-				}
-				break
 
-			}
-		}
+	report := NewErrorReporter(os.Stderr, eFmtFlag, cntFlFlag)
+	if jsOutFlag {
+		// errors are results in the same stream as conversions
+		report = NewErrorReporter(os.Stdout, "result", false)
 	}
-	return "", s
-}
 
-// Elements splits the given file path into individual path components based
-// on the receiver Format f's directory separator. Unlike strings.Split, empty
-// components are not added to the returned slice.
-func (f Format) Elements(s string) []string {
-	e := []string{}
-	b := strings.Builder{}
-	for _, c := range s {
-		if f.issep(c) {
-			e = append(e, b.String())
-			b.Reset()
-		} else {
-			b.WriteRune(c)
+	if cwdOnFlag != "" {
+		d := strings.TrimRight(cwdOnFlag, `:\`)
+		if len(d) != 1 || !isalpha(d[0]) {
+			fmt.Fprintln(os.Stderr, "error: invalid arguments: -cwd-on: invalid drive letter:", cwdOnFlag)
+			os.Exit(100)
 		}
+		p, err := wslpath.DefaultResolver.CwdOn(d[0])
+		if nil != err {
+			report.Report("CwdOn", cwdOnFlag, err)
+			os.Exit(1)
+		}
+		fmt.Print(p, oSepFlag)
+		os.Exit(0)
 	}
-	if b.Len() > 0 {
-		e = append(e, b.String())
-	}
-	return e
-}
 
-// Clean is the same as standard Go's path/filepath.Clean, except that it can
-// handle arbitrary directory separators. In particular, it applies the
-// following rules iteratively until no further processing can be done:
-//
-//     1. Replace multiple directory separators with a single one.
-//     2. Eliminate each "." path name element (the current directory).
-//     3. Eliminate each inner-".." path name element (the parent directory)
-//        along with the non-".." element that precedes it.
-//     4. Eliminate ".." elements that begin a rooted path: that is, replace
-//        "/.." by "/" at the beginning of a path, assuming directory separator
-//        is '/'.
-//
-// The volume prefix, if provided as either a drive letter or UNC host+share, is
-// preserved on both absolute and relative file paths.
-//
-// The returned path ends in a slash only if it represents a root directory,
-// such as "/" on Unix or `C:\` on Windows.
-//
-// If the result of this process is an empty string, "." is returned.
-func (f Format) Clean(s string) string {
+	if equalFlag {
+		if flag.NArg() != 2 {
+			fmt.Fprintln(os.Stderr, "error: invalid arguments: -equal requires exactly two paths")
+			os.Exit(100)
+		}
+		eq, err := wslpath.DefaultResolver.Equal(flag.Arg(0), flag.Arg(1))
+		if nil != err {
+			report.Report("Equal", strings.Join(flag.Args(), " "), err)
+			os.Exit(2)
+		}
+		if !eq {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
 
-	var vol string
-	vol, s = f.SplitVolume(s)
+	exitCode := 0
 
-	if len(s) == 0 {
-		return vol + "."
+	// read from command line args if provided, otherwise STDIN
+	in := InputReader(oSepFlag, flag.Args()...)
+	if in == os.Stdin {
+		if IsTerminal(os.Stdin) && stdToFlag <= 0 {
+			fmt.Fprintln(os.Stderr, "reading paths from STDIN; press Ctrl-D to end (-h for help)")
+		}
+		in = TimeoutReader(in, stdToFlag)
+	}
+	// progress is only reported to an interactive terminal
+	var progress *Progress
+	if prgrsFlag && IsTerminal(os.Stderr) {
+		progress = NewProgress(os.Stderr, time.Second)
 	}
 
-	// replace multiple separator elements with a single one
-	u := string(f.sep())
-	d := u + u
-	for n := 0; n != len(s); {
-		n = len(s)
-		s = strings.ReplaceAll(s, d, u)
+	if timngFlag {
+		wslpath.DefaultResolver.Timing = &wslpath.Timing{}
 	}
+	timing := wslpath.DefaultResolver.Timing
 
-	e := f.Elements(s)
+	// each input is a list of paths (or text) rather than a single path
+	list := pListFlag || lnLstFlag || inTxtFlag
 
-	// remove any "." elements (current dir)
-	p := []string{}
-	for _, u := range e {
-		if u != "." {
-			p = append(p, u)
-		}
+	// each stale mount point is only reported once
+	staleMounts := map[string]bool{}
+
+	// paths are buffered when printed as a single array literal
+	var array []string
+
+	// note only once when short names are unavailable
+	shortNoted := false
+
+	// paths are buffered when printed in groups by volume
+	groups := NewVolumeGroups()
+
+	s := bufio.NewScanner(in)
+	if nullFlag {
+		s.Split(ScanNull)
 	}
+	n := 0
+	for ; s.Scan(); n++ {
 
-	// remove any (inner) ".." elements and their predecessor (parent dir)
-	for {
-		// create buffer for current pass
-		q := []string{}
-		// keep iterating until no change was performed (done == true)
-		done, skip := true, false
-		// walk over each path element, checking if its following
-		// element is ".."
-		for i := range p {
-			if !skip {
-				// preceding element was not ".."
-				if (i+1 < len(p)) && (p[i] != "..") && (p[i+1] == "..") {
-					if (i == 0) && (p[i] == "") {
-						// keep. leading element is ".."
-						q = append(q, p[i])
-					}
-					// skip. following element is ".."
-					// need to process elements again
-					done, skip = false, true
-				} else {
-					// keep. following element is not ".."
-					q = append(q, p[i])
-				}
-			} else {
-				// skip. current element is ".."
-				skip = false
+		// blank and comment lines are not paths, so they are neither
+		// converted nor counted toward -limit
+		if line := s.Text(); (skBlkFlag && "" == strings.TrimSpace(line)) ||
+			("" != skCmtFlag && strings.HasPrefix(strings.TrimLeft(line, " \t"), skCmtFlag)) {
+			if !drSkpFlag && !bArryFlag && !pArryFlag && !grpVoFlag && !jsOutFlag {
+				fmt.Print(line, oSepFlag)
 			}
+			n--
+			continue
 		}
-		// replace final path elements with result of current pass
-		p = q
-		if done {
-			// no change performed in current pass. all done.
+
+		if abortFlag && report.Failed() > 0 {
+			fmt.Fprintln(os.Stderr, "warning: stopped at the first failed path (-strict); remaining input ignored")
 			break
 		}
-	}
 
-	// if no elements remain, use current dir "."
-	if len(p) == 0 {
-		return vol + "."
-	} else {
-		if (len(p) == 1) && (p[0] == "") {
-			return vol + string(f.sep())
-		} else {
-			return vol + strings.Join(p, string(f.sep()))
+		if limtFlag > 0 && n >= limtFlag {
+			fmt.Fprintf(os.Stderr, "warning: stopped after %d paths (-limit); remaining input ignored\n", limtFlag)
+			break
 		}
-	}
-}
 
-// Format translates the given file path s, interpreted as a path in the
-// receiver Format f, to a file path in given Format t.
-//
-// When translating absolute paths from one file system to the other,
-// environment variables are used to determine relative paths or mount points.
-//
-// The bool return paramter is true if and only if the returned path is
-// a Windows formatted path into the WSL virtual rootfs (i.e., read-only).
-func (f Format) Format(t Format, s string, x bool, z uint) (string, bool, error) {
+		progress.Add()
 
-	s = f.Clean(s)
-	wsl := false
+		var err error
+		text := s.Text()
+		form, wsl := "", false
 
-	if z > 1 {
-		return "", false, fmt.Errorf("invalid path: %s", s)
-	}
+		line := text
+		if nUniFlag {
+			line = wslpath.NormalizeUnicode(line)
+		}
+		if dlOnlFlag && !toWinFlag {
+			line, _ = wslpath.BareDrive(line)
+		}
+		if expHmFlag && !toNixFlag {
+			line = wslpath.ExpandHome(line)
+		}
+		if colpsFlag {
+			line, _ = wslpath.DefaultResolver.CollapseVolume(line)
+		}
+		if rsLnkFlag && wslpath.IsShortcut(line) {
+			t, err := wslpath.DefaultResolver.ReadShortcut(line)
+			if nil != err {
+				report.Report("ReadShortcut", text, err)
+				exitCode = 1
+				continue
+			}
+			line = t
+		}
 
-	switch f {
-	case Windows:
-		if Unix == t {
-			v, p := f.SplitVolume(s)
-			if len(v) >= 2 {
-				// absolute path
-				v0, v1 := v[0], v[1]
-				if (v1 == ':') && (('a' <= v0 && v0 <= 'z') || ('A' <= v0 && v0 <= 'Z')) {
-					// convert drive letter to environment variable
-					e := strings.ToUpper(string(v0)) + NixPathEnvSuffix
-					if dp, ok := os.LookupEnv(e); ok {
-						// replace drive letter with value of environment variable
-						s = dp + p
-					} else {
-						return "", false, fmt.Errorf("environment variable not set: %s", e)
-					}
-				} else if len(v) >= 5 {
-					v2 := v[2]
-					if v[:2] == `\\` && v2 != '\\' && v2 != '.' {
-						if up, ok := os.LookupEnv(UncPathEnvVar); ok {
-							ok = false
-							for _, vm := range strings.Split(up, `;`) {
-								if m := strings.SplitN(vm, `=`, 2); len(m) == 2 {
-									if strings.ToUpper(m[0]) == strings.ToUpper(v) {
-										s = m[1] + p
-										ok = true
-										break
-									}
-								}
-							}
-							if !ok {
-								return "", false, fmt.Errorf("UNC volume %q not found in environment variable: %s=%q", v, UncPathEnvVar, up)
-							}
-						} else {
-							return "", false, fmt.Errorf("environment variable not set: %s", UncPathEnvVar)
-						}
-					}
-				}
+		if dtOnlFlag {
+			fmt.Print(wslpath.Identify(line), oSepFlag)
+			continue
+		}
+		if clsfyFlag {
+			fmt.Print(wslpath.DefaultResolver.Classify(line), oSepFlag)
+			continue
+		}
+		if vscodFlag {
+			uri, err := wslpath.VSCodeURI(line)
+			if nil != err {
+				report.Report("VSCodeURI", text, err)
+				exitCode = 1
+				continue
 			}
-			s = strings.ReplaceAll(s, string(f.sep()), string(t.sep()))
-			s = t.Clean(s)
-		}
-
-	case Unix:
-		if Windows == t {
-			if len(s) > 0 {
-				if s[0] == '/' {
-					// absolute file path
-					//e, err := filepath.EvalSymlinks(s)
-					//if err != nil {
-					//	return "", false, err
-					//}
-					s = f.abspath(s)
-					var rk, rv string
-					unc := false
-					if up, ok := os.LookupEnv(UncPathEnvVar); ok {
-						for _, vm := range strings.Split(up, `;`) {
-							if m := strings.SplitN(vm, `=`, 2); len(m) == 2 {
-								if strings.HasPrefix(s, m[1]) && len(m[1]) > len(rv) {
-									rk, rv = m[0], m[1]
-									unc = true
-								}
-							}
-						}
-					}
-					if unc {
-						s = strings.Replace(s, rv, rk, 1)
-					} else {
-						for _, e := range os.Environ() {
-							n := strings.IndexRune(e, '=')
-							if (-1 != n) && (len(e) > n+1) {
-								k, v := e[:n], f.Clean(e[n+1:])
-								if strings.HasSuffix(k, NixPathEnvSuffix) &&
-									strings.HasPrefix(s, v) && (len(v) > len(rv)) {
-									rk, rv = k, v
-								}
-							}
-						}
-						if len(rk) > 0 {
-							s = strings.Replace(s, rv, string(rk[0])+":", 1)
-						} else {
-							if up, ok := os.LookupEnv(WslRootfsEnvVar); !x && ok {
-								// Remove trailing line delimiters in case of misconfiguration
-								// caused by subtle interop (e.g., calling reg.exe from WSL will
-								// leave a hard-to-detect carriage return \x0D in its output).
-								//
-								// It should be very unlikely that someone intentionally wanted
-								// a newline or carriage return at the very end of a file name.
-								up = strings.TrimRight(up, "\r\n")
-								s = fmt.Sprintf("%s%c%s", up, t.sep(), s)
-								wsl = true
-							} else {
-								return "", false, fmt.Errorf("path substring not found in environment: %s", s)
-							}
-						}
-					}
-				} else {
-					// relative file path
-					//a, err := filepath.Abs(s)
-					//if err != nil {
-					//	return "", false, err
-					//}
-					// if we cannot resolve the absolute path to a Windows volume, then
-					// the relative path will never make sense in a Windows context.
-					// Instead, construct an absolute path to the WSL rootfs path.
-					p, w, err := f.Format(t, f.abspath(s), x, z+1)
-					if err != nil {
-						return "", false, err
-					}
-					if w {
-						// The absolute path was unresolved to a Windows volume, and we
-						// instead received a path to the virtual WSL rootfs.
-						// Use the absolute WSL rootfs path instead of a relative path.
-						s, wsl = p, true
-					}
-					// s is either a physical relative path or an absolute virtual path.
-				}
+			fmt.Print(uri, oSepFlag)
+			continue
+		}
+
+		if nil != assrtFlag {
+			if f := wslpath.Identify(line); *assrtFlag != f {
+				report.Report("Identify", text, &wslpath.Error{Code: wslpath.ErrFormatMismatch,
+					Err: fmt.Errorf("%q: detected %s, expected %s", text, f, *assrtFlag)})
+				exitCode = 1
+				continue
 			}
+		}
 
-			s = strings.ReplaceAll(s, string(f.sep()), string(t.sep()))
-			s = t.Clean(s)
+		// use command line flag as target format if provided
+		from, to := wslpath.Identify(line), wslpath.Any
+		switch {
+		case toWinFlag:
+			from, to = wslpath.Unix, wslpath.Windows
+		case toNixFlag:
+			from, to = wslpath.Windows, wslpath.Unix
+		case inTxtFlag:
+			// each quoted path is identified individually
+			from, to = wslpath.Any, wslpath.Any
+		default:
+			// otherwise, no command line flag, try to detect the
+			// given format and use the opposite as target format
+			switch from {
+			case wslpath.Windows:
+				to = wslpath.Unix
+			case wslpath.Unix:
+				to = wslpath.Windows
+			}
 		}
 
-	case Any:
-	default:
-	}
+		if wConfFlag && !list {
+			if c := wslpath.DefaultResolver.Conflicts(from, to, line); len(c) > 0 {
+				src := make([]string, len(c))
+				for i, a := range c {
+					src[i] = fmt.Sprintf("%s=%q", a.Source, a.Result)
+				}
+				fmt.Fprintf(os.Stderr, "warning: conflicting mappings for %q: %s\n",
+					text, strings.Join(src, ", "))
+			}
+		}
 
-	return s, wsl, nil
-}
+		start := time.Now()
 
-func (f Format) abspath(s string) string {
-	var act, rel string
-	for _, p := range strings.Split(s, string(f.sep())) {
-		if act == "" && rel == "" && p == "" {
-			p = string(f.sep())
-		} else {
-			if rel != "" {
-				if rel != string(f.sep()) {
-					rel += string(f.sep())
-				}
-				rel += p
+		// the file system is only accessible through Unix paths
+		fs := time.Now()
+		switch {
+		case canonFlag && wslpath.Unix == from:
+			line = wslpath.Canonical(line)
+		case rCaseFlag && wslpath.Unix == from:
+			line = wslpath.RealCase(line)
+		}
+		timing.AddFS(fs)
+		bare := wslpath.Any == wslpath.Identify(line) && !list
+		switch {
+		case bare && errAnFlag:
+			err = &wslpath.Error{Code: wslpath.ErrFormatMismatch,
+				Err: fmt.Errorf("bare file name has no path to convert: %s", line)}
+		case bare && psAnyFlag:
+			form = line
+		case inTxtFlag:
+			form, err = wslpath.DefaultResolver.FormatQuoted(to, line, existFlag)
+		case wslpath.Any == to:
+			form = wslpath.Any.Clean(line)
+		case pListFlag:
+			form, err = wslpath.DefaultResolver.FormatList(from, to, line, existFlag, dropUFlag)
+		case lnLstFlag:
+			form, err = wslpath.DefaultResolver.FormatListSep(from, to, line, lnSepFlag, existFlag, dropUFlag)
+		default:
+			form, wsl, err = from.Format(to, line, existFlag, 0)
+		}
+		if wslpath.Unix == to && nil == err {
+			fs = time.Now()
+			switch {
+			case canonFlag:
+				form = wslpath.Canonical(form)
+			case rCaseFlag:
+				form = wslpath.RealCase(form)
+			}
+			timing.AddFS(fs)
+		}
+		timing.Add(time.Since(start))
+		if nil != err {
+			report.Report("Format", text, err)
+			exitCode = 1
+			continue
+		}
+		status := wslpath.Unchecked
+		if wStatFlag && !list {
+			// results in the WSL rootfs are not inspected from Windows
+			switch {
+			case wslpath.Windows != to:
+				status = wslpath.DefaultResolver.Stat(form)
+			case !wsl:
+				status = wslpath.DefaultResolver.Stat(line)
+			}
+		}
+		if chkMtFlag {
+			u := form
+			if wslpath.Unix != to {
+				u = line
+			}
+			if err := wslpath.DefaultResolver.CheckMount(u); nil != err && !staleMounts[err.Error()] {
+				fmt.Fprintln(os.Stderr, "warning:", err)
+				staleMounts[err.Error()] = true
+			}
+		}
+		if uncOnFlag {
+			w := form
+			if wslpath.Windows != to {
+				w = line
+			}
+			if wslpath.Windows != wslpath.Identify(w) || !wslpath.DefaultResolver.IsUNC(w) {
+				continue
+			}
+		}
+		if shortFlag && wslpath.Windows == to && wslpath.Windows.IsAbs(form) && !list {
+			short, ok, err := wslpath.ShortPath(form)
+			if nil != err {
+				report.Report("ShortPath", text, err)
+				exitCode = 1
 				continue
 			}
+			if !ok && !shortNoted {
+				fmt.Fprintln(os.Stderr, "note: 8.3 short names unavailable (disabled on volume?); using long names")
+				shortNoted = true
+			}
+			form = short
 		}
-		t := act
-		if t != "" && t != string(f.sep()) {
-			t += string(f.sep())
+		volume := ""
+		if grpVoFlag {
+			// the source volume is that of the Windows side of the
+			// conversion, including the rootfs (e.g., \\wsl$\Ubuntu).
+			w := form
+			if wslpath.Windows != to {
+				w = line
+			}
+			if wslpath.Windows == wslpath.Identify(w) {
+				volume, _ = wslpath.Windows.SplitVolume(w)
+			}
+		}
+		forms := []string{form}
+		switch {
+		case bsnmeFlag:
+			forms[0] = to.Base(form)
+		case drnmeFlag:
+			forms[0] = to.Dir(form)
+		case ancstFlag && wslpath.Any != to && !list:
+			a, err := wslpath.DefaultResolver.Ancestors(from, to, line, existFlag)
+			if nil != err {
+				report.Report("Ancestors", text, err)
+				exitCode = 1
+				continue
+			}
+			// keep the path itself as corrected by any of the above
+			forms = append(forms, a[1:]...)
+		case depthFlag && !list:
+			// the depth below a mount point is only known from the
+			// Windows side of the conversion.
+			switch {
+			case wslpath.Windows == to:
+				forms[0] = strconv.Itoa(wslpath.Windows.Depth(form))
+			case wslpath.Windows == from:
+				forms[0] = strconv.Itoa(wslpath.Windows.Depth(line))
+			default:
+				forms[0] = strconv.Itoa(to.Depth(form))
+			}
 		}
-		t += p
-		es, ee := filepath.EvalSymlinks(t)
-		as, ae := filepath.Abs(es)
-		if ee == nil && ae == nil {
-			act = as
-		} else {
-			rel = p
+		for _, form := range forms {
+			if tldfyFlag && wslpath.Unix == to {
+				form = wslpath.Tildify(form)
+			}
+			if chgOnFlag && form == text {
+				continue
+			}
+			if cmpctFlag {
+				form = wslpath.Compact(form, wslpath.DefaultResolver.Abbrevs(abbrvFlag...))
+			}
+			if mixedFlag {
+				form = wslpath.Mixed(form)
+			}
+			switch {
+			case psEscFlag != wslpath.NoQuote:
+				form = wslpath.PowerShellEscape(form, psEscFlag)
+			case mkEscFlag:
+				form = wslpath.MakeEscape(form)
+			case quoteFlag:
+				form = wslpath.ShellQuote(form)
+			}
+			if bArryFlag || pArryFlag {
+				array = append(array, form)
+				continue
+			}
+			if wStatFlag {
+				form += "\t" + status.String()
+			}
+			if grpVoFlag {
+				groups.Add(volume, form)
+				continue
+			}
+			if jsOutFlag {
+				WriteResult(os.Stdout, text, form, to, wsl)
+				continue
+			}
+			fmt.Print(form, oSepFlag)
 		}
 	}
-	if act != "" {
-		if rel != "" {
-			return act + string(f.sep()) + rel
+	progress.Done()
+	switch {
+	case grpVoFlag:
+		hdr, hsep := os.Stdout, oSepFlag
+		if grpErFlag {
+			hdr, hsep = os.Stderr, LF
 		}
-		return act
+		groups.Write(os.Stdout, oSepFlag, hdr, hsep)
+	case bArryFlag:
+		fmt.Print(wslpath.BashArray(array), oSepFlag)
+	case pArryFlag:
+		fmt.Print(wslpath.PowerShellArray(array), oSepFlag)
+	}
+	timing.Report(os.Stderr)
+
+	if err := s.Err(); nil != err {
+		fmt.Fprintln(os.Stderr, "error: Scan():", err)
+		os.Exit(127)
 	}
-	if rel != "" {
-		return rel
+
+	if cntFlFlag {
+		fmt.Fprintf(os.Stderr, "%d failure(s) in %d input(s)\n", report.Failed(), n)
+		if exitCode = report.Failed(); exitCode > 125 {
+			exitCode = 125
+		}
 	}
-	return ""
+
+	os.Exit(exitCode)
 }
 
-// issep returns true if and only if the given rune is equal to the receiver
-// Format f's directory separator.
-func (f Format) issep(c rune) bool {
-	switch f {
-	case Windows:
-		return c == '\\'
-	case Unix:
-		return c == '/'
-	case Any:
-		return c == '/' || c == '\\'
+// btoi returns 1 if the given bool is true, otherwise 0.
+func btoi(b bool) int {
+	if b {
+		return 1
 	}
-	return false
+	return 0
 }
 
-// sep returns the directory separator rune of the receiver Format f.
-func (f Format) sep() rune {
-	if Windows == f {
-		return '\\'
+// InputReader returns an io.Reader that reads all given arguments, each
+// terminated by the given record terminator, if provided, otherwise it reads
+// from STDIN.
+func InputReader(term string, args ...string) io.Reader {
+	if len(args) > 0 {
+		return strings.NewReader(strings.Join(args, term) + term)
 	}
-	return '/'
+	return os.Stdin
 }
@@ -0,0 +1,866 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// mainEnvVar is defined in the environment of the test binary when it is run
+// by wslpath to invoke main instead of the tests.
+const mainEnvVar = "WSLPATH_TEST_MAIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(mainEnvVar) != "" {
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+		main()
+	}
+	os.Exit(m.Run())
+}
+
+// command returns a Cmd that runs main with the given command-line arguments
+// in an environment containing only the given variables.
+func command(env []string, args ...string) *exec.Cmd {
+	cmd := exec.Command(os.Args[0], append([]string{"--"}, args...)...)
+	cmd.Env = append([]string{mainEnvVar + "=1"}, env...)
+	return cmd
+}
+
+// run runs main with the given command-line arguments and input on STDIN
+// in an environment containing only the given variables. It returns the
+// content written to STDOUT and STDERR and the exit code.
+func run(t *testing.T, env []string, input string, args ...string) (string, string, int) {
+	t.Helper()
+	cmd := command(env, args...)
+	cmd.Stdin = strings.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	if _, ok := err.(*exec.ExitError); err != nil && !ok {
+		t.Fatal(err)
+	}
+	return stdout.String(), stderr.String(), cmd.ProcessState.ExitCode()
+}
+
+func TestChangedOnly(t *testing.T) {
+	env := []string{"C_VOLUME_PATH=/mnt/c"}
+	for _, c := range []struct {
+		args  []string
+		input string
+		want  string
+	}{
+		{[]string{"-x", "--changed-only"}, "file.txt\nC:\\x\n/mnt/c/y\nD\\z\n", "/mnt/c/x\nD/z\n"},
+		{[]string{"-x"}, "file.txt\nC:\\x\n/mnt/c/y\n", "file.txt\n/mnt/c/x\n/mnt/c/y\n"},
+		{[]string{"-w", "--changed-only"}, "C:\\x\n/mnt/c/y\n", "C:\\y\n"},
+		{[]string{"-w", "--changed-only"}, "C:\\x\n", ""},
+	} {
+		if got, stderr, _ := run(t, env, c.input, c.args...); got != c.want {
+			t.Errorf("%q: got %q; want %q (%s)", c.args, got, c.want, stderr)
+		}
+	}
+}
+
+func TestPSEscapeOutput(t *testing.T) {
+	env := []string{"C_VOLUME_PATH=/mnt/c"}
+	for _, c := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-w", "--ps-escape"}, "C:\\it''s\nC:\\$x\nC:\\a`b\n"},
+		{[]string{"-w", "--ps-escape=double"}, "C:\\it's\nC:\\`$x\nC:\\a``b\n"},
+	} {
+		got, stderr, _ := run(t, env, "/mnt/c/it's\n/mnt/c/$x\n/mnt/c/a`b\n", c.args...)
+		if got != c.want {
+			t.Errorf("%q: got %q; want %q (%s)", c.args, got, c.want, stderr)
+		}
+	}
+}
+
+func TestDriveLetterOnly(t *testing.T) {
+	env := []string{"C_VOLUME_PATH=/mnt/c"}
+	for _, c := range []struct {
+		args  []string
+		input string
+		want  string
+	}{
+		{[]string{"-x", "--drive-letter-only"}, "C\nC/\nC\\foo\nfile\n", "/mnt/c\n/mnt/c\n/mnt/c/foo\nfile\n"},
+		{[]string{"-x"}, "C\nC\\foo\n", "C\nC/foo\n"},
+	} {
+		if got, stderr, _ := run(t, env, c.input, c.args...); got != c.want {
+			t.Errorf("%q: got %q; want %q (%s)", c.args, got, c.want, stderr)
+		}
+	}
+}
+
+func TestClassifyOutput(t *testing.T) {
+	env := []string{"C_VOLUME_PATH=/mnt/c", `WSL_ROOTFS_PATH=\\wsl$\Ubuntu`}
+	got, stderr, _ := run(t, env, "/mnt/c/x\n/proc/1\n/home\n", "--classify")
+	if want := "drvfs-rw\nnone\nrootfs-ro\n"; got != want {
+		t.Errorf("got %q; want %q (%s)", got, want, stderr)
+	}
+}
+
+func TestDetectOnly(t *testing.T) {
+	const input = "C:foo\nC:/x\nC:\\x\n\\\\host\\share\n/a\n./a\nfile\n"
+	const want = "windows\nwindows\nwindows\nwindows\nunix\nunix\nany\n"
+	for _, args := range [][]string{
+		{"--detect-only"},
+		{"--echo-format"},
+		// the target format is ignored
+		{"--detect-only", "-x"},
+		{"--detect-only", "-w"},
+	} {
+		if got, stderr, _ := run(t, nil, input, args...); got != want {
+			t.Errorf("%q: got %q; want %q (%s)", args, got, want, stderr)
+		}
+	}
+}
+
+func TestBaseDirOutput(t *testing.T) {
+	env := []string{"C_VOLUME_PATH=/mnt/c"}
+	for _, c := range []struct {
+		args  []string
+		input string
+		want  string
+	}{
+		{[]string{"-x", "--basename"}, "C:\\a\\b.txt\nC:\\\n", "b.txt\nc\n"},
+		{[]string{"-x", "--dirname"}, "C:\\a\\b.txt\nC:\\\n", "/mnt/c/a\n/mnt\n"},
+		{[]string{"-w", "--basename"}, "/mnt/c/a/b.txt\n/mnt/c\n", "b.txt\n\\\n"},
+		{[]string{"-w", "--dirname"}, "/mnt/c/a/b.txt\n/mnt/c\n", "C:\\a\nC:\\\n"},
+	} {
+		if got, stderr, _ := run(t, env, c.input, c.args...); got != c.want {
+			t.Errorf("%q: got %q; want %q (%s)", c.args, got, c.want, stderr)
+		}
+	}
+}
+
+func TestAssert(t *testing.T) {
+	env := []string{"C_VOLUME_PATH=/mnt/c"}
+	for _, c := range []struct {
+		args   []string
+		input  string
+		want   string
+		errors []string
+		code   int
+	}{
+		{[]string{"-x", "--assert", "windows"}, "C:\\x\nC:/y\n", "/mnt/c/x\n/mnt/c/y\n", nil, 0},
+		{[]string{"-x", "--assert", "windows"}, "C:\\x\n/a\nfile\n", "/mnt/c/x\n",
+			[]string{`"/a": detected unix, expected windows`, `"file": detected any, expected windows`}, 1},
+		{[]string{"-w", "--assert=unix"}, "/mnt/c/x\nC:\\y\n", "C:\\x\n",
+			[]string{`"C:\\y": detected windows, expected unix`}, 1},
+	} {
+		got, stderr, code := run(t, env, c.input, c.args...)
+		if got != c.want || code != c.code {
+			t.Errorf("%q: got %q, exit %d; want %q, exit %d (%s)", c.args, got, code, c.want, c.code, stderr)
+		}
+		for _, e := range c.errors {
+			if !strings.Contains(stderr, e) {
+				t.Errorf("%q: error %q not reported: %q", c.args, e, stderr)
+			}
+		}
+	}
+}
+
+func TestPathList(t *testing.T) {
+	env := []string{"C_VOLUME_PATH=/mnt/c", `WSL_ROOTFS_PATH=\\wsl$\Ubuntu`}
+	const input = "/usr/bin:/mnt/c/Windows/system32:/mnt/c/Windows\n"
+	for _, c := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-w", "--path-list"}, "\\\\wsl$\\Ubuntu\\usr\\bin;C:\\Windows\\system32;C:\\Windows\n"},
+		{[]string{"-w", "--path-list", "--drop-unconvertible"}, "C:\\Windows\\system32;C:\\Windows\n"},
+	} {
+		if got, stderr, _ := run(t, env, input, c.args...); got != c.want {
+			t.Errorf("%q: got %q; want %q (%s)", c.args, got, c.want, stderr)
+		}
+	}
+}
+
+func TestCanonicalOutput(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "Projects", "App"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(dir, "Projects"), filepath.Join(dir, "Link")); err != nil {
+		t.Fatal(err)
+	}
+	env := []string{"C_VOLUME_PATH=" + dir}
+	for _, c := range []struct {
+		args  []string
+		input string
+		want  string
+	}{
+		{[]string{"-w", "--canonical"}, dir + "/link/./app/\n", "C:\\Projects\\App\n"},
+		{[]string{"-x", "--canonical"}, "C:\\link\\app\n", dir + "/Projects/App\n"},
+	} {
+		if got, stderr, _ := run(t, env, c.input, c.args...); got != c.want {
+			t.Errorf("%q: got %q; want %q (%s)", c.args, got, c.want, stderr)
+		}
+	}
+}
+
+func TestExpandHomeOutput(t *testing.T) {
+	env := []string{"HOME=/home/me", "C_VOLUME_PATH=/mnt/c", `WSL_ROOTFS_PATH=\\wsl$\Ubuntu`}
+	for _, c := range []struct {
+		args  []string
+		input string
+		want  string
+	}{
+		{[]string{"-w", "--expand-home"}, "~/x\n/mnt/c/PROGRA~1\n", "\\\\wsl$\\Ubuntu\\home\\me\\x\nC:\\PROGRA~1\n"},
+		{[]string{"-x", "--expand-home"}, "C:\\PROGRA~1\\app\n", "/mnt/c/PROGRA~1/app\n"},
+	} {
+		if got, stderr, _ := run(t, env, c.input, c.args...); got != c.want {
+			t.Errorf("%q: got %q; want %q (%s)", c.args, got, c.want, stderr)
+		}
+	}
+}
+
+func TestTildifyOutput(t *testing.T) {
+	env := []string{"HOME=/mnt/c/Users/me", "C_VOLUME_PATH=/mnt/c"}
+	for _, c := range []struct {
+		args  []string
+		input string
+		want  string
+	}{
+		{[]string{"-x", "--tildify"}, "C:\\Users\\me\\x\nC:\\Users\\me\nC:\\y\n", "~/x\n~\n/mnt/c/y\n"},
+		// only Unix results are abbreviated
+		{[]string{"-w", "--tildify"}, "/mnt/c/Users/me/x\n", "C:\\Users\\me\\x\n"},
+	} {
+		if got, stderr, _ := run(t, env, c.input, c.args...); got != c.want {
+			t.Errorf("%q: got %q; want %q (%s)", c.args, got, c.want, stderr)
+		}
+	}
+}
+
+func TestWarnConflicts(t *testing.T) {
+	env := []string{"C_VOLUME_PATH=/mnt/c"}
+	got, stderr, code := run(t, env, "C:\\src\\app\nC:\\x\n",
+		"-x", "--warn-conflicts", "--prefix-map", `C:\src=>/workspace`)
+	if want := "/workspace/app\n/mnt/c/x\n"; got != want || code != 0 {
+		t.Errorf("got %q, exit %d; want %q", got, code, want)
+	}
+	const warning = `warning: conflicting mappings for "C:\\src\\app": ` +
+		`prefix-map="/workspace/app", C_VOLUME_PATH="/mnt/c/src/app"` + "\n"
+	if stderr != warning {
+		t.Errorf("stderr = %q; want %q", stderr, warning)
+	}
+}
+
+func TestEqualExit(t *testing.T) {
+	env := []string{"C_VOLUME_PATH=/mnt/c"}
+	for _, c := range []struct {
+		args []string
+		code int
+	}{
+		{[]string{"--equal", `C:\Users`, "/mnt/c/Users"}, 0},
+		{[]string{"--equal", `C:\Users`, "/mnt/c/users/"}, 0},
+		{[]string{"--equal", `C:\Users`, "/mnt/c/Windows"}, 1},
+		{[]string{"--equal", `C:\Users`}, 100},
+	} {
+		if _, stderr, code := run(t, env, "", c.args...); code != c.code {
+			t.Errorf("%q: exit %d; want %d (%s)", c.args, code, c.code, stderr)
+		}
+	}
+}
+
+func TestDepthOutput(t *testing.T) {
+	env := []string{"C_VOLUME_PATH=/mnt/c", `WSL_UNC_PATH=\\host\share=/mnt/share`}
+	for _, c := range []struct {
+		args  []string
+		input string
+		want  string
+	}{
+		// the depth is below the mount point, not the Unix root
+		{[]string{"-w", "--depth"}, "/mnt/c/a/b\n/mnt/c\n/mnt/share/x\n", "2\n0\n1\n"},
+		{[]string{"-x", "--depth"}, "C:\\a\\b\nC:\\\n\\\\host\\share\\x\n", "2\n0\n1\n"},
+	} {
+		if got, stderr, _ := run(t, env, c.input, c.args...); got != c.want {
+			t.Errorf("%q: got %q; want %q (%s)", c.args, got, c.want, stderr)
+		}
+	}
+}
+
+func TestExpandOutput(t *testing.T) {
+	env := []string{"C_VOLUME_PATH=/mnt/c", `TEMP=C:\Users\me\AppData\Local\Temp`}
+	got, stderr, code := run(t, env, "%TEMP%\\file\n%APPDATA%\\x\n",
+		"-x", "--expand", "--var", `APPDATA=C:\Users\me\AppData\Roaming`)
+	if want := "/mnt/c/Users/me/AppData/Local/Temp/file\n/mnt/c/Users/me/AppData/Roaming/x\n"; got != want || code != 0 {
+		t.Errorf("got %q, exit %d; want %q (%s)", got, code, want, stderr)
+	}
+	got, stderr, code = run(t, env, "%LOCALAPPDATA%\\x\n", "-x", "--expand")
+	if got != "" || code == 0 || !strings.Contains(stderr, "-var LOCALAPPDATA=PATH") {
+		t.Errorf("got %q, exit %d, %q; want error with guidance", got, code, stderr)
+	}
+}
+
+func TestCollapseOutput(t *testing.T) {
+	env := []string{"C_VOLUME_PATH=/mnt/c"}
+	for _, c := range []struct {
+		args  []string
+		input string
+		want  string
+	}{
+		{[]string{"-x", "--collapse-redundant-volume"}, "C:/mnt/c/x\nC:\\y\n", "/mnt/c/x\n/mnt/c/y\n"},
+		{[]string{"-w", "--collapse-redundant-volume"}, "C:\\mnt\\c\\x\n/mnt/c/y\n", "C:\\x\nC:\\y\n"},
+		{[]string{"-x"}, "C:/mnt/c/x\n", "/mnt/c/mnt/c/x\n"},
+	} {
+		if got, stderr, _ := run(t, env, c.input, c.args...); got != c.want {
+			t.Errorf("%q: got %q; want %q (%s)", c.args, got, c.want, stderr)
+		}
+	}
+}
+
+func TestMakeEscapeOutput(t *testing.T) {
+	env := []string{"C_VOLUME_PATH=/mnt/c"}
+	got, stderr, _ := run(t, env, "C:\\Program Files\\$x\n", "-x", "--make-escape")
+	if want := "/mnt/c/Program\\ Files/$$x\n"; got != want {
+		t.Errorf("got %q; want %q (%s)", got, want, stderr)
+	}
+	// incompatible with JSON output
+	if _, _, code := run(t, env, "C:\\x\n", "-x", "--make-escape", "--json"); code != 100 {
+		t.Errorf("-make-escape -json: exit %d; want 100", code)
+	}
+}
+
+func TestUNCOnly(t *testing.T) {
+	env := []string{"C_VOLUME_PATH=/mnt/c", `WSL_UNC_PATH=\\host\share=/mnt/share`, `WSL_ROOTFS_PATH=\\wsl$\Ubuntu`}
+	for _, c := range []struct {
+		args  []string
+		input string
+		want  string
+	}{
+		{[]string{"-x", "--unc-only"}, "C:\\x\n\\\\host\\share\\y\n\\\\wsl$\\Ubuntu\\z\n", "/mnt/share/y\n"},
+		{[]string{"-w", "--unc-only"}, "/mnt/c/x\n/mnt/share/y\n/home\n", "\\\\host\\share\\y\n"},
+	} {
+		if got, stderr, _ := run(t, env, c.input, c.args...); got != c.want {
+			t.Errorf("%q: got %q; want %q (%s)", c.args, got, c.want, stderr)
+		}
+	}
+}
+
+func TestRootfsEscape(t *testing.T) {
+	env := []string{`WSL_ROOTFS_PATH=\\wsl$\Ubuntu`}
+	for _, c := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-w", "--quote"}, "'\\\\wsl$\\Ubuntu\\x'\n"},
+		{[]string{"-w", "--ps-escape=double"}, "\\\\wsl`$\\Ubuntu\\x\n"},
+		// "$" is literal in single-quoted PowerShell strings
+		{[]string{"-w", "--ps-escape=single"}, "\\\\wsl$\\Ubuntu\\x\n"},
+	} {
+		if got, stderr, _ := run(t, env, "/x\n", c.args...); got != c.want {
+			t.Errorf("%q: got %q; want %q (%s)", c.args, got, c.want, stderr)
+		}
+	}
+}
+
+func TestVolumeStyleOutput(t *testing.T) {
+	env := []string{"C_VOLUME_PATH=/mnt/c", `WSL_UNC_PATH=\\server\c=/mnt/c`}
+	for _, c := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-w"}, "C:\\x\n"},
+		{[]string{"-w", "--output-volume-style=drive"}, "C:\\x\n"},
+		{[]string{"-w", "--output-volume-style=unc"}, "\\\\server\\c\\x\n"},
+	} {
+		if got, stderr, _ := run(t, env, "/mnt/c/x\n", c.args...); got != c.want {
+			t.Errorf("%q: got %q; want %q (%s)", c.args, got, c.want, stderr)
+		}
+	}
+}
+
+func TestTimingOutput(t *testing.T) {
+	env := []string{"C_VOLUME_PATH=/mnt/c"}
+	for _, c := range []struct {
+		args   []string
+		timing bool
+	}{
+		{[]string{"-x"}, false},
+		{[]string{"-x", "--timing"}, true},
+		{[]string{"-x", "-L", "--timing"}, true},
+	} {
+		stdout, stderr, _ := run(t, env, "C:\\x\nC:\\y\n", c.args...)
+		if stdout != "/mnt/c/x\n/mnt/c/y\n" {
+			t.Errorf("%q: got %q; want converted paths only", c.args, stdout)
+		}
+		if got := strings.Contains(stderr, "timing: 2 paths in "); got != c.timing {
+			t.Errorf("%q: timing summary on STDERR = %t; want %t (%s)", c.args, got, c.timing, stderr)
+		}
+	}
+}
+
+func TestLimit(t *testing.T) {
+	env := []string{"C_VOLUME_PATH=/mnt/c"}
+	for _, c := range []struct {
+		args  []string
+		input string
+		want  string
+		warn  bool
+	}{
+		{[]string{"-x", "--limit", "2"}, "C:\\a\nC:\\b\nC:\\c\n", "/mnt/c/a\n/mnt/c/b\n", true},
+		{[]string{"-x", "--limit", "3"}, "C:\\a\nC:\\b\nC:\\c\n", "/mnt/c/a\n/mnt/c/b\n/mnt/c/c\n", false},
+		{[]string{"-x", "--limit", "5"}, "C:\\a\n", "/mnt/c/a\n", false},
+		{[]string{"-x", "--limit", "0"}, "C:\\a\nC:\\b\n", "/mnt/c/a\n/mnt/c/b\n", false},
+		{[]string{"-x", "--limit", "1", "--skip-blank"}, "\nC:\\a\nC:\\b\n", "\n/mnt/c/a\n", true},
+	} {
+		got, stderr, _ := run(t, env, c.input, c.args...)
+		if got != c.want {
+			t.Errorf("%q: got %q; want %q (%s)", c.args, got, c.want, stderr)
+		}
+		if warn := strings.Contains(stderr, "-limit"); warn != c.warn {
+			t.Errorf("%q: warning on STDERR = %t; want %t (%s)", c.args, warn, c.warn, stderr)
+		}
+	}
+}
+
+func TestBareFileName(t *testing.T) {
+	env := []string{"C_VOLUME_PATH=/mnt/c"}
+	for _, c := range []struct {
+		args []string
+		want string
+		code int
+	}{
+		{[]string{"--passthrough-any"}, "file.txt\n", 0},
+		{[]string{"-w", "--passthrough-any"}, "file.txt\n", 0},
+		{[]string{"-x", "--passthrough-any"}, "file.txt\n", 0},
+		{[]string{"--error-any"}, "", 1},
+		{[]string{"-w", "--error-any"}, "", 1},
+		{[]string{"-x", "--error-any"}, "", 1},
+		{[]string{"--passthrough-any", "--error-any"}, "", 100},
+	} {
+		got, stderr, code := run(t, env, "file.txt\n", c.args...)
+		if got != c.want || code != c.code {
+			t.Errorf("%q: got %q, exit %d; want %q, exit %d (%s)", c.args, got, code, c.want, c.code, stderr)
+		}
+	}
+	// only bare names are affected
+	if got, stderr, _ := run(t, env, "file.txt\nC:\\x\n", "-x", "--error-any"); got != "/mnt/c/x\n" {
+		t.Errorf("-x --error-any: got %q; want %q (%s)", got, "/mnt/c/x\n", stderr)
+	}
+}
+
+func TestLineList(t *testing.T) {
+	env := []string{"C_VOLUME_PATH=/mnt/c", "D_VOLUME_PATH=/mnt/d"}
+	for _, c := range []struct {
+		args  []string
+		input string
+		want  string
+	}{
+		{[]string{"-x", "--line-list"}, "C:\\a;D:\\b\nC:\\c\nD:\\d;;C:\\e\n", "/mnt/c/a:/mnt/d/b\n/mnt/c/c\n/mnt/d/d::/mnt/c/e\n"},
+		{[]string{"-w", "--line-list"}, "/mnt/c/a;/mnt/d/b\n/mnt/c/c;/mnt/d/d\n", "C:\\a;D:\\b\nC:\\c;D:\\d\n"},
+		{[]string{"-x", "--line-list", "--list-sep", ","}, "C:\\a,D:\\b\nC:\\c,C:\\d\n", "/mnt/c/a:/mnt/d/b\n/mnt/c/c:/mnt/c/d\n"},
+	} {
+		if got, stderr, _ := run(t, env, c.input, c.args...); got != c.want {
+			t.Errorf("%q: got %q; want %q (%s)", c.args, got, c.want, stderr)
+		}
+	}
+	if _, _, code := run(t, env, "", "-x", "--line-list", "--path-list"); code != 100 {
+		t.Errorf("-line-list -path-list: exit %d; want 100", code)
+	}
+}
+
+func TestVersionOutput(t *testing.T) {
+	for _, c := range []struct {
+		args []string
+		full bool
+	}{
+		{[]string{"-v"}, false},
+		{[]string{"--version-full"}, true},
+		{[]string{"-v", "--version-full"}, true},
+	} {
+		stdout, stderr, code := run(t, nil, "", c.args...)
+		lines := strings.Split(strings.TrimSuffix(stdout, "\n"), "\n")
+		if code != 0 || !strings.HasSuffix(lines[0], " version "+version) {
+			t.Errorf("%q: got %q, exit %d; want version line (%s)", c.args, stdout, code, stderr)
+		}
+		if full := len(lines) > 1; full != c.full {
+			t.Errorf("%q: got %q; want build metadata %t", c.args, stdout, c.full)
+		}
+	}
+}
+
+func TestRelativeTextualOutput(t *testing.T) {
+	env := []string{"C_VOLUME_PATH=/mnt/c"}
+	for _, c := range []struct {
+		args []string
+		want string
+		code int
+	}{
+		{[]string{"-w", "--relative-textual"}, "a\\b\\c\n", 0},
+		{[]string{"-w", "--relative-textual", "-a"}, "", 100},
+		{[]string{"-w", "--relative-textual", "--base", "/tmp"}, "", 100},
+	} {
+		got, stderr, code := run(t, env, "a/b/c\n", c.args...)
+		if got != c.want || code != c.code {
+			t.Errorf("%q: got %q, exit %d; want %q, exit %d (%s)", c.args, got, code, c.want, c.code, stderr)
+		}
+	}
+}
+
+func TestStrictMatchOutput(t *testing.T) {
+	env := []string{"C_VOLUME_PATH=/mnt/data", "D_VOLUME_PATH=/mnt/data"}
+	for _, c := range []struct {
+		args []string
+		code int
+	}{
+		{[]string{"-w"}, 0},
+		{[]string{"-w", "--strict-match"}, 1},
+	} {
+		if _, stderr, code := run(t, env, "/mnt/data/x\n", c.args...); code != c.code {
+			t.Errorf("%q: exit %d; want %d (%s)", c.args, code, c.code, stderr)
+		}
+	}
+}
+
+func TestDriveAnchor(t *testing.T) {
+	env := []string{"D_VOLUME_PATH=/mnt/d", `WSL_UNC_PATH=\\host\share=/mnt/share`}
+	for _, c := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-x", "--drive", `\\host\share`}, "/mnt/share/sub/file\n"},
+		{[]string{"-x", "--drive", `\\host\share\dir`}, "/mnt/share/dir/sub/file\n"},
+		{[]string{"-x", "--drive", `D:`}, "/mnt/d/sub/file\n"},
+	} {
+		if got, stderr, _ := run(t, env, "sub\\file\n", c.args...); got != c.want {
+			t.Errorf("%q: got %q; want %q (%s)", c.args, got, c.want, stderr)
+		}
+	}
+}
+
+func TestArrayOutput(t *testing.T) {
+	env := []string{"C_VOLUME_PATH=/mnt/c"}
+	for _, c := range []struct {
+		args  []string
+		input string
+		want  string
+	}{
+		{[]string{"-x", "--bash-array"}, "C:\\a\nC:\\b c\n", "(/mnt/c/a '/mnt/c/b c')\n"},
+		{[]string{"-w", "--ps-array"}, "/mnt/c/a\n/mnt/c/b c\n", "@('C:\\a','C:\\b c')\n"},
+		// failed conversions are omitted from the array
+		{[]string{"-x", "--bash-array", "-e"}, "C:\\a\nZ:\\b\n", "(/mnt/c/a)\n"},
+	} {
+		if got, stderr, _ := run(t, env, c.input, c.args...); got != c.want {
+			t.Errorf("%q: got %q; want %q (%s)", c.args, got, c.want, stderr)
+		}
+	}
+	if _, _, code := run(t, env, "", "-x", "--bash-array", "--ps-array"); code != 100 {
+		t.Errorf("-bash-array -ps-array: exit %d; want 100", code)
+	}
+}
+
+func TestNormalizeUnicodeOutput(t *testing.T) {
+	env := []string{"C_VOLUME_PATH=/mnt/c"}
+	for _, c := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-x", "--normalize-unicode"}, "/mnt/c/Users/me\n"},
+		// without the flag, the colon is not a drive designator
+		{[]string{"-x"}, "C：/Users/me\n"},
+	} {
+		if got, stderr, _ := run(t, env, "C：\\Users\\me\n", c.args...); got != c.want {
+			t.Errorf("%q: got %q; want %q (%s)", c.args, got, c.want, stderr)
+		}
+	}
+}
+
+func TestMountTableCheckOutput(t *testing.T) {
+	// no drive is mounted at this point in the mount table of any host
+	env := []string{"C_VOLUME_PATH=/nonexistent/wslpath/c"}
+	for _, c := range []struct {
+		args []string
+		warn int
+	}{
+		{[]string{"-x"}, 0},
+		{[]string{"-x", "--resolve-against-mount-table"}, 1},
+	} {
+		stdout, stderr, _ := run(t, env, "C:\\x\nC:\\y\n", c.args...)
+		if stdout != "/nonexistent/wslpath/c/x\n/nonexistent/wslpath/c/y\n" {
+			t.Errorf("%q: got %q; want converted paths (%s)", c.args, stdout, stderr)
+		}
+		// each stale mapping is reported only once
+		if n := strings.Count(stderr, "warning: C_VOLUME_PATH="); n != c.warn {
+			t.Errorf("%q: %d warnings on STDERR; want %d (%s)", c.args, n, c.warn, stderr)
+		}
+	}
+}
+
+func TestWithStatus(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	mnt := filepath.Join(dir, "c")
+	for _, d := range []string{filepath.Join(mnt, "exists"), filepath.Join(dir, "rootfs")} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	env := []string{"C_VOLUME_PATH=" + mnt, `WSL_ROOTFS_PATH=\\wsl$\Ubuntu`}
+	for _, c := range []struct {
+		args  []string
+		input string
+		want  string
+	}{
+		{[]string{"-x", "--with-status"}, "C:\\exists\nC:\\missing\n",
+			mnt + "/exists\texists\n" + mnt + "/missing\tmissing\n"},
+		{[]string{"-w", "--with-status"}, mnt + "/exists\n" + mnt + "/missing\n",
+			"C:\\exists\texists\nC:\\missing\tmissing\n"},
+		// results in the WSL rootfs are not inspected, even if they exist
+		{[]string{"-w", "--with-status"}, dir + "/rootfs\n",
+			`\\wsl$\Ubuntu` + strings.ReplaceAll(dir, "/", `\`) + "\\rootfs\tunchecked\n"},
+	} {
+		if got, stderr, _ := run(t, env, c.input, c.args...); got != c.want {
+			t.Errorf("%q: got %q; want %q (%s)", c.args, got, c.want, stderr)
+		}
+	}
+	if _, _, code := run(t, env, "", "-x", "--with-status", "--depth"); code != 100 {
+		t.Errorf("-with-status -depth: exit %d; want 100", code)
+	}
+}
+
+func TestMixedOutput(t *testing.T) {
+	env := []string{"C_VOLUME_PATH=/mnt/c", `WSL_UNC_PATH=\\host\share=/mnt/share`}
+	for _, c := range []struct {
+		args []string
+		want string
+		code int
+	}{
+		{[]string{"-m"}, "C:/Users/me\n//host/share/doc\n", 0},
+		{[]string{"--mixed"}, "C:/Users/me\n//host/share/doc\n", 0},
+		{[]string{"-w"}, "C:\\Users\\me\n\\\\host\\share\\doc\n", 0},
+		{[]string{"-m", "-w"}, "", 100},
+		{[]string{"-m", "-x"}, "", 100},
+	} {
+		got, stderr, code := run(t, env, "/mnt/c/Users/me\n/mnt/share/doc\n", c.args...)
+		if got != c.want || code != c.code {
+			t.Errorf("%q: got %q, exit %d; want %q, exit %d (%s)", c.args, got, code, c.want, c.code, stderr)
+		}
+	}
+}
+
+func TestSkipLines(t *testing.T) {
+	env := []string{"C_VOLUME_PATH=/mnt/c"}
+	const input = "# paths\nC:\\a\n\n  \t\n  # indented\nC:\\b # not a comment\n"
+	for _, c := range []struct {
+		args  []string
+		input string
+		want  string
+	}{
+		{[]string{"-x", "--skip-blank", "--skip-comment", "#"}, input,
+			"# paths\n/mnt/c/a\n\n  \t\n  # indented\n/mnt/c/b # not a comment\n"},
+		{[]string{"-x", "--skip-blank", "--skip-comment", "#", "--drop-skipped"}, input,
+			"/mnt/c/a\n/mnt/c/b # not a comment\n"},
+		// blank lines are converted without -skip-blank
+		{[]string{"-x", "--skip-comment", "#", "--drop-skipped"}, "# paths\nC:\\a\n\n", "/mnt/c/a\n.\n"},
+		// the comment prefix is configurable
+		{[]string{"-x", "--skip-comment", "//"}, "// paths\nC:\\a\n", "// paths\n/mnt/c/a\n"},
+	} {
+		if got, stderr, _ := run(t, env, c.input, c.args...); got != c.want {
+			t.Errorf("%q: got %q; want %q (%s)", c.args, got, c.want, stderr)
+		}
+	}
+}
+
+func TestVersionExit(t *testing.T) {
+	env := []string{"C_VOLUME_PATH=/mnt/c"}
+	for _, args := range [][]string{
+		{"-v"},
+		{"-v", "-x", `C:\a`},
+		{"-x", "-v"},
+	} {
+		// input is neither read nor converted
+		stdout, stderr, code := run(t, env, "C:\\b\n", args...)
+		if code != 0 || stderr != "" || strings.Count(stdout, "\n") != 1 || !strings.Contains(stdout, " version ") {
+			t.Errorf("%q: got %q, exit %d; want version line only (%s)", args, stdout, code, stderr)
+		}
+	}
+}
+
+func TestUNCMountRootOutput(t *testing.T) {
+	env := []string{`WSL_UNC_PATH=\\host\mapped=/media/mapped`}
+	for _, c := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-x", "--unc-mount-root", "/mnt"}, "/mnt/host/share/x\n/media/mapped/y\n"},
+		{[]string{"-x"}, "/media/mapped/y\n"},
+	} {
+		if got, stderr, _ := run(t, env, "\\\\host\\share\\x\n\\\\host\\mapped\\y\n", c.args...); got != c.want {
+			t.Errorf("%q: got %q; want %q (%s)", c.args, got, c.want, stderr)
+		}
+	}
+}
+
+func TestBaseOutput(t *testing.T) {
+	env := []string{"C_VOLUME_PATH=/mnt/c"}
+	for _, c := range []struct {
+		args  []string
+		input string
+		want  string
+		code  int
+	}{
+		{[]string{"-w", "--base", "/mnt/c/proj"}, "src/main.go\n", "C:\\proj\\src\\main.go\n", 0},
+		{[]string{"-x", "--base", `C:\proj`}, "src\\main.go\n", "/mnt/c/proj/src/main.go\n", 0},
+		// the base must be given in the source format
+		{[]string{"-w", "--base", `C:\proj`}, "src/main.go\n", "", 100},
+		{[]string{"-x", "--base", "/mnt/c/proj"}, "src\\main.go\n", "", 100},
+	} {
+		got, stderr, code := run(t, env, c.input, c.args...)
+		if got != c.want || code != c.code {
+			t.Errorf("%q: got %q, exit %d; want %q, exit %d (%s)", c.args, got, code, c.want, c.code, stderr)
+		}
+	}
+}
+
+func TestTrailingSepOutput(t *testing.T) {
+	env := []string{"C_VOLUME_PATH=/mnt/c"}
+	for _, c := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-w"}, "C:\\Users\nC:\\\n"},
+		{[]string{"-w", "-t"}, "C:\\Users\\\nC:\\\n"},
+	} {
+		if got, stderr, _ := run(t, env, "/mnt/c/Users/\n/mnt/c/\n", c.args...); got != c.want {
+			t.Errorf("%q: got %q; want %q (%s)", c.args, got, c.want, stderr)
+		}
+	}
+}
+
+func TestGroupByVolume(t *testing.T) {
+	env := []string{"C_VOLUME_PATH=/mnt/c", "D_VOLUME_PATH=/mnt/d", `WSL_UNC_PATH=\\host\share=/mnt/share`}
+	const input = "D:\\b\nC:\\z\n\\\\host\\share\\x\nc:\\a\nD:\\a\n"
+	for _, c := range []struct {
+		args           []string
+		stdout, stderr string
+	}{
+		{[]string{"-x", "--group-by-volume"},
+			"[C:]\n/mnt/c/a\n/mnt/c/z\n[D:]\n/mnt/d/a\n/mnt/d/b\n[\\\\host\\share]\n/mnt/share/x\n", ""},
+		{[]string{"-x", "--group-by-volume", "--group-headers-stderr"},
+			"/mnt/c/a\n/mnt/c/z\n/mnt/d/a\n/mnt/d/b\n/mnt/share/x\n", "[C:]\n[D:]\n[\\\\host\\share]\n"},
+	} {
+		stdout, stderr, _ := run(t, env, input, c.args...)
+		if stdout != c.stdout || stderr != c.stderr {
+			t.Errorf("%q: got %q, %q; want %q, %q", c.args, stdout, stderr, c.stdout, c.stderr)
+		}
+	}
+	// Unix paths are grouped by the Windows volume on which they reside
+	if got, stderr, _ := run(t, env, "/mnt/d/x\n/mnt/c/y\n", "-w", "--group-by-volume"); got != "[C:]\nC:\\y\n[D:]\nD:\\x\n" {
+		t.Errorf("-w --group-by-volume: got %q (%s)", got, stderr)
+	}
+}
+
+func TestPhysicalOutput(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "real"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("real", filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+	env := []string{"C_VOLUME_PATH=" + dir}
+	for _, c := range []struct {
+		args []string
+		want string
+		code int
+	}{
+		{[]string{"-w"}, "C:\\link\\f\n", 0},
+		{[]string{"-w", "-L"}, "C:\\link\\f\n", 0},
+		{[]string{"-w", "-P"}, "C:\\real\\f\n", 0},
+		{[]string{"-w", "-L", "-P"}, "", 100},
+	} {
+		got, stderr, code := run(t, env, dir+"/link/f\n", c.args...)
+		if got != c.want || code != c.code {
+			t.Errorf("%q: got %q, exit %d; want %q, exit %d (%s)", c.args, got, code, c.want, c.code, stderr)
+		}
+	}
+}
+
+func TestUnixRootOutput(t *testing.T) {
+	for _, c := range []struct {
+		env  []string
+		args []string
+		want string
+		code int
+	}{
+		{[]string{`WSL_ROOTFS_PATH=\\wsl$\Ubuntu`}, []string{"-w", "/"}, "\\\\wsl$\\Ubuntu\n", 0},
+		{[]string{`WSL_ROOTFS_PATH=\\wsl$\Ubuntu`}, []string{"-w", "-e", "/"}, "", 1},
+		{nil, []string{"-w", "/"}, "", 1},
+		{[]string{"C_VOLUME_PATH=/mnt/c"}, []string{"-x", `C:\`}, "/mnt/c\n", 0},
+	} {
+		got, stderr, code := run(t, c.env, "", c.args...)
+		if got != c.want || code != c.code {
+			t.Errorf("%q: got %q, exit %d; want %q, exit %d (%s)", c.args, got, code, c.want, c.code, stderr)
+		}
+	}
+}
+
+func TestInText(t *testing.T) {
+	env := []string{"C_VOLUME_PATH=/mnt/c"}
+	for _, c := range []struct {
+		args  []string
+		input string
+		want  string
+	}{
+		{[]string{"-x", "--in-text"}, "run \"C:\\tools\\x.exe\" --opt /mnt/c/data\n",
+			"run \"/mnt/c/tools/x.exe\" --opt /mnt/c/data\n"},
+		{[]string{"-w", "--in-text"}, "run '/mnt/c/tools/x.exe' --opt /mnt/c/data\nplain text\n",
+			"run 'C:\\tools\\x.exe' --opt /mnt/c/data\nplain text\n"},
+	} {
+		if got, stderr, _ := run(t, env, c.input, c.args...); got != c.want {
+			t.Errorf("%q: got %q; want %q (%s)", c.args, got, c.want, stderr)
+		}
+	}
+}
+
+func TestRemapDriveOutput(t *testing.T) {
+	env := []string{"C_VOLUME_PATH=/mnt/c", "E_VOLUME_PATH=/mnt/e"}
+	for _, c := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-w"}, "C:\\x\nE:\\y\n"},
+		{[]string{"-w", "--remap-drive", "C:=D:"}, "D:\\x\nE:\\y\n"},
+		{[]string{"-w", "--remap-drive", "C:=D:", "--remap-drive", "E:=F:"}, "D:\\x\nF:\\y\n"},
+		{[]string{"-m", "--remap-drive", "C:=D:"}, "D:/x\nE:/y\n"},
+	} {
+		if got, stderr, _ := run(t, env, "/mnt/c/x\n/mnt/e/y\n", c.args...); got != c.want {
+			t.Errorf("%q: got %q; want %q (%s)", c.args, got, c.want, stderr)
+		}
+	}
+}
+
+func TestCygpathOutput(t *testing.T) {
+	env := []string{"C_VOLUME_PATH=/mnt/c"}
+	for _, c := range []struct {
+		args []string
+		want string
+		code int
+	}{
+		{[]string{"-u", `C:\x`}, "/mnt/c/x\n", 0},
+		{[]string{"--unix", `C:\x`}, "/mnt/c/x\n", 0},
+		{[]string{"--windows", "/mnt/c/x"}, "C:\\x\n", 0},
+		{[]string{"--mixed", "/mnt/c/x"}, "C:/x\n", 0},
+		{[]string{"-am", "/mnt/c/x"}, "C:/x\n", 0},
+		{[]string{"-u", "-w", `C:\x`}, "", 100},
+	} {
+		got, stderr, code := run(t, env, "", c.args...)
+		if got != c.want || code != c.code {
+			t.Errorf("%q: got %q, %d; want %q, %d (%s)", c.args, got, code, c.want, c.code, stderr)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Output record terminators selectable with -output-sep, and NUL with -0.
+const (
+	LF   = "\n"
+	CRLF = "\r\n"
+	NUL  = "\x00"
+)
+
+// outputSep implements flag.Value, parsing a line separator name ("lf" or
+// "crlf") into the record terminator s.
+type outputSep struct{ s *string }
+
+func (outputSep) String() string { return "" }
+
+func (v outputSep) Set(s string) error {
+	switch strings.ToLower(s) {
+	case "lf":
+		*v.s = LF
+	case "crlf":
+		*v.s = CRLF
+	default:
+		return fmt.Errorf("unrecognized line separator: %q (must be lf or crlf)", s)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import "testing"
+
+func TestOutputSep(t *testing.T) {
+	for _, c := range []struct {
+		in, want string
+	}{
+		{"lf", LF},
+		{"LF", LF},
+		{"crlf", CRLF},
+		{"CrLf", CRLF},
+		{"cr", ""},
+		{"", ""},
+	} {
+		s := "unset"
+		err := outputSep{&s}.Set(c.in)
+		if c.want == "" {
+			if err == nil || s != "unset" {
+				t.Errorf("Set(%q) = %q, %v; want error", c.in, s, err)
+			}
+		} else if err != nil || s != c.want {
+			t.Errorf("Set(%q) = %q, %v; want %q", c.in, s, err, c.want)
+		}
+	}
+}
+
+func TestOutputSepOutput(t *testing.T) {
+	env := []string{"C_VOLUME_PATH=/mnt/c"}
+	for _, c := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-w"}, "C:\\x\nC:\\y\n"},
+		{[]string{"-w", "--output-sep", "lf"}, "C:\\x\nC:\\y\n"},
+		{[]string{"-w", "--output-sep", "crlf"}, "C:\\x\r\nC:\\y\r\n"},
+		{[]string{"-w", "--output-sep", "crlf", "--bash-array"}, "('C:\\x' 'C:\\y')\r\n"},
+	} {
+		if got, stderr, _ := run(t, env, "/mnt/c/x\n/mnt/c/y\n", c.args...); got != c.want {
+			t.Errorf("%q: got %q; want %q (%s)", c.args, got, c.want, stderr)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// Progress periodically reports the number of paths processed. All methods
+// are no-ops on a nil *Progress.
+type Progress struct {
+	w     io.Writer
+	every time.Duration
+	last  time.Time
+	n     int
+}
+
+// NewProgress returns a Progress that reports to w at most once per the given
+// interval.
+func NewProgress(w io.Writer, every time.Duration) *Progress {
+	return &Progress{w: w, every: every, last: time.Now()}
+}
+
+// Add counts a single processed path, reporting the total if the interval has
+// elapsed since the last report.
+func (p *Progress) Add() {
+	if p == nil {
+		return
+	}
+	p.n++
+	if now := time.Now(); now.Sub(p.last) >= p.every {
+		p.last = now
+		fmt.Fprintf(p.w, "\rprocessed %d paths", p.n)
+	}
+}
+
+// Done reports the final total, terminating the progress line.
+func (p *Progress) Done() {
+	if p == nil {
+		return
+	}
+	fmt.Fprintf(p.w, "\rprocessed %d paths\n", p.n)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgress(t *testing.T) {
+	var b bytes.Buffer
+	p := NewProgress(&b, 0)
+	for i := 0; i < 1000; i++ {
+		p.Add()
+	}
+	p.Done()
+	if got := strings.Count(b.String(), "\rprocessed "); got != 1001 {
+		t.Errorf("reported %d times; want 1001", got)
+	}
+	if !strings.HasSuffix(b.String(), "\rprocessed 1000 paths\n") {
+		t.Errorf("final report = %q; want total of 1000", b.String()[b.Len()-32:])
+	}
+
+	b.Reset()
+	p = NewProgress(&b, time.Hour)
+	for i := 0; i < 1000; i++ {
+		p.Add()
+	}
+	p.Done()
+	if got, want := b.String(), "\rprocessed 1000 paths\n"; got != want {
+		t.Errorf("reported %q; want %q", got, want)
+	}
+
+	// a nil Progress reports nothing
+	p = nil
+	p.Add()
+	p.Done()
+}
+
+func TestProgressPiped(t *testing.T) {
+	input := strings.Repeat("C:\\x\n", 10000)
+	got, stderr, code := run(t, []string{"C_VOLUME_PATH=/mnt/c"}, input, "-x", "--progress")
+	if code != 0 || got != strings.Repeat("/mnt/c/x\n", 10000) {
+		t.Errorf("exit %d; got %.32q... (%s)", code, got, stderr)
+	}
+	if strings.Contains(stderr, "processed") {
+		t.Errorf("progress reported to a pipe: %q", stderr)
+	}
+}
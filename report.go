@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ardnew/wslpath/wslpath"
+)
+
+// ErrorReport describes an error encountered while processing a single input.
+type ErrorReport struct {
+	// Input is the input being processed when the error occurred.
+	Input string `json:"input"`
+	// Op identifies the failed operation (e.g., "Format").
+	Op string `json:"op"`
+	// Code is the ErrorCode of the error.
+	Code wslpath.ErrorCode `json:"code"`
+	// Message is the error message.
+	Message string `json:"message"`
+}
+
+// Result describes the conversion of a single input, as written with -json.
+// Output and Format are null if the conversion failed, and Error is null
+// otherwise.
+type Result struct {
+	// Input is the input converted.
+	Input string `json:"input"`
+	// Output is the converted path.
+	Output *string `json:"output"`
+	// Format is the Format of Output.
+	Format *string `json:"format"`
+	// Rootfs is true if Output is a path into the WSL rootfs, which should only
+	// be used for read-only operations.
+	Rootfs bool `json:"rootfs"`
+	// Error is the error message.
+	Error *string `json:"error"`
+}
+
+// WriteResult writes the successful conversion of the given input to output,
+// in the given Format, as a single line of JSON (Result) to w.
+func WriteResult(w io.Writer, input, output string, format wslpath.Format, rootfs bool) {
+	f := format.String()
+	_ = json.NewEncoder(w).Encode(Result{
+		Input: input, Output: &output, Format: &f, Rootfs: rootfs,
+	})
+}
+
+// ErrorReporter writes each error encountered while processing inputs to w,
+// either as plain text or as a single line of JSON (ErrorReport), and counts
+// the errors written.
+type ErrorReporter struct {
+	w      io.Writer
+	json   bool
+	result bool
+	input  bool
+	failed int
+}
+
+// NewErrorReporter returns an ErrorReporter writing to w in the given format,
+// which is either "plain", "json", or "result" (a Result, as with -json). If
+// input is true, each plain error is prefixed with the input being processed,
+// which is always included in JSON errors.
+func NewErrorReporter(w io.Writer, format string, input bool) *ErrorReporter {
+	return &ErrorReporter{w: w, json: format == "json", result: format == "result", input: input}
+}
+
+// Failed returns the number of errors reported.
+func (e *ErrorReporter) Failed() int {
+	return e.failed
+}
+
+// Report writes the given error, encountered by operation op while processing
+// the given input.
+func (e *ErrorReporter) Report(op, input string, err error) {
+	e.failed++
+	if e.result {
+		msg := err.Error()
+		_ = json.NewEncoder(e.w).Encode(Result{Input: input, Error: &msg})
+		return
+	}
+	if e.json {
+		_ = json.NewEncoder(e.w).Encode(ErrorReport{
+			Input: input, Op: op, Code: wslpath.CodeOf(err), Message: err.Error(),
+		})
+		return
+	}
+	if e.input {
+		fmt.Fprintf(e.w, "error: %s: %s(): %v\n", input, op, err)
+		return
+	}
+	fmt.Fprintf(e.w, "error: %s(): %v\n", op, err)
+}
+
+// errorFormat implements flag.Value, accepting an ErrorReporter format name.
+type errorFormat struct{ s *string }
+
+func (errorFormat) String() string { return "" }
+
+func (v errorFormat) Set(s string) error {
+	switch s = strings.ToLower(s); s {
+	case "plain", "json":
+		*v.s = s
+		return nil
+	}
+	return fmt.Errorf("unrecognized error format: %q (must be plain or json)", s)
+}
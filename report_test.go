@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ardnew/wslpath/wslpath"
+)
+
+func TestErrorReporter(t *testing.T) {
+	err := &wslpath.Error{Code: wslpath.ErrEnvNotSet,
+		Err: errors.New("environment variable not set: Q_VOLUME_PATH")}
+	for _, c := range []struct {
+		format string
+		input  bool
+		want   string
+	}{
+		{"plain", false, "error: Format(): " + err.Error() + "\n"},
+		{"plain", true, `error: Q:\x: Format(): ` + err.Error() + "\n"},
+	} {
+		var b bytes.Buffer
+		e := NewErrorReporter(&b, c.format, c.input)
+		e.Report("Format", `Q:\x`, err)
+		if b.String() != c.want || e.Failed() != 1 {
+			t.Errorf("%s: Report() = %q, %d failed; want %q", c.format, b.String(), e.Failed(), c.want)
+		}
+	}
+
+	var b bytes.Buffer
+	e := NewErrorReporter(&b, "json", false)
+	e.Report("Format", `Q:\x`, err)
+	e.Report("Identify", "file", errors.New("detected any"))
+	d := json.NewDecoder(&b)
+	for _, want := range []ErrorReport{
+		{Input: `Q:\x`, Op: "Format", Code: wslpath.ErrEnvNotSet, Message: err.Error()},
+		{Input: "file", Op: "Identify", Code: wslpath.ErrOther, Message: "detected any"},
+	} {
+		var got ErrorReport
+		if err := d.Decode(&got); err != nil || got != want {
+			t.Errorf("json: Report() = %+v, %v; want %+v", got, err, want)
+		}
+	}
+	if e.Failed() != 2 {
+		t.Errorf("json: Failed() = %d; want 2", e.Failed())
+	}
+}
+
+func TestErrorFormat(t *testing.T) {
+	for _, c := range []struct{ in, want string }{
+		{"plain", "plain"},
+		{"JSON", "json"},
+		{"result", ""},
+		{"xml", ""},
+	} {
+		s := "unset"
+		err := errorFormat{&s}.Set(c.in)
+		if c.want == "" {
+			if err == nil {
+				t.Errorf("Set(%q) = %q; want error", c.in, s)
+			}
+		} else if err != nil || s != c.want {
+			t.Errorf("Set(%q) = %q, %v; want %q", c.in, s, err, c.want)
+		}
+	}
+}
+
+func TestErrorFormatOutput(t *testing.T) {
+	_, stderr, code := run(t, nil, "C:\\x\n", "-x", "--error-format=json")
+	var got map[string]string
+	if err := json.Unmarshal([]byte(stderr), &got); err != nil {
+		t.Fatalf("stderr %q: %v", stderr, err)
+	}
+	want := map[string]string{
+		"input": `C:\x`, "op": "Format", "code": "env-not-set",
+		"message": "environment variable not set: C_VOLUME_PATH",
+	}
+	if len(got) != len(want) || code != 1 {
+		t.Errorf("got %v, exit %d; want %v, exit 1", got, code, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q; want %q", k, got[k], v)
+		}
+	}
+}
+
+func TestCountFailures(t *testing.T) {
+	env := []string{"C_VOLUME_PATH=/mnt/c"}
+	for _, c := range []struct {
+		args   []string
+		input  string
+		stdout string
+		errors []string
+		code   int
+	}{
+		{[]string{"-x", "--count-failures"}, "C:\\a\nY:\\b\nC:\\c\nZ:\\d\n", "/mnt/c/a\n/mnt/c/c\n",
+			[]string{"error: Y:\\b: Format(): ", "error: Z:\\d: Format(): ", "2 failure(s) in 4 input(s)"}, 2},
+		{[]string{"-x", "--count-failures"}, "C:\\a\n", "/mnt/c/a\n", []string{"0 failure(s) in 1 input(s)"}, 0},
+		{[]string{"-x"}, "C:\\a\nY:\\b\nZ:\\d\n", "/mnt/c/a\n",
+			[]string{"error: Format(): ", "error: Format(): "}, 1},
+		// the first failure stops processing
+		{[]string{"-x", "--strict"}, "C:\\a\nY:\\b\nC:\\c\nZ:\\d\n", "/mnt/c/a\n",
+			[]string{"error: Format(): ", "warning: stopped at the first failed path"}, 1},
+		{[]string{"-x", "--strict", "--count-failures"}, "Y:\\b\nZ:\\d\n", "",
+			[]string{"error: Y:\\b: Format(): ", "warning: stopped at the first failed path", "1 failure(s) in 1 input(s)"}, 1},
+	} {
+		stdout, stderr, code := run(t, env, c.input, c.args...)
+		lines := strings.Split(strings.TrimSuffix(stderr, "\n"), "\n")
+		if stderr == "" {
+			lines = nil
+		}
+		ok := stdout == c.stdout && code == c.code && len(lines) == len(c.errors)
+		for i := 0; ok && i < len(lines); i++ {
+			ok = strings.HasPrefix(lines[i], c.errors[i])
+		}
+		if !ok {
+			t.Errorf("%q: got %q, exit %d, %q; want %q, exit %d, %q", c.args, stdout, code, lines, c.stdout, c.code, c.errors)
+		}
+	}
+	// the exit code is capped below those of other errors
+	input := strings.Repeat("Z:\\x\n", 130)
+	if _, _, code := run(t, env, input, "-x", "--count-failures"); code != 125 {
+		t.Errorf("130 failures: exit %d; want 125", code)
+	}
+}
+
+func TestWriteResult(t *testing.T) {
+	var b bytes.Buffer
+	WriteResult(&b, "/etc/hosts", `\\wsl$\Ubuntu\etc\hosts`, wslpath.Windows, true)
+	want := `{"input":"/etc/hosts","output":"\\\\wsl$\\Ubuntu\\etc\\hosts","format":"windows","rootfs":true,"error":null}` + "\n"
+	if b.String() != want {
+		t.Errorf("WriteResult() = %q; want %q", b.String(), want)
+	}
+	b.Reset()
+	e := NewErrorReporter(&b, "result", false)
+	e.Report("Format", `Z:\x`, errors.New("environment variable not set: Z_VOLUME_PATH"))
+	want = `{"input":"Z:\\x","output":null,"format":null,"rootfs":false,"error":"environment variable not set: Z_VOLUME_PATH"}` + "\n"
+	if b.String() != want || e.Failed() != 1 {
+		t.Errorf("Report() = %q, %d failed; want %q", b.String(), e.Failed(), want)
+	}
+}
+
+func TestJSONOutput(t *testing.T) {
+	env := []string{"C_VOLUME_PATH=/mnt/c", `WSL_ROOTFS_PATH=\\wsl$\Ubuntu`}
+	str := func(s string) *string { return &s }
+	for _, c := range []struct {
+		args  []string
+		input string
+		want  []Result
+		code  int
+	}{
+		{[]string{"-w", "--json"}, "/mnt/c/x\n/etc/hosts\n", []Result{
+			{Input: "/mnt/c/x", Output: str(`C:\x`), Format: str("windows")},
+			{Input: "/etc/hosts", Output: str(`\\wsl$\Ubuntu\etc\hosts`), Format: str("windows"), Rootfs: true},
+		}, 0},
+		{[]string{"-x", "--json"}, "C:\\x\nZ:\\y\n", []Result{
+			{Input: `C:\x`, Output: str("/mnt/c/x"), Format: str("unix")},
+			{Input: `Z:\y`, Error: str("environment variable not set: Z_VOLUME_PATH")},
+		}, 1},
+		// with -0, input is NUL-delimited and output is still one line each
+		{[]string{"-x", "--json", "-0"}, "C:\\a\nb\x00", []Result{
+			{Input: "C:\\a\nb", Output: str("/mnt/c/a\nb"), Format: str("unix")},
+		}, 0},
+	} {
+		stdout, stderr, code := run(t, env, c.input, c.args...)
+		if code != c.code || stderr != "" || strings.Count(stdout, "\n") != len(c.want) {
+			t.Errorf("%q: got %q, exit %d, %q; want %d lines, exit %d", c.args, stdout, code, stderr, len(c.want), c.code)
+			continue
+		}
+		d := json.NewDecoder(strings.NewReader(stdout))
+		for _, want := range c.want {
+			var got Result
+			if err := d.Decode(&got); err != nil || !equalResult(got, want) {
+				t.Errorf("%q: got %s, %v; want %s", c.args, stdout, err, resultString(want))
+			}
+		}
+	}
+}
+
+// equalResult returns true if and only if the given Results are equal,
+// comparing the values of their optional fields.
+func equalResult(a, b Result) bool {
+	eq := func(x, y *string) bool { return (x == nil) == (y == nil) && (x == nil || *x == *y) }
+	return a.Input == b.Input && eq(a.Output, b.Output) && eq(a.Format, b.Format) &&
+		a.Rootfs == b.Rootfs && eq(a.Error, b.Error)
+}
+
+// resultString returns the given Result as JSON.
+func resultString(r Result) string {
+	b, _ := json.Marshal(r)
+	return string(b)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+)
+
+// WriteVersion writes the name and version number of the executable to w. If
+// full is true, the Go toolchain and platform are also written, followed by
+// the module version, VCS commit, and commit date recorded in the executable's
+// build information, if any (e.g., built from a module with "go build").
+func WriteVersion(w io.Writer, name string, full bool) {
+	fmt.Fprintln(w, name, "version", version)
+	if !full {
+		return
+	}
+	fmt.Fprintf(w, "  go:       %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	info, ok := debug.ReadBuildInfo()
+	if !ok || nil == info {
+		fmt.Fprintln(w, "  build:    (no build information)")
+		return
+	}
+	if "" != info.Main.Version {
+		fmt.Fprintf(w, "  module:   %s %s\n", info.Main.Path, info.Main.Version)
+	}
+	var rev, date string
+	dirty := false
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			rev = s.Value
+		case "vcs.time":
+			date = s.Value
+		case "vcs.modified":
+			dirty = "true" == s.Value
+		}
+	}
+	if "" != rev {
+		if dirty {
+			rev += " (modified)"
+		}
+		fmt.Fprintf(w, "  commit:   %s\n", rev)
+	}
+	if "" != date {
+		fmt.Fprintf(w, "  date:     %s\n", date)
+	}
+}
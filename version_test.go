@@ -0,0 +1,27 @@
+package main
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestWriteVersion(t *testing.T) {
+	for _, full := range []bool{false, true} {
+		var b bytes.Buffer
+		WriteVersion(&b, "wslpath", full)
+		lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+		if lines[0] != "wslpath version "+version {
+			t.Errorf("WriteVersion(full=%t) = %q; want version line first", full, b.String())
+		}
+		switch {
+		case !full && len(lines) != 1:
+			t.Errorf("WriteVersion(full=false) = %q; want version line only", b.String())
+		case full && (len(lines) < 3 || !strings.Contains(lines[1], runtime.Version())):
+			// build metadata, or a note that there is none, follows the
+			// toolchain line
+			t.Errorf("WriteVersion(full=true) = %q; want toolchain and build lines", b.String())
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package wslpath
+
+// Ancestors translates the given file path s, interpreted as a path in Format
+// f, to Format t, and returns the result followed by each of its parent
+// directories, up to and including the root of the volume on which it resides.
+//
+// The volume root is determined by the Windows path, so the ancestors of a
+// path on a mounted drive end with the drive root (e.g., `C:\` or "/mnt/c"),
+// never the directories above its mount point. Parent directories are
+// translated individually using the receiver Resolver r, and the list ends at
+// the first parent that cannot be translated (e.g., a UNC path above the path
+// given in its mapping).
+func (r *Resolver) Ancestors(f, t Format, s string, x bool) ([]string, error) {
+	p, _, err := r.Format(f, t, s, x, 0)
+	if nil != err {
+		return nil, err
+	}
+	if Windows != f {
+		return t.Ancestors(p), nil
+	}
+	a := f.Ancestors(s)
+	a[0] = t.Clean(p)
+	for i := 1; i < len(a); i++ {
+		if a[i], _, err = r.Format(f, t, a[i], x, 0); nil != err {
+			return a[:i], nil
+		}
+	}
+	return a, nil
+}
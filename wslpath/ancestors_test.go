@@ -0,0 +1,51 @@
+package wslpath
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAncestors(t *testing.T) {
+	isolate(t)
+	r := newResolver()
+	r.MapDrive('C', "/mnt/c")
+	r.MapUNC(`\\host\share`, "/mnt/share")
+	r.MapUNC(`\\host\other\dir`, "/mnt/dir")
+	for _, c := range []struct {
+		f, t Format
+		in   string
+		want []string
+	}{
+		{Windows, Unix, `C:\a\b`, []string{"/mnt/c/a/b", "/mnt/c/a", "/mnt/c"}},
+		{Windows, Unix, `C:\`, []string{"/mnt/c"}},
+		{Unix, Windows, "/mnt/c/a/b", []string{`C:\a\b`, `C:\a`, `C:\`}},
+		{Windows, Unix, `\\host\share\a\b`, []string{"/mnt/share/a/b", "/mnt/share/a", "/mnt/share"}},
+		{Unix, Windows, "/mnt/share/a", []string{`\\host\share\a`, `\\host\share\`}},
+		// the list ends at the first parent that cannot be translated
+		{Windows, Unix, `\\host\other\dir\a`, []string{"/mnt/dir/a", "/mnt/dir"}},
+	} {
+		got, err := r.Ancestors(c.f, c.t, c.in, true)
+		if err != nil || !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Ancestors(%q) = %q, %v; want %q", c.in, got, err, c.want)
+		}
+	}
+	if got, err := r.Ancestors(Windows, Unix, `D:\a`, true); err == nil {
+		t.Errorf("Ancestors(%q) = %q; want error", `D:\a`, got)
+	}
+}
+
+func TestFormatAncestors(t *testing.T) {
+	for _, c := range []struct {
+		f    Format
+		in   string
+		want []string
+	}{
+		{Unix, "/a/b/", []string{"/a/b", "/a", "/"}},
+		{Unix, "a/b", []string{"a/b", "a"}},
+		{Windows, `C:\a\..\b\c`, []string{`C:\b\c`, `C:\b`, `C:\`}},
+	} {
+		if got := c.f.Ancestors(c.in); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s.Ancestors(%q) = %q; want %q", c.f, c.in, got, c.want)
+		}
+	}
+}
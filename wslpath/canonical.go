@@ -0,0 +1,32 @@
+package wslpath
+
+import "path/filepath"
+
+// Canonical returns the canonical form of the given Unix file path s, which is
+// the result of applying the following steps in order:
+//
+//  1. Anchor a relative path to the current working directory.
+//  2. Correct the case of each path element with RealCase.
+//  3. Resolve symbolic links in the longest existing prefix of the path.
+//  4. Clean the path.
+//
+// Elements of s that do not exist are kept unchanged.
+func Canonical(s string) string {
+	if p, err := filepath.Abs(s); nil == err {
+		s = p
+	}
+	return Unix.Clean(evalSymlinks(RealCase(s)))
+}
+
+// evalSymlinks returns the given absolute path s with all symbolic links in
+// its longest existing prefix resolved.
+func evalSymlinks(s string) string {
+	if p, err := filepath.EvalSymlinks(s); nil == err {
+		return p
+	}
+	dir, name := filepath.Split(s)
+	if dir = filepath.Clean(dir); dir == s || "" == name {
+		return s
+	}
+	return filepath.Join(evalSymlinks(dir), name)
+}
@@ -0,0 +1,37 @@
+package wslpath
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCanonical(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "Projects", "App"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(dir, "Projects"), filepath.Join(dir, "Link")); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+	for _, c := range []struct{ in, want string }{
+		{dir + "/Projects/App", dir + "/Projects/App"},
+		// real case
+		{dir + "/projects/app", dir + "/Projects/App"},
+		// symbolic links, also found with the wrong case
+		{dir + "/Link/App", dir + "/Projects/App"},
+		{dir + "/link/app/", dir + "/Projects/App"},
+		// clean
+		{dir + "/./Projects//App/../App", dir + "/Projects/App"},
+		// relative paths
+		{"projects/app", dir + "/Projects/App"},
+		{"link", dir + "/Projects"},
+		// elements that do not exist are kept
+		{dir + "/link/app/New/file", dir + "/Projects/App/New/file"},
+	} {
+		if got := Canonical(c.in); got != c.want {
+			t.Errorf("Canonical(%q) = %q; want %q", c.in, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package wslpath
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// abspath returns the absolute path of the given file path s in Format f, as
+// resolved by resolve, within the receiver Resolver r's Root. Relative paths
+// are first joined to Base, if defined.
+//
+// If Root is defined, absolute Unix paths are interpreted relative to it: the
+// file system is inspected at Root joined with s, and the resolved path is
+// returned relative to Root again, so that volume mappings are matched against
+// the path as seen from within Root. If the working directory lies within
+// Root, relative paths are anchored to its location within Root. Symbolic
+// links are followed by the host, so absolute link targets refer to the host
+// file system, and paths resolving outside of Root are left unresolved.
+func (r *Resolver) abspath(f Format, s string) (string, error) {
+	defer r.Timing.AddFS(time.Now())
+	if b, ok := r.base(f); ok && !f.IsAbs(s) {
+		s = f.join(b, s)
+	}
+	if r.Root == "" || Unix != f {
+		return r.resolve(f, s)
+	}
+	root := Unix.Clean(r.Root)
+	if !f.IsAbs(s) {
+		wd, err := os.Getwd()
+		if err != nil {
+			return r.resolve(f, s)
+		}
+		if rel, ok := f.trimPrefix(wd, root); ok {
+			wd = f.join(string(f.sep()), rel)
+		}
+		s = f.join(wd, s)
+	}
+	p, err := r.resolve(f, filepath.Join(root, s))
+	if err != nil {
+		return "", err
+	}
+	if rel, ok := f.trimPrefix(p, root); ok {
+		return f.Clean(f.join(string(f.sep()), rel)), nil
+	}
+	return f.Clean(s), nil
+}
+
+// resolve returns the absolute path of the given file path s in Format f, as
+// resolved by Format.abspath. If the receiver Resolver r's Logical is enabled,
+// symbolic links are not resolved, and s is only anchored to the current
+// working directory (as given by $PWD, if valid) and cleaned.
+func (r *Resolver) resolve(f Format, s string) (string, error) {
+	if !r.Logical {
+		return f.abspath(s)
+	}
+	if !f.IsAbs(s) {
+		if wd, err := os.Getwd(); err == nil {
+			s = f.join(wd, s)
+		}
+	}
+	return f.Clean(s), nil
+}
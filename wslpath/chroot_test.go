@@ -0,0 +1,66 @@
+package wslpath
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCwdOnDrive(t *testing.T) {
+	isolate(t)
+	mnt := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(mnt, "projects", "app"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(filepath.Join(mnt, "projects"))
+	t.Setenv("PWD", filepath.Join(mnt, "projects"))
+	t.Setenv("C"+NixPathEnvSuffix, mnt)
+	for _, logical := range []bool{false, true} {
+		r := &Resolver{MountTable: os.DevNull, Logical: logical, Absolute: true}
+		for _, c := range []struct{ in, want string }{
+			{"app/main.go", `C:\projects\app\main.go`},
+			{".", `C:\projects`},
+			{"..", `C:\`},
+			{"../other", `C:\other`},
+		} {
+			got, _, err := r.Format(Unix, Windows, c.in, true, 0)
+			if err != nil || got != c.want {
+				t.Errorf("Logical=%t: Format(%q) = %q, %v; want %q", logical, c.in, got, err, c.want)
+			}
+		}
+	}
+}
+
+func TestChroot(t *testing.T) {
+	isolate(t)
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "mnt", "c", "Users"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("Users", filepath.Join(root, "mnt", "c", "link")); err != nil {
+		t.Fatal(err)
+	}
+	r := &Resolver{MountTable: os.DevNull, Root: root, Absolute: true}
+	r.MapDrive('C', "/mnt/c")
+	for _, c := range []struct {
+		wd       string
+		in, want string
+	}{
+		// symbolic links are resolved within the root
+		{"", "/mnt/c/link/x", `C:\Users\x`},
+		{"", "/mnt/c/Users", `C:\Users`},
+		// and relative paths are anchored to the working directory within it
+		{"mnt/c/Users", "x", `C:\Users\x`},
+		{"mnt/c/link", "../x", `C:\x`},
+	} {
+		t.Chdir(filepath.Join(root, c.wd))
+		got, _, err := r.Format(Unix, Windows, c.in, true, 0)
+		if err != nil || got != c.want {
+			t.Errorf("wd=%q: Format(%q) = %q, %v; want %q", c.wd, c.in, got, err, c.want)
+		}
+	}
+	// paths outside of the root are not mapped
+	if got, _, err := r.Format(Unix, Windows, "/home", true, 0); err == nil {
+		t.Errorf("Format(%q) = %q; want error", "/home", got)
+	}
+}
@@ -0,0 +1,78 @@
+package wslpath
+
+// Accessibility represents an enumeration of the ways in which a file path is
+// accessible from both the Windows host and the WSL user space.
+type Accessibility int
+
+const (
+	// NotAccessible paths cannot be accessed from the Windows host, such as
+	// WSL pseudo-file systems (e.g., "/proc") or paths with no mapping.
+	NotAccessible Accessibility = iota
+	// DrvfsReadWrite paths are stored on a Windows volume mounted in WSL
+	// with drvfs, and are safe to read and write from either context.
+	DrvfsReadWrite
+	// WSLRootfsReadOnly paths exist solely in the WSL virtual rootfs, and
+	// must only be read (never written) from the Windows host context.
+	WSLRootfsReadOnly
+)
+
+// PseudoFsRoots lists the mount points of WSL pseudo-file systems, whose
+// content is generated by the kernel and is inaccessible from Windows.
+var PseudoFsRoots = []string{"/proc", "/sys", "/dev", "/run"}
+
+// String returns a short name of the receiver Accessibility a.
+func (a Accessibility) String() string {
+	switch a {
+	case DrvfsReadWrite:
+		return "drvfs-rw"
+	case WSLRootfsReadOnly:
+		return "rootfs-ro"
+	}
+	return "none"
+}
+
+// Classify returns the Accessibility of the given file path, in either Windows
+// or Unix Format, using the receiver Resolver r's volume mappings.
+func (r *Resolver) Classify(path string) Accessibility {
+	switch Identify(path) {
+	case Windows:
+		if _, ok := r.fromRootfs(Windows.Clean(path)); ok {
+			return WSLRootfsReadOnly
+		}
+		if _, _, err := r.Format(Windows, Unix, path, true, 0); err != nil {
+			return NotAccessible
+		}
+		return DrvfsReadWrite
+	case Unix:
+		a, err := r.abspath(Unix, Unix.Clean(path))
+		if err != nil {
+			return NotAccessible
+		}
+		for _, p := range PseudoFsRoots {
+			if _, ok := Unix.trimPrefix(a, p); ok {
+				return NotAccessible
+			}
+		}
+		_, wsl, err := r.Format(Unix, Windows, path, false, 0)
+		switch {
+		case err != nil:
+			return NotAccessible
+		case wsl:
+			return WSLRootfsReadOnly
+		}
+		return DrvfsReadWrite
+	}
+	return NotAccessible
+}
+
+// IsUNC returns true if and only if the given Windows path s refers to a
+// network share by UNC path (e.g., `\\host\share\x`). Paths into the WSL
+// virtual rootfs (e.g., `\\wsl$\Ubuntu\x`) and device or volume GUID paths
+// (e.g., `\\?\Volume{GUID}\x`) are not network shares.
+func (r *Resolver) IsUNC(s string) bool {
+	if _, ok := r.fromRootfs(Windows.Clean(s)); ok {
+		return false
+	}
+	v, _ := Windows.SplitVolume(s)
+	return len(v) > 2 && v[0] == '\\' && v[1] == '\\' && v[2] != '?'
+}
@@ -0,0 +1,57 @@
+package wslpath
+
+import "testing"
+
+func TestClassify(t *testing.T) {
+	isolate(t)
+	t.Setenv(WslRootfsEnvVar, `\\wsl$\Ubuntu`)
+	r := newResolver()
+	t.Setenv("C"+NixPathEnvSuffix, "/mnt/c")
+	for _, c := range []struct {
+		in   string
+		want Accessibility
+	}{
+		{"/mnt/c/Users/me", DrvfsReadWrite},
+		{`C:\Users\me`, DrvfsReadWrite},
+		{"/home/me", WSLRootfsReadOnly},
+		{`\\wsl$\Ubuntu\home\me`, WSLRootfsReadOnly},
+		{"/proc/self/status", NotAccessible},
+		{"/dev/null", NotAccessible},
+		{"/sys", NotAccessible},
+		{"/processes", WSLRootfsReadOnly},
+		{`D:\x`, NotAccessible},
+		{"file.txt", NotAccessible},
+	} {
+		if got := r.Classify(c.in); got != c.want {
+			t.Errorf("Classify(%q) = %s; want %s", c.in, got, c.want)
+		}
+	}
+	// without a rootfs, paths found only there are not accessible
+	unsetenv(t, WslRootfsEnvVar)
+	if got := r.Classify("/home/me"); got != NotAccessible {
+		t.Errorf("Classify(%q) = %s; want %s", "/home/me", got, NotAccessible)
+	}
+}
+
+func TestIsUNC(t *testing.T) {
+	isolate(t)
+	t.Setenv(WslRootfsEnvVar, `\\wsl$\Ubuntu`)
+	r := newResolver()
+	for _, c := range []struct {
+		in   string
+		want bool
+	}{
+		{`\\host\share\x`, true},
+		{`\\host\share`, true},
+		{`\\192.168.1.10\c$`, true},
+		{`\\wsl$\Ubuntu\home`, false},
+		{`\\?\Volume{0b0fd6a2}\x`, false},
+		{`\\.\COM3`, false},
+		{`C:\x`, false},
+		{"/mnt/c", false},
+	} {
+		if got := r.IsUNC(c.in); got != c.want {
+			t.Errorf("IsUNC(%q) = %t; want %t", c.in, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package wslpath
+
+import "strings"
+
+// CollapseVolume returns the Unix path contained in the given malformed path s,
+// which has a drive designator followed by the mount point of that same drive
+// (e.g., "C:/mnt/c/x" or `C:\mnt\c\x`), as produced by some tools that prefix
+// a drive to an already-translated path. The returned bool is false, and s is
+// returned unchanged, if s does not have this form.
+func (r *Resolver) CollapseVolume(s string) (string, bool) {
+	if len(s) < 3 || s[1] != ':' || !isalpha(s[0]) || (s[2] != '/' && s[2] != '\\') {
+		return s, false
+	}
+	mp, _, err := r.lookupDrive(s[0])
+	if err != nil {
+		return s, false
+	}
+	p := Unix.Clean(strings.ReplaceAll(s[2:], `\`, "/"))
+	if _, ok := Unix.trimPrefix(p, Unix.Clean(mp)); !ok {
+		return s, false
+	}
+	return p, true
+}
@@ -0,0 +1,31 @@
+package wslpath
+
+import "testing"
+
+func TestCollapseVolume(t *testing.T) {
+	isolate(t)
+	r := newResolver()
+	r.MapDrive('C', "/mnt/c")
+	r.MapDrive('D', "/data")
+	for _, c := range []struct {
+		in, want string
+		ok       bool
+	}{
+		{"C:/mnt/c/x", "/mnt/c/x", true},
+		{`C:\mnt\c\x`, "/mnt/c/x", true},
+		{"c:/mnt/c", "/mnt/c", true},
+		{"C:/mnt/c/./x/", "/mnt/c/x", true},
+		{"D:/data/x", "/data/x", true},
+		// normal paths are untouched
+		{`C:\x`, `C:\x`, false},
+		{`C:\mnt\cd\x`, `C:\mnt\cd\x`, false},
+		{"D:/mnt/c/x", "D:/mnt/c/x", false},
+		{"E:/mnt/e/x", "E:/mnt/e/x", false},
+		{"/mnt/c/x", "/mnt/c/x", false},
+		{"C:mnt/c", "C:mnt/c", false},
+	} {
+		if got, ok := r.CollapseVolume(c.in); got != c.want || ok != c.ok {
+			t.Errorf("CollapseVolume(%q) = %q, %t; want %q, %t", c.in, got, ok, c.want, c.ok)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package wslpath
+
+import "strings"
+
+// Abbrev associates a path prefix with a short name used in its place when
+// displaying compact paths.
+type Abbrev struct {
+	Prefix, Short string
+}
+
+// Abbrevs returns the abbreviations used by Compact, which include the given
+// custom abbreviations followed by the defaults derived from the receiver
+// Resolver r's volume mappings: each drive mount point (e.g., "/mnt/c") and
+// drive root (e.g., `C:\`) is abbreviated "~c", and each UNC volume and its
+// mount point are abbreviated with a tilde followed by the share name (e.g.,
+// "\\host\share" is "~share"). If no volume mappings are defined, each drive
+// mount point under AutomountRoot is abbreviated instead, along with the root
+// of every drive.
+func (r *Resolver) Abbrevs(custom ...Abbrev) []Abbrev {
+	a := append([]Abbrev{}, custom...)
+	mounts, configured := r.driveMounts()
+	for _, m := range mounts {
+		d := string(m.drive)
+		a = append(a, Abbrev{m.path, "~" + strings.ToLower(d)}, Abbrev{d + `:\`, "~" + strings.ToLower(d)})
+	}
+	if !configured {
+		for d := 'a'; d <= 'z'; d++ {
+			a = append(a, Abbrev{AutomountRoot + "/" + string(d), "~" + string(d)},
+				Abbrev{strings.ToUpper(string(d)) + `:\`, "~" + string(d)})
+		}
+	}
+	for _, u := range r.uncMappings() {
+		v, _ := Windows.SplitVolume(u.volume)
+		if e := Windows.Elements(v); len(e) > 0 {
+			short := "~" + e[len(e)-1]
+			a = append(a, Abbrev{u.volume, short}, Abbrev{u.path, short})
+		}
+	}
+	return a
+}
+
+// Compact returns the given path s with its longest prefix matching any of the
+// given abbreviations replaced by that abbreviation's short name. The result
+// is intended for display only; it cannot be converted back to a valid path.
+func Compact(s string, abbrevs []Abbrev) string {
+	f := Identify(s)
+	var short, rest string
+	n := -1
+	for _, a := range abbrevs {
+		if Identify(a.Prefix) != f {
+			continue
+		}
+		p := f.Clean(a.Prefix)
+		if r, ok := f.trimPrefix(s, p); ok && len(p) > n {
+			short, rest, n = a.Short, r, len(p)
+		}
+	}
+	if n < 0 {
+		return s
+	}
+	if len(rest) > 0 && !f.issep(rune(rest[0])) {
+		rest = string(f.sep()) + rest
+	}
+	return short + rest
+}
@@ -0,0 +1,41 @@
+package wslpath
+
+import "testing"
+
+func TestCompact(t *testing.T) {
+	isolate(t)
+	t.Setenv("C"+NixPathEnvSuffix, "/mnt/c")
+	t.Setenv(UncPathEnvVar, `\\host\share=/mnt/share`)
+	r := newResolver()
+	abbrevs := r.Abbrevs(Abbrev{"/mnt/c/projects", "~proj"})
+	for _, c := range []struct{ in, want string }{
+		{"/mnt/c/Users/me", "~c/Users/me"},
+		{"/mnt/c", "~c"},
+		{`C:\Users\me`, `~c\Users\me`},
+		{`C:\`, "~c"},
+		{"/mnt/c/projects/x", "~proj/x"},
+		{`\\host\share\doc`, `~share\doc`},
+		{"/mnt/share/doc", "~share/doc"},
+		{"/mnt/cc/x", "/mnt/cc/x"},
+		{`D:\x`, `D:\x`},
+		{"/home/me", "/home/me"},
+	} {
+		if got := Compact(c.in, abbrevs); got != c.want {
+			t.Errorf("Compact(%q) = %q; want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestCompactAutomount(t *testing.T) {
+	isolate(t)
+	abbrevs := newResolver().Abbrevs()
+	for _, c := range []struct{ in, want string }{
+		{"/mnt/d/x", "~d/x"},
+		{`D:\x`, `~d\x`},
+		{`z:\x`, `~z\x`},
+	} {
+		if got := Compact(c.in, abbrevs); got != c.want {
+			t.Errorf("Compact(%q) = %q; want %q", c.in, got, c.want)
+		}
+	}
+}
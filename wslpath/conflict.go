@@ -0,0 +1,92 @@
+package wslpath
+
+import "os"
+
+// Candidate is the translation of a file path by a single mapping source.
+type Candidate struct {
+	// Source identifies the mapping used: "relative-root" for a relative
+	// root, "prefix-map" for a prefix map, the
+	// key of an explicit mapping (e.g., "map" or "FILE:LINE" from a map
+	// file), or the identifier of an environment variable.
+	Source string
+	Result string
+}
+
+// Candidates returns the translation of the given path s from Format f to
+// Format t by every mapping source that matches s, in order of precedence:
+//
+//  1. Relative roots (RelativeRoots)
+//  2. Prefix maps (PrefixMaps)
+//  3. Explicit volume mappings (MapDrive, MapUNC, or a map file)
+//  4. Volume mappings defined in the environment
+//
+// Only the first Candidate is used by Format. Drvfs mounts found in the mount
+// table, automount, and WSL rootfs paths apply only if no mapping source
+// matches, so they are never Candidates.
+func (r *Resolver) Candidates(f, t Format, s string) []Candidate {
+	var c []Candidate
+	if f == t || Any == f || Any == t {
+		return c
+	}
+	s = f.Clean(s)
+	if p, ok := r.reanchor(f, t, s); ok {
+		c = append(c, Candidate{Source: "relative-root", Result: p})
+	}
+	if p, ok := r.mapPrefix(f, t, s); ok {
+		c = append(c, Candidate{Source: "prefix-map", Result: p})
+	}
+	switch f {
+	case Windows:
+		v, p := f.SplitVolume(s)
+		if len(v) == 2 && v[1] == ':' && isalpha(v[0]) {
+			for _, m := range r.drives {
+				if m.drive == upper(v[0]) {
+					c = append(c, Candidate{Source: m.key, Result: r.convert(f, t, m.path, p)})
+				}
+			}
+			for _, e := range r.driveVars(v[0]) {
+				if dp, ok := os.LookupEnv(e); ok {
+					c = append(c, Candidate{Source: e, Result: r.convert(f, t, dp, p)})
+				}
+			}
+		} else {
+			for _, u := range r.uncMappings() {
+				if rest, ok := f.trimPrefix(s, u.volume); ok {
+					c = append(c, Candidate{Source: u.key, Result: r.convert(f, t, u.path, rest)})
+				}
+			}
+		}
+	case Unix:
+		for _, u := range r.uncMappings() {
+			if rest, ok := r.trimMount(s, u.path); ok {
+				c = append(c, Candidate{Source: u.key, Result: r.convert(f, t, u.volume, rest)})
+			}
+		}
+		mounts, _ := r.driveMounts()
+		for _, m := range mounts {
+			if rest, ok := r.trimMount(s, m.path); ok {
+				c = append(c, Candidate{Source: m.key,
+					Result: r.convert(f, t, string(m.drive)+":"+string(t.sep()), rest)})
+			}
+		}
+	}
+	return c
+}
+
+// Conflicts returns the Candidates of the given path s, as translated from
+// Format f to Format t, if and only if they do not all produce the same result.
+func (r *Resolver) Conflicts(f, t Format, s string) []Candidate {
+	c := r.Candidates(f, t, s)
+	for i := 1; i < len(c); i++ {
+		if c[i].Result != c[0].Result {
+			return c
+		}
+	}
+	return nil
+}
+
+// convert returns the given path rest in Format f appended to the given prefix
+// in Format t, translating separators as Format does.
+func (r *Resolver) convert(f, t Format, prefix, rest string) string {
+	return t.Clean(prefix + string(t.sep()) + r.replaceSep(f, t, rest))
+}
@@ -0,0 +1,80 @@
+package wslpath
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCandidates(t *testing.T) {
+	isolate(t)
+	t.Setenv("C"+NixPathEnvSuffix, "/mnt/c")
+	r := newResolver()
+	r.PrefixMaps = []PrefixMap{{From: `C:\src`, To: "/workspace"}}
+	for _, c := range []struct {
+		f, t Format
+		in   string
+		want []Candidate
+		diff bool
+	}{
+		// prefix maps take precedence over the environment
+		{Windows, Unix, `C:\src\app`, []Candidate{
+			{"prefix-map", "/workspace/app"},
+			{"C" + NixPathEnvSuffix, "/mnt/c/src/app"},
+		}, true},
+		{Unix, Windows, "/mnt/c/x", []Candidate{
+			{"C" + NixPathEnvSuffix, `C:\x`},
+		}, false},
+		{Unix, Windows, "/workspace/app", []Candidate{
+			{"prefix-map", `C:\src\app`},
+		}, false},
+		{Unix, Windows, "/home", nil, false},
+	} {
+		got := r.Candidates(c.f, c.t, c.in)
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Candidates(%q) = %v; want %v", c.in, got, c.want)
+		}
+		if got := r.Conflicts(c.f, c.t, c.in); (got != nil) != c.diff {
+			t.Errorf("Conflicts(%q) = %v; want conflict %t", c.in, got, c.diff)
+		}
+	}
+	// the first Candidate is the result of Format
+	if p, _, err := r.Format(Windows, Unix, `C:\src\app`, true, 0); err != nil || p != "/workspace/app" {
+		t.Errorf("Format(%q) = %q, %v; want %q", `C:\src\app`, p, err, "/workspace/app")
+	}
+
+	// explicit mappings take precedence over the environment
+	r.PrefixMaps = nil
+	r.MapDrive('C', "/data/c")
+	want := []Candidate{{"map", "/data/c/x"}, {"C" + NixPathEnvSuffix, "/mnt/c/x"}}
+	if got := r.Conflicts(Windows, Unix, `C:\x`); !reflect.DeepEqual(got, want) {
+		t.Errorf("Conflicts(%q) = %v; want %v", `C:\x`, got, want)
+	}
+	// mappings that agree do not conflict
+	r.MapDrive('C', "/mnt/c")
+	if got := r.Conflicts(Windows, Unix, `C:\x`); got != nil {
+		t.Errorf("Conflicts(%q) = %v; want none", `C:\x`, got)
+	}
+}
+
+func TestCandidatesSepReplacer(t *testing.T) {
+	isolate(t)
+	t.Setenv("C"+NixPathEnvSuffix, "/mnt/c")
+	r := newResolver()
+	r.SepReplacer = StructuralSep
+	for _, c := range []struct {
+		f, t     Format
+		in, want string
+	}{
+		{Unix, Windows, `/mnt/c/a\b/c`, "C:\\a\uf05cb\\c"},
+		{Windows, Unix, "C:\\a\uf05cb\\c", `/mnt/c/a\b/c`},
+	} {
+		// Candidates agree with Format
+		if p, _, err := r.Format(c.f, c.t, c.in, true, 0); err != nil || p != c.want {
+			t.Errorf("Format(%q) = %q, %v; want %q", c.in, p, err, c.want)
+		}
+		want := []Candidate{{"C" + NixPathEnvSuffix, c.want}}
+		if got := r.Candidates(c.f, c.t, c.in); !reflect.DeepEqual(got, want) {
+			t.Errorf("Candidates(%q) = %v; want %v", c.in, got, want)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package wslpath
+
+import (
+	"os"
+	"strings"
+)
+
+// DistroHosts are the UNC host names under which Windows exposes the file
+// system of each WSL distribution, as a share named after the distribution
+// (e.g., `\\wsl$\Ubuntu` or `\\wsl.localhost\Ubuntu`).
+var DistroHosts = []string{"wsl$", "wsl.localhost"}
+
+// fromDistro returns the absolute Unix path of the given Windows path s if it
+// is a UNC path on one of the DistroHosts (e.g., `\\wsl$\Ubuntu\home\me`),
+// along with the name of the distribution, which is the UNC share. The returned
+// bool is false if s is not such a path.
+func fromDistro(s string) (string, string, bool) {
+	v, p := Windows.SplitVolume(s)
+	if !strings.HasPrefix(v, `\\`) {
+		return s, "", false
+	}
+	e := Windows.Elements(v[2:])
+	if len(e) != 2 {
+		return s, "", false
+	}
+	for _, h := range DistroHosts {
+		if strings.EqualFold(e[0], h) {
+			p = ReplaceSep(Windows, Unix, p)
+			return Unix.Clean(string(Unix.sep()) + p), e[1], true
+		}
+	}
+	return s, "", false
+}
+
+// isActiveDistro returns true unless the active WSL distribution, identified by
+// DistroEnvVar, is known and differs from the given distribution d.
+func isActiveDistro(d string) bool {
+	a, set := os.LookupEnv(DistroEnvVar)
+	return !set || strings.EqualFold(a, d)
+}
@@ -0,0 +1,49 @@
+package wslpath
+
+import "testing"
+
+func TestDistroUNC(t *testing.T) {
+	isolate(t)
+	t.Setenv(DistroEnvVar, "Ubuntu")
+	r := newResolver()
+	for _, c := range []struct {
+		in, want string
+	}{
+		{`\\wsl$\Ubuntu\home\me\file`, "/home/me/file"},
+		{`\\wsl.localhost\Ubuntu\etc\hosts`, "/etc/hosts"},
+		{`\\WSL$\ubuntu`, "/"},
+		{`\\wsl.localhost\Ubuntu\`, "/"},
+		{`\\wsl$\Ubuntu\a\..\..\etc`, "/etc"},
+	} {
+		if got, _, err := r.Format(Windows, Unix, c.in, true, 0); err != nil || got != c.want {
+			t.Errorf("Format(%q) = %q, %v; want %q", c.in, got, err, c.want)
+		}
+	}
+	// only the active distribution is accessible through Unix paths
+	s := `\\wsl.localhost\Ubuntu-22.04\etc\hosts`
+	if got, _, err := r.Format(Windows, Unix, s, true, 0); CodeOf(err) != ErrNoMapping {
+		t.Errorf("Format(%q) = %q, %v; want %s", s, got, err, ErrNoMapping)
+	}
+}
+
+func TestRootfsHost(t *testing.T) {
+	isolate(t)
+	t.Setenv(WslRootfsEnvVar, `C:\rootfs`)
+	r := newResolver()
+	for _, c := range []struct {
+		host, distro string
+		want         string
+	}{
+		{"", "Ubuntu", `C:\rootfs\etc\hosts`},
+		{"wsl$", "Ubuntu", `\\wsl$\Ubuntu\etc\hosts`},
+		{"wsl.localhost", "Ubuntu-22.04", `\\wsl.localhost\Ubuntu-22.04\etc\hosts`},
+		// the distribution must be known to name its share
+		{"wsl$", "", `C:\rootfs\etc\hosts`},
+	} {
+		t.Setenv(DistroEnvVar, c.distro)
+		r.RootfsHost = c.host
+		if got, _, err := r.Format(Unix, Windows, "/etc/hosts", false, 0); err != nil || got != c.want {
+			t.Errorf("RootfsHost=%q, distro=%q: Format() = %q, %v; want %q", c.host, c.distro, got, err, c.want)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package wslpath
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// driveCwd returns the current directory (e.g., `C:\Users\me`) on the given
+// drive, which anchors drive-relative paths such as "C:foo".
+//
+// Drives not found in DriveCwds are looked up, and the directories found are
+// cached privately, so that they are never confused with those given
+// explicitly.
+func (r *Resolver) driveCwd(drive byte) (string, error) {
+	drive = upper(drive)
+	if cwd, ok := r.DriveCwds[drive]; ok {
+		return cwd, nil
+	}
+	r.mu.Lock()
+	cwd, ok := r.cwds[drive]
+	r.mu.Unlock()
+	if ok {
+		return cwd, nil
+	}
+	lookup := r.LookupDriveCwd
+	if lookup == nil {
+		lookup = cmdCwd
+	}
+	// the lookup may invoke a Windows utility, so the lock is not held
+	cwd, err := lookup(drive)
+	if err != nil {
+		return "", errorf(ErrNoMapping, "cannot determine current directory on drive %c: %v", drive, err)
+	}
+	v, p := Windows.SplitVolume(Windows.Clean(cwd))
+	if len(v) != 2 || upper(v[0]) != drive || len(p) == 0 || p[0] != '\\' {
+		return "", errorf(ErrInvalidPath, "invalid current directory on drive %c: %q", drive, cwd)
+	}
+	r.mu.Lock()
+	if r.cwds == nil {
+		r.cwds = map[byte]string{}
+	}
+	r.cwds[drive] = v + p
+	r.mu.Unlock()
+	return v + p, nil
+}
+
+// SetDriveCwd defines the current directory on the given drive, which anchors
+// drive-relative paths such as "C:foo".
+func (r *Resolver) SetDriveCwd(drive byte, cwd string) {
+	if r.DriveCwds == nil {
+		r.DriveCwds = map[byte]string{}
+	}
+	r.DriveCwds[upper(drive)] = cwd
+}
+
+// CwdOn returns the Unix path of the current directory on the given drive.
+func (r *Resolver) CwdOn(drive byte) (string, error) {
+	cwd, err := r.driveCwd(drive)
+	if err != nil {
+		return "", err
+	}
+	p, _, err := r.Format(Windows, Unix, cwd, false, 0)
+	return p, err
+}
+
+// cmdCwd returns the current directory on the given drive, by way of WSL
+// interop with the Windows command interpreter.
+//
+// WSL does not track a current directory per drive, so unless the current
+// directory in WSL is on the given drive, this is generally its root.
+func cmdCwd(drive byte) (string, error) {
+	out, err := exec.Command("cmd.exe", "/d", "/c", "cd", string(drive)+":").Output()
+	if err != nil {
+		return "", errorf(ErrInterop, "cmd.exe: %v", err)
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
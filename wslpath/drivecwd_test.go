@@ -0,0 +1,57 @@
+package wslpath
+
+import "testing"
+
+func TestDriveRelative(t *testing.T) {
+	isolate(t)
+	r := newResolver()
+	r.MapDrive('C', "/mnt/c")
+	r.SetDriveCwd('C', `C:\Users\me`)
+	for _, c := range []struct{ in, want string }{
+		{`C:`, "/mnt/c"},
+		{`C:\`, "/mnt/c"},
+		{`C:foo`, "/mnt/c/Users/me/foo"},
+		{`c:..\you`, "/mnt/c/Users/you"},
+	} {
+		got, _, err := r.Format(Windows, Unix, c.in, false, 0)
+		if err != nil || got != c.want {
+			t.Errorf("Format(%q) = %q, %v; want %q", c.in, got, err, c.want)
+		}
+	}
+	// a relative path naming a file "c:" is not the drive itself
+	for _, s := range []string{`.\c:`, `x\..\c:`, `x\..\C:\y`} {
+		if got, _, err := r.Format(Windows, Unix, s, false, 0); CodeOf(err) != ErrInvalidPath {
+			t.Errorf("Format(%q) = %q, %v; want %s", s, got, err, ErrInvalidPath)
+		}
+	}
+}
+
+func TestCwdOn(t *testing.T) {
+	isolate(t)
+	r := newResolver()
+	r.MapDrive('D', "/mnt/d")
+	var asked byte
+	r.LookupDriveCwd = func(drive byte) (string, error) {
+		asked = drive
+		return `D:\work`, nil
+	}
+	got, err := r.CwdOn('d')
+	if err != nil || got != "/mnt/d/work" {
+		t.Errorf("CwdOn('d') = %q, %v; want %q", got, err, "/mnt/d/work")
+	}
+	if asked != 'D' {
+		t.Errorf("LookupDriveCwd called with %q; want 'D'", asked)
+	}
+	// the directory found is cached, but not as if given explicitly
+	asked = 0
+	if got, err := r.CwdOn('D'); err != nil || got != "/mnt/d/work" || asked != 0 {
+		t.Errorf("CwdOn('D') = %q, %v (lookup %q); want %q without lookup", got, err, asked, "/mnt/d/work")
+	}
+	if r.DriveCwds != nil {
+		t.Errorf("DriveCwds = %q; want nil", r.DriveCwds)
+	}
+	r.LookupDriveCwd = func(byte) (string, error) { return `D:\elsewhere`, nil }
+	if _, err := r.CwdOn('E'); CodeOf(err) != ErrInvalidPath {
+		t.Errorf("CwdOn('E') error = %v; want %s", err, ErrInvalidPath)
+	}
+}
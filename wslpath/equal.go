@@ -0,0 +1,43 @@
+package wslpath
+
+import "strings"
+
+// Equal returns true if and only if the given paths a and b, each in either
+// Windows or Unix Format, refer to the same file path.
+//
+// Both paths are translated to a common Windows Format and cleaned. Paths on
+// Windows volumes are compared case-insensitively, while paths into the WSL
+// virtual rootfs, whose file system is case-sensitive, are compared exactly.
+func (r *Resolver) Equal(a, b string) (bool, error) {
+	pa, wa, err := r.windowsPath(a)
+	if err != nil {
+		return false, err
+	}
+	pb, wb, err := r.windowsPath(b)
+	if err != nil {
+		return false, err
+	}
+	if wa || wb {
+		return pa == pb, nil
+	}
+	return strings.EqualFold(pa, pb), nil
+}
+
+// windowsPath returns the given path s translated to a clean Windows path, and
+// true if it refers to the WSL virtual rootfs. Windows paths into the rootfs,
+// including those of the active distribution on any of the DistroHosts (e.g.,
+// `\\wsl.localhost\Ubuntu\home`), are first translated to Unix paths, so that
+// every path into the rootfs is addressed alike.
+func (r *Resolver) windowsPath(s string) (string, bool, error) {
+	if Windows == Identify(s) {
+		s = Windows.Clean(s)
+		if p, ok := r.fromRootfs(s); ok {
+			s = p
+		} else if p, d, ok := fromDistro(s); ok && isActiveDistro(d) {
+			s = p
+		} else {
+			return s, false, nil
+		}
+	}
+	return r.Format(Unix, Windows, s, false, 0)
+}
@@ -0,0 +1,40 @@
+package wslpath
+
+import "testing"
+
+func TestEqual(t *testing.T) {
+	isolate(t)
+	t.Setenv(WslRootfsEnvVar, `\\wsl$\Ubuntu`)
+	t.Setenv(DistroEnvVar, "Ubuntu")
+	r := newResolver()
+	r.MapDrive('C', "/mnt/c")
+	for _, c := range []struct {
+		a, b string
+		want bool
+	}{
+		{`C:\Users`, "/mnt/c/Users", true},
+		{`c:/users/`, "/mnt/c/Users", true},
+		{`C:\Users\..\Windows`, "/mnt/c/Windows/.", true},
+		{"/mnt/c/Users", "/mnt/c/users", true},
+		{`C:\Users`, "/mnt/c/Windows", false},
+		{`C:\Users`, `D:\Users`, false},
+		// the rootfs is case-sensitive
+		{`\\wsl$\Ubuntu\home`, "/home", true},
+		{"/home/Me", "/home/me", false},
+		{`\\wsl$\Ubuntu\home\Me`, "/home/me", false},
+		// as addressed on any distribution host
+		{`\\wsl.localhost\Ubuntu\home\u`, "/home/u", true},
+		{`\\wsl.localhost\Ubuntu\home`, `\\wsl$\Ubuntu\home`, true},
+		{`\\wsl.localhost\Ubuntu\home\Me`, "/home/me", false},
+		// but only that of the active distribution
+		{`\\wsl.localhost\Debian\home`, "/home", false},
+	} {
+		if got, err := r.Equal(c.a, c.b); err != nil || got != c.want {
+			t.Errorf("Equal(%q, %q) = %t, %v; want %t", c.a, c.b, got, err, c.want)
+		}
+	}
+	unsetenv(t, WslRootfsEnvVar)
+	if _, err := r.Equal(`C:\x`, "/home"); err == nil {
+		t.Errorf("Equal(%q, %q) succeeded without a rootfs; want error", `C:\x`, "/home")
+	}
+}
@@ -0,0 +1,58 @@
+package wslpath
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorCode classifies the errors returned while translating a file path.
+type ErrorCode string
+
+const (
+	// ErrEnvNotSet indicates a required environment variable is not defined.
+	ErrEnvNotSet ErrorCode = "env-not-set"
+	// ErrNoMapping indicates no volume mapping matches the given path.
+	ErrNoMapping ErrorCode = "no-mapping"
+	// ErrAmbiguous indicates more than one volume mapping equally matches
+	// the given path.
+	ErrAmbiguous ErrorCode = "ambiguous"
+	// ErrInvalidPath indicates the given path is malformed or cannot be
+	// represented in the target Format.
+	ErrInvalidPath ErrorCode = "invalid-path"
+	// ErrFormatMismatch indicates the given path is not in the expected
+	// Format.
+	ErrFormatMismatch ErrorCode = "format-mismatch"
+	// ErrDevicePath indicates the given path is a Windows device path (e.g.,
+	// `\\.\COM3`), which has no equivalent in the target Format.
+	ErrDevicePath ErrorCode = "device-path"
+	// ErrInterop indicates a Windows utility invoked via WSL interop failed.
+	ErrInterop ErrorCode = "interop"
+	// ErrOther classifies all other errors.
+	ErrOther ErrorCode = "error"
+)
+
+// Error is an error classified by an ErrorCode.
+type Error struct {
+	Code ErrorCode
+	Err  error
+}
+
+func (e *Error) Error() string { return e.Err.Error() }
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error { return e.Err }
+
+// errorf returns an *Error with the given ErrorCode and formatted message.
+func errorf(code ErrorCode, format string, a ...interface{}) error {
+	return &Error{Code: code, Err: fmt.Errorf(format, a...)}
+}
+
+// CodeOf returns the ErrorCode of the given error, or ErrOther if it does not
+// have one.
+func CodeOf(err error) ErrorCode {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return ErrOther
+}
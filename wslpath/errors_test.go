@@ -0,0 +1,47 @@
+package wslpath
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestCodeOf(t *testing.T) {
+	base := errors.New("base")
+	e := errorf(ErrNoMapping, "no mapping: %w", base)
+	for _, c := range []struct {
+		err  error
+		want ErrorCode
+	}{
+		{e, ErrNoMapping},
+		{fmt.Errorf("wrapped: %w", e), ErrNoMapping},
+		{&Error{Code: ErrInterop, Err: base}, ErrInterop},
+		{base, ErrOther},
+		{nil, ErrOther},
+	} {
+		if got := CodeOf(c.err); got != c.want {
+			t.Errorf("CodeOf(%v) = %s; want %s", c.err, got, c.want)
+		}
+	}
+	if e.Error() != "no mapping: base" || !errors.Is(e, base) {
+		t.Errorf("errorf() = %q; want %q wrapping %v", e, "no mapping: base", base)
+	}
+}
+
+func TestFormatErrorCodes(t *testing.T) {
+	isolate(t)
+	r := newResolver()
+	for _, c := range []struct {
+		f, t Format
+		in   string
+		want ErrorCode
+	}{
+		{Windows, Unix, `C:\x`, ErrEnvNotSet},
+		{Windows, Unix, `\\.\COM3`, ErrDevicePath},
+		{Unix, Windows, "/home", ErrNoMapping},
+	} {
+		if got, _, err := r.Format(c.f, c.t, c.in, true, 0); CodeOf(err) != c.want {
+			t.Errorf("Format(%q) = %q, %v; want %s", c.in, got, err, c.want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package wslpath
+
+import "strings"
+
+// QuoteStyle represents an enumeration of shell string literal quoting styles.
+type QuoteStyle int
+
+const (
+	// NoQuote indicates no escaping is performed.
+	NoQuote QuoteStyle = iota
+	// SingleQuote indicates a single-quoted (verbatim) string literal.
+	SingleQuote
+	// DoubleQuote indicates a double-quoted (expandable) string literal.
+	DoubleQuote
+)
+
+// PowerShellEscape returns the given string s escaped for safe inclusion in a
+// PowerShell string literal of the given QuoteStyle q. The returned string
+// does not include the enclosing quotes.
+//
+// Within single-quoted strings, PowerShell interprets every character
+// literally, except for the single quote itself (including its typographic
+// variants), which is escaped by doubling it.
+//
+// Within double-quoted strings, the backtick "`" is the escape character, and
+// each backtick, "$", and double quote (including its typographic variants)
+// is preceded by a backtick to prevent expansion.
+func PowerShellEscape(s string, q QuoteStyle) string {
+	var b strings.Builder
+	for _, c := range s {
+		switch q {
+		case SingleQuote:
+			switch c {
+			case '\'', '‘', '’', '‚', '‛':
+				b.WriteRune(c)
+			}
+		case DoubleQuote:
+			switch c {
+			case '`', '$', '"', '“', '”', '„':
+				b.WriteRune('`')
+			}
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// ShellQuote returns the given string s quoted for safe inclusion as a single
+// word in a POSIX shell (e.g., bash) command line. Strings containing only
+// characters that are never special to the shell are returned unchanged.
+// Otherwise, s is enclosed in single quotes, within which every character is
+// literal (e.g., the "$" in `\\wsl$\Ubuntu`), and each single quote is written
+// as '"'"'.
+func ShellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	safe := true
+	for _, c := range s {
+		if !(('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') ||
+			strings.ContainsRune("@%+=:,./_-", c)) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
+
+// BashArray returns the given strings as a single bash array literal (e.g.,
+// "(C:/a '/mnt/c/b c')"), with each element quoted by ShellQuote.
+func BashArray(a []string) string {
+	q := make([]string, len(a))
+	for i, s := range a {
+		q[i] = ShellQuote(s)
+	}
+	return "(" + strings.Join(q, " ") + ")"
+}
+
+// PowerShellArray returns the given strings as a single PowerShell array
+// literal (e.g., "@('C:\a','C:\b c')"), with each element single-quoted and
+// escaped by PowerShellEscape.
+func PowerShellArray(a []string) string {
+	q := make([]string, len(a))
+	for i, s := range a {
+		q[i] = "'" + PowerShellEscape(s, SingleQuote) + "'"
+	}
+	return "@(" + strings.Join(q, ",") + ")"
+}
+
+// MakeEscape returns the given string s escaped for safe inclusion in a
+// Makefile, such as in a variable definition or as a target or prerequisite.
+// Each space is preceded by a backslash, and each "$" is doubled to prevent
+// variable expansion.
+//
+// The result is only meaningful to make(1) and is not a valid path, so it is
+// not combined with any other output encoding (e.g., PowerShellEscape).
+func MakeEscape(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		switch c {
+		case ' ':
+			b.WriteRune('\\')
+		case '$':
+			b.WriteRune('$')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,85 @@
+package wslpath
+
+import "testing"
+
+func TestPowerShellEscape(t *testing.T) {
+	for _, c := range []struct {
+		in   string
+		q    QuoteStyle
+		want string
+	}{
+		{`C:\it's`, SingleQuote, `C:\it''s`},
+		{`C:\it’s`, SingleQuote, `C:\it’’s`},
+		{`C:\$env`, SingleQuote, `C:\$env`},
+		{"C:\\a`b", SingleQuote, "C:\\a`b"},
+		{`C:\it's`, DoubleQuote, `C:\it's`},
+		{`C:\$env`, DoubleQuote, "C:\\`$env"},
+		{"C:\\a`b", DoubleQuote, "C:\\a``b"},
+		{`C:\"a"`, DoubleQuote, "C:\\`\"a`\""},
+		{`C:\it's $x`, NoQuote, `C:\it's $x`},
+	} {
+		if got := PowerShellEscape(c.in, c.q); got != c.want {
+			t.Errorf("PowerShellEscape(%q, %d) = %q; want %q", c.in, c.q, got, c.want)
+		}
+	}
+}
+
+func TestMakeEscape(t *testing.T) {
+	for _, c := range []struct{ in, want string }{
+		{"/mnt/c/Program Files/app", `/mnt/c/Program\ Files/app`},
+		{`C:\a b\c d`, `C:\a\ b\c\ d`},
+		{"/mnt/c/$dir/x", "/mnt/c/$$dir/x"},
+		{"/a $b", `/a\ $$b`},
+		{"/plain/path", "/plain/path"},
+	} {
+		if got := MakeEscape(c.in); got != c.want {
+			t.Errorf("MakeEscape(%q) = %q; want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	for _, c := range []struct{ in, want string }{
+		{`\\wsl$\Ubuntu\x`, `'\\wsl$\Ubuntu\x'`},
+		{"/mnt/c/plain-path_1.txt", "/mnt/c/plain-path_1.txt"},
+		{"C:/a", "C:/a"},
+		{"/a b", "'/a b'"},
+		{"/it's", `'/it'"'"'s'`},
+		{"", "''"},
+	} {
+		if got := ShellQuote(c.in); got != c.want {
+			t.Errorf("ShellQuote(%q) = %q; want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestBashArray(t *testing.T) {
+	for _, c := range []struct {
+		in   []string
+		want string
+	}{
+		{[]string{"/mnt/c/a", "/mnt/c/b c"}, "(/mnt/c/a '/mnt/c/b c')"},
+		{[]string{`C:\it's`}, `('C:\it'"'"'s')`},
+		{[]string{""}, "('')"},
+		{nil, "()"},
+	} {
+		if got := BashArray(c.in); got != c.want {
+			t.Errorf("BashArray(%q) = %q; want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestPowerShellArray(t *testing.T) {
+	for _, c := range []struct {
+		in   []string
+		want string
+	}{
+		{[]string{`C:\a`, `C:\b c`}, `@('C:\a','C:\b c')`},
+		{[]string{`C:\it's`, `C:\$x`}, `@('C:\it''s','C:\$x')`},
+		{nil, "@()"},
+	} {
+		if got := PowerShellArray(c.in); got != c.want {
+			t.Errorf("PowerShellArray(%q) = %q; want %q", c.in, got, c.want)
+		}
+	}
+}
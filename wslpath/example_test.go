@@ -0,0 +1,90 @@
+package wslpath_test
+
+import (
+	"fmt"
+
+	"github.com/ardnew/wslpath/wslpath"
+)
+
+func ExampleIdentify() {
+	fmt.Println(wslpath.Identify(`C:\Users\me`))
+	fmt.Println(wslpath.Identify(`\\host\share\doc`))
+	fmt.Println(wslpath.Identify("/mnt/c/Users/me"))
+	fmt.Println(wslpath.Identify("file.txt"))
+	// Output:
+	// windows
+	// windows
+	// unix
+	// any
+}
+
+func ExampleFormat_Clean() {
+	fmt.Println(wslpath.Windows.Clean(`C:\a\..\b\`))
+	fmt.Println(wslpath.Windows.Clean(`C:/a//b`))
+	fmt.Println(wslpath.Unix.Clean("/a/./b/../c/"))
+	// Output:
+	// C:\b
+	// C:\a\b
+	// /a/c
+}
+
+func ExampleFormat_Format() {
+	// volume mappings given explicitly take precedence over the environment
+	// (e.g., C_VOLUME_PATH), so no live WSL environment is needed.
+	wslpath.DefaultResolver.MapDrive('C', "/mnt/c")
+
+	p, _, err := wslpath.Windows.Format(wslpath.Unix, `C:\Users\me`, false, 0)
+	fmt.Println(p, err)
+	p, _, err = wslpath.Unix.Format(wslpath.Windows, "/mnt/c/Windows/System32", false, 0)
+	fmt.Println(p, err)
+	// Output:
+	// /mnt/c/Users/me <nil>
+	// C:\Windows\System32 <nil>
+}
+
+func ExampleResolver_Format() {
+	r := &wslpath.Resolver{}
+	r.MapDrive('D', "/data")
+	r.MapUNC(`\\host\share`, "/mnt/share")
+
+	// Windows to Unix
+	p, _, err := r.Format(wslpath.Windows, wslpath.Unix, `D:\projects\wslpath`, false, 0)
+	fmt.Println(p, err)
+	p, _, err = r.Format(wslpath.Windows, wslpath.Unix, `\\host\share\doc`, false, 0)
+	fmt.Println(p, err)
+
+	// Unix to Windows
+	p, _, err = r.Format(wslpath.Unix, wslpath.Windows, "/data/projects/wslpath", false, 0)
+	fmt.Println(p, err)
+	p, _, err = r.Format(wslpath.Unix, wslpath.Windows, "/mnt/share/doc", false, 0)
+	fmt.Println(p, err)
+	// Output:
+	// /data/projects/wslpath <nil>
+	// /mnt/share/doc <nil>
+	// D:\projects\wslpath <nil>
+	// \\host\share\doc <nil>
+}
+
+func ExampleResolver_Format_prefixMap() {
+	r := &wslpath.Resolver{
+		PrefixMaps: []wslpath.PrefixMap{{From: `C:\src`, To: "/workspace"}},
+	}
+	p, _, err := r.Format(wslpath.Windows, wslpath.Unix, `C:\src\app\main.go`, false, 0)
+	fmt.Println(p, err)
+	p, _, err = r.Format(wslpath.Unix, wslpath.Windows, "/workspace/app", false, 0)
+	fmt.Println(p, err)
+	// Output:
+	// /workspace/app/main.go <nil>
+	// C:\src\app <nil>
+}
+
+func ExampleResolver_Format_rootfs() {
+	r := &wslpath.Resolver{}
+	r.MapDrive('C', "/mnt/c")
+
+	// with x true, paths found only in the WSL rootfs are an error
+	_, _, err := r.Format(wslpath.Unix, wslpath.Windows, "/etc/hosts", true, 0)
+	fmt.Println(wslpath.CodeOf(err))
+	// Output:
+	// no-mapping
+}
@@ -0,0 +1,127 @@
+package wslpath
+
+import (
+	"os"
+	"strings"
+)
+
+// DefaultSystemDrive is the Windows system drive assumed if it is neither
+// configured nor defined in the environment.
+const DefaultSystemDrive = "C:"
+
+// SystemDriveEnvVar is the Windows environment variable identifying the drive
+// on which Windows is installed.
+const SystemDriveEnvVar = "SystemDrive"
+
+// lookupEnvFold returns the value of the environment variable whose identifier
+// matches the given key, ignoring case like Windows does.
+func lookupEnvFold(key string) (string, bool) {
+	if v, ok := os.LookupEnv(key); ok {
+		return v, true
+	}
+	for _, e := range os.Environ() {
+		if n := strings.IndexRune(e, '='); n > 0 && strings.EqualFold(e[:n], key) {
+			return e[n+1:], true
+		}
+	}
+	return "", false
+}
+
+// systemDrive returns the Windows system drive (e.g., "C:"), using the
+// receiver Resolver r's SystemDrive if defined, otherwise the environment
+// variable SystemDriveEnvVar if defined, otherwise DefaultSystemDrive.
+func (r *Resolver) systemDrive() string {
+	if r.SystemDrive != "" {
+		return r.SystemDrive
+	}
+	if v, ok := lookupEnvFold(SystemDriveEnvVar); ok && len(v) == 2 &&
+		isalpha(v[0]) && v[1] == ':' {
+		return v
+	}
+	return DefaultSystemDrive
+}
+
+// UserPathVars are the Windows environment variables holding per-user paths,
+// which are generally not defined in the WSL environment. A reference to one
+// of these variables that cannot be expanded is an error.
+var UserPathVars = []string{"TEMP", "TMP", "APPDATA", "LOCALAPPDATA", "USERPROFILE"}
+
+// SetVar defines the value of the given Windows environment variable used for
+// expansion, overriding the environment.
+func (r *Resolver) SetVar(key, value string) {
+	if r.Vars == nil {
+		r.Vars = map[string]string{}
+	}
+	r.Vars[strings.ToUpper(key)] = value
+}
+
+// expand replaces each Windows environment variable reference "%NAME%" in the
+// given Windows path s with the value of that variable. SystemDriveEnvVar is
+// always defined, per systemDrive. References to undefined variables are left
+// unchanged, as they are by the Windows command interpreter, except for those
+// in UserPathVars, which return an error.
+//
+// If the expanded path is rooted but has no volume (e.g., "\Windows"), it is
+// anchored to the system drive.
+func (r *Resolver) expand(s string) (string, error) {
+	var b strings.Builder
+	for {
+		i := strings.IndexRune(s, '%')
+		if i < 0 {
+			break
+		}
+		j := strings.IndexRune(s[i+1:], '%')
+		if j < 0 {
+			break
+		}
+		j += i + 1
+		key := s[i+1 : j]
+		if v, ok := r.lookupVar(key); ok && len(key) > 0 {
+			b.WriteString(s[:i])
+			b.WriteString(v)
+			s = s[j+1:]
+		} else if isUserPathVar(key) {
+			return "", errorf(ErrEnvNotSet, "environment variable not defined in WSL: %%%s%% "+
+				"(define it with -var %s=PATH, or share it with WSLENV=%s)", key, key, key)
+		} else {
+			// not a variable reference, the closing "%" may open another
+			b.WriteString(s[:j])
+			s = s[j:]
+		}
+	}
+	b.WriteString(s)
+	s = b.String()
+	if v, _ := Windows.SplitVolume(s); v == "" && len(s) > 0 && s[0] == '\\' &&
+		(len(s) == 1 || s[1] != '\\') {
+		s = r.systemDrive() + s
+	}
+	return s, nil
+}
+
+// lookupVar returns the value of the given Windows environment variable.
+func (r *Resolver) lookupVar(key string) (string, bool) {
+	if strings.EqualFold(key, SystemDriveEnvVar) {
+		return r.systemDrive(), true
+	}
+	if v, ok := r.Vars[strings.ToUpper(key)]; ok {
+		return v, true
+	}
+	v, ok := lookupEnvFold(key)
+	// a variable holding a Unix path (e.g., TEMP=/tmp) belongs to Linux, not
+	// Windows, and cannot be spliced into a Windows path.
+	if ok && Unix == Identify(v) {
+		return "", false
+	}
+	return v, ok
+}
+
+// isUserPathVar returns true if and only if the given identifier is one of
+// UserPathVars, ignoring case.
+func isUserPathVar(key string) bool {
+	for _, v := range UserPathVars {
+		if strings.EqualFold(key, v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package wslpath
+
+import "testing"
+
+func TestSystemDrive(t *testing.T) {
+	isolate(t)
+	unsetenv(t, SystemDriveEnvVar)
+	unsetenv(t, "SYSTEMDRIVE")
+	r := newResolver()
+	r.Expand = true
+	t.Setenv("C"+NixPathEnvSuffix, "/mnt/c")
+	t.Setenv("D"+NixPathEnvSuffix, "/mnt/d")
+	t.Setenv("E"+NixPathEnvSuffix, "/mnt/e")
+	for _, c := range []struct {
+		env, drive string
+		in, want   string
+	}{
+		{"", "", `\Windows\System32`, "/mnt/c/Windows/System32"},
+		{"", "", `\Windows`, "/mnt/c/Windows"},
+		{"", "", `%SystemDrive%\Windows`, "/mnt/c/Windows"},
+		{"", "", `%SYSTEMDRIVE%\Windows`, "/mnt/c/Windows"},
+		{"D:", "", `\Windows`, "/mnt/d/Windows"},
+		{"D:", "", `%SystemDrive%\Windows`, "/mnt/d/Windows"},
+		{"D:", "E:", `\Windows`, "/mnt/e/Windows"},
+		{"invalid", "", `\Windows`, "/mnt/c/Windows"},
+		// only rooted paths without a volume are anchored
+		{"D:", "", `C:\Windows`, "/mnt/c/Windows"},
+	} {
+		if c.env != "" {
+			t.Setenv(SystemDriveEnvVar, c.env)
+		} else {
+			unsetenv(t, SystemDriveEnvVar)
+		}
+		r.SystemDrive = c.drive
+		got, _, err := r.Format(Windows, Unix, c.in, true, 0)
+		if err != nil || got != c.want {
+			t.Errorf("%s=%q, SystemDrive=%q: Format(%q) = %q, %v; want %q",
+				SystemDriveEnvVar, c.env, c.drive, c.in, got, err, c.want)
+		}
+	}
+}
+
+func TestExpandUserVars(t *testing.T) {
+	isolate(t)
+	for _, k := range append([]string{"HOME", "HOMEDRIVE"}, UserPathVars...) {
+		unsetenv(t, k)
+	}
+	r := newResolver()
+	r.Expand = true
+	r.MapDrive('C', "/mnt/c")
+	t.Setenv("TEMP", `C:\Users\me\AppData\Local\Temp`)
+	t.Setenv("TMP", "/tmp")
+	r.SetVar("appdata", `C:\Users\me\AppData\Roaming`)
+	for _, c := range []struct{ in, want string }{
+		{`%TEMP%\file`, "/mnt/c/Users/me/AppData/Local/Temp/file"},
+		{`%temp%\file`, "/mnt/c/Users/me/AppData/Local/Temp/file"},
+		{`%APPDATA%\Code`, "/mnt/c/Users/me/AppData/Roaming/Code"},
+		{`C:\100%\x`, "/mnt/c/100%/x"},
+		{`C:\%NOT_DEFINED%\x`, "/mnt/c/%NOT_DEFINED%/x"},
+		{`C:\a%%b`, "/mnt/c/a%%b"},
+	} {
+		got, _, err := r.Format(Windows, Unix, c.in, true, 0)
+		if err != nil || got != c.want {
+			t.Errorf("Format(%q) = %q, %v; want %q", c.in, got, err, c.want)
+		}
+	}
+	// user path variables that cannot be expanded are an error, including
+	// those holding a Unix path
+	for _, s := range []string{`%LOCALAPPDATA%\x`, `%TMP%\x`, `%UserProfile%`} {
+		got, _, err := r.Format(Windows, Unix, s, true, 0)
+		if CodeOf(err) != ErrEnvNotSet {
+			t.Errorf("Format(%q) = %q, %v; want %s", s, got, err, ErrEnvNotSet)
+		}
+	}
+	// and references are left alone unless expansion is enabled
+	r.Expand = false
+	if got, _, err := r.Format(Windows, Unix, `C:\%TEMP%`, true, 0); err != nil || got != "/mnt/c/%TEMP%" {
+		t.Errorf("Format(%q) = %q, %v; want %q", `C:\%TEMP%`, got, err, "/mnt/c/%TEMP%")
+	}
+}
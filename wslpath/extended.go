@@ -0,0 +1,81 @@
+package wslpath
+
+import (
+	"strings"
+	"unicode/utf16"
+)
+
+const (
+	// ExtendedPrefix is the prefix of an extended-length Windows path, which
+	// is exempt from MaxPath (e.g., `\\?\C:\path` or `\\?\UNC\host\share`).
+	ExtendedPrefix = `\\?\`
+	// MaxPath is the legacy limit on the length of a Windows path, including
+	// its terminating NUL character.
+	MaxPath = 260
+	// DevicePrefix is the prefix of a Windows device path (e.g., `\\.\COM3`
+	// or `\\.\PhysicalDrive0`), which refers to a device rather than a file,
+	// and has no Unix equivalent.
+	DevicePrefix = `\\.\`
+)
+
+// IsDevice returns true if and only if the given path is a Windows device
+// path, beginning with DevicePrefix.
+func IsDevice(s string) bool {
+	return len(s) > len(DevicePrefix) && strings.HasPrefix(s, DevicePrefix)
+}
+
+// extendedUNC is the remainder of ExtendedPrefix that precedes a UNC host in
+// an extended-length path, in place of the leading `\\` of the UNC path.
+const extendedUNC = `UNC\`
+
+// splitExtended returns the length of ExtendedPrefix, including extendedUNC if
+// present, preceding an ordinary drive letter or UNC volume of the given
+// Windows path s, and true if the remainder is a UNC path. The returned length
+// is zero if s is not an extended-length path (e.g., a volume GUID path).
+func splitExtended(s string) (int, bool) {
+	n := len(ExtendedPrefix)
+	if len(s) <= n || s[:n] != ExtendedPrefix {
+		return 0, false
+	}
+	if r := s[n:]; len(r) > len(extendedUNC) && strings.EqualFold(r[:len(extendedUNC)], extendedUNC) {
+		return n + len(extendedUNC), true
+	}
+	if len(s) > n+1 && s[n+1] == ':' && isalpha(s[n]) {
+		return n, false
+	}
+	return 0, false
+}
+
+// TrimExtended returns the given Windows path s without ExtendedPrefix, such
+// that `\\?\C:\path` is `C:\path` and `\\?\UNC\host\share` is `\\host\share`.
+// The returned bool is false, and s is returned unchanged, if s is not an
+// extended-length path.
+func TrimExtended(s string) (string, bool) {
+	n, unc := splitExtended(s)
+	switch {
+	case n == 0:
+		return s, false
+	case unc:
+		return `\\` + s[n:], true
+	}
+	return s[n:], true
+}
+
+// extendLength returns the given absolute Windows path s with ExtendedPrefix
+// if its length exceeds MaxPath, and s unchanged otherwise. Its length is that
+// of its UTF-16 encoding, as Windows measures it, not its length in bytes.
+func extendLength(s string) string {
+	if len(utf16.Encode([]rune(s))) < MaxPath {
+		return s
+	}
+	if n, _ := splitExtended(s); n > 0 {
+		return s
+	}
+	switch v, _ := Windows.SplitVolume(s); {
+	case len(v) == 2:
+		return ExtendedPrefix + s
+	case len(v) > 2 && strings.HasPrefix(v, `\\`):
+		return ExtendedPrefix + extendedUNC + s[2:]
+	}
+	return s
+}
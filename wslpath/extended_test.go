@@ -0,0 +1,113 @@
+package wslpath
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTrimExtended(t *testing.T) {
+	for _, c := range []struct {
+		in, want string
+		ok       bool
+	}{
+		{`\\?\C:\very\long\path`, `C:\very\long\path`, true},
+		{`\\?\c:`, `c:`, true},
+		{`\\?\UNC\host\share\path`, `\\host\share\path`, true},
+		{`\\?\unc\host\share`, `\\host\share`, true},
+		// volume GUID paths are not drive or UNC paths
+		{`\\?\Volume{01234567-89ab-cdef-0123-456789abcdef}\x`, `\\?\Volume{01234567-89ab-cdef-0123-456789abcdef}\x`, false},
+		{`\\?\`, `\\?\`, false},
+		{`C:\x`, `C:\x`, false},
+	} {
+		if got, ok := TrimExtended(c.in); got != c.want || ok != c.ok {
+			t.Errorf("TrimExtended(%q) = %q, %t; want %q, %t", c.in, got, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestExtendedFormat(t *testing.T) {
+	isolate(t)
+	r := newResolver()
+	r.MapDrive('C', "/mnt/c")
+	r.MapUNC(`\\host\share`, "/mnt/share")
+	for _, c := range []struct{ in, want string }{
+		{`\\?\C:\very\long\path`, "/mnt/c/very/long/path"},
+		{`\\?\UNC\host\share\path`, "/mnt/share/path"},
+		{`\\?\C:\`, "/mnt/c"},
+	} {
+		if got, _, err := r.Format(Windows, Unix, c.in, true, 0); err != nil || got != c.want {
+			t.Errorf("Format(%q) = %q, %v; want %q", c.in, got, err, c.want)
+		}
+	}
+	for _, c := range []struct{ in, vol, path string }{
+		{`\\?\C:\x`, `\\?\C:`, `\x`},
+		{`\\?\UNC\host\share\x`, `\\?\UNC\host\share`, `\x`},
+	} {
+		if v, p := Windows.SplitVolume(c.in); v != c.vol || p != c.path {
+			t.Errorf("SplitVolume(%q) = %q, %q; want %q, %q", c.in, v, p, c.vol, c.path)
+		}
+	}
+}
+
+func TestExtendedLength(t *testing.T) {
+	isolate(t)
+	r := newResolver()
+	r.MapDrive('C', "/mnt/c")
+	r.MapUNC(`\\host\share`, "/mnt/share")
+	// only paths that do not fit in MaxPath, with a terminating NUL, are
+	// prefixed.
+	short := strings.Repeat("a", MaxPath-len(`C:\`)-1)
+	long := strings.Repeat("a", MaxPath-len(`C:\`))
+	wide := strings.Repeat("\u00e9", 140)
+	for _, c := range []struct {
+		extended bool
+		in, want string
+	}{
+		{true, "/mnt/c/" + short, `C:\` + short},
+		{true, "/mnt/c/" + long, `\\?\C:\` + long},
+		{false, "/mnt/c/" + long, `C:\` + long},
+		{true, "/mnt/share/" + long, `\\?\UNC\host\share\` + long},
+		// the length is measured in UTF-16 code units, not bytes
+		{true, "/mnt/c/" + wide, `C:\` + wide},
+		{true, "/mnt/c/" + wide + long[140:], `\\?\C:\` + wide + long[140:]},
+	} {
+		r.ExtendedLength = c.extended
+		if got, _, err := r.Format(Unix, Windows, c.in, true, 0); err != nil || got != c.want {
+			t.Errorf("ExtendedLength=%t: Format(%d bytes) = %q, %v; want %q", c.extended, len(c.in), got, err, c.want)
+		}
+	}
+}
+
+func TestDevicePath(t *testing.T) {
+	for _, c := range []struct {
+		in   string
+		want bool
+	}{
+		{`\\.\PhysicalDrive0`, true},
+		{`\\.\COM3`, true},
+		{`\\.\C:\x`, true},
+		{`\\.\`, false},
+		{`\\?\C:\x`, false},
+		{`\\host\share`, false},
+		{`.\COM3`, false},
+	} {
+		if got := IsDevice(c.in); got != c.want {
+			t.Errorf("IsDevice(%q) = %t; want %t", c.in, got, c.want)
+		}
+	}
+	isolate(t)
+	r := newResolver()
+	r.MapDrive('C', "/mnt/c")
+	for _, s := range []string{`\\.\PhysicalDrive0`, `\\.\COM3`, `\\.\pipe\name`} {
+		if f := Identify(s); f != Windows {
+			t.Errorf("Identify(%q) = %s; want %s", s, f, Windows)
+		}
+		// device paths are taken verbatim
+		if got := Windows.Clean(s + `\..\x`); got != s+`\..\x` {
+			t.Errorf("Clean(%q) = %q; want it unchanged", s+`\..\x`, got)
+		}
+		if got, _, err := r.Format(Windows, Unix, s, false, 0); CodeOf(err) != ErrDevicePath {
+			t.Errorf("Format(%q) = %q, %v; want %s", s, got, err, ErrDevicePath)
+		}
+	}
+}
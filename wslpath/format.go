@@ -0,0 +1,1033 @@
+// Package wslpath translates file paths between Windows and the Windows
+// Subsystem for Linux (WSL), using the mount points of Windows volumes defined
+// in the environment (e.g., C_VOLUME_PATH) or configured on a Resolver.
+//
+// Identify detects the Format of a file path, and Format.Format translates a
+// file path to another Format using DefaultResolver.
+//
+// A Resolver may instead be configured with volume mappings directly, which
+// take precedence over the environment and require no live WSL environment:
+//
+//	r := &wslpath.Resolver{}
+//	r.MapDrive('C', "/mnt/c")
+//	r.MapUNC(`\\host\share`, "/mnt/share")
+//
+//	// Windows to Unix: "/mnt/c/Users/me"
+//	p, _, err := r.Format(wslpath.Windows, wslpath.Unix, `C:\Users\me`, false, 0)
+//
+//	// Unix to Windows: `\\host\share\doc`
+//	p, _, err = r.Format(wslpath.Unix, wslpath.Windows, "/mnt/share/doc", false, 0)
+//
+// The Format of a path, and its cleaned form, are determined without any
+// volume mappings:
+//
+//	wslpath.Identify(`C:\x`)             // wslpath.Windows
+//	wslpath.Identify("/x")               // wslpath.Unix
+//	wslpath.Windows.Clean(`C:\a\..\b\`)  // `C:\b`
+//	wslpath.Unix.Clean("/a/./b/../c")    // "/a/c"
+package wslpath
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"unicode/utf8"
+)
+
+// Format represents an enumeration of possible file path formats.
+type Format int
+
+const (
+	// Windows file paths may contain a volume prefix as either a drive
+	// letter "C:" or a UNC path "\\host\share". Following a drive letter,
+	// an absolute or relative path may be specified, where the former is
+	// expressed with a leading "\", which indicates the path is anchored to
+	// the root of the volume. Relative paths are anchored to the current
+	// directory. UNC paths must always be fully-qualified, absolute file
+	// paths. For more info, see:
+	//
+	//   https://docs.microsoft.com/en-us/dotnet/standard/io/file-path-formats
+	//
+	// Both types of volumes, drive letter or UNC path, are mounted within
+	// the WSL user space as any ordinary mount point, and therefore can be
+	// converted to traditional Unix file paths. Both types of volumes are
+	// mounted in WSL using the "drvfs" file system driver.
+	//
+	// The Windows directory separator is always "\".
+	Windows Format = iota
+
+	// Unix file paths do not distinguish the volume from which a given
+	// file system is provided. Instead, volumes are mounted at any regular
+	// directory path within a single/common file system. This file system
+	// uses "/" as its root path. Any file path containing a leading "/"
+	// is interpreted as an absolute file path; all others are considered
+	// relative file paths.
+	//
+	// Currently, the WSL file system is stored on top of the Windows host
+	// (NTFS) file system. However, accessing files stored in the WSL file
+	// system from the Windows host context is not supported and may lead to
+	// file system corruption. Read operations are considered relatively
+	// safe, but write operations are not. The user must ensure any Windows
+	// application used to read files from the WSL container does not also
+	// write to that file system (e.g., cache or temporary files).
+	//
+	// The Unix directory separator is always "/".
+	Unix
+
+	// Any file paths are simple file names that do not contain a directory
+	// separator, which are valid on both Windows and Unix file systems.
+	Any
+)
+
+const (
+	// NixPathEnvSuffix defines the suffix of WSL environment variable
+	// identifiers for variables holding paths to Windows volumes mounted
+	// within WSL user space. The prefix of these identifiers is constructed
+	// dynamically based on the volume specified by a Windows absolute path.
+	NixPathEnvSuffix = "_VOLUME_PATH" // e.g., C_VOLUME_PATH="/mnt/c"
+	// WslRootfsEnvVar holds the Windows-formatted absolute path to the
+	// active WSL distribution's rootfs directory (content is read-only).
+	// For files that exist solely in the WSL virtual rootfs (they are not
+	// stored physically on any host file system), the following environment
+	// variable is used as the path prefix.
+	WslRootfsEnvVar = "WSL_ROOTFS_PATH"
+	UncPathEnvVar   = "WSL_UNC_PATH"
+	// UncFileEnvVar names a file of UNC volume mappings, one per line, read
+	// by the wslpath command in addition to UncPathEnvVar. See LoadUNCFile.
+	UncFileEnvVar = "WSL_UNC_FILE"
+)
+
+// Identify automatically detects and returns the file path Format of a given
+// string. If the path begins with a Windows drive letter prefix, then it is
+// always Windows, regardless of the directory separators that follow (e.g.,
+// "C:/" refers to the root of the "C:" volume). Otherwise, the Format is
+// determined by scanning for the first directory path separator.
+// If no separator exists, such as a simple file name, and no drive letter
+// prefix exists (a volume-anchored relative path, e.g., "D:foo.dat" refers to
+// "foo.dat" in the current working path on the "D:" volume, regardless of the
+// current volume), then the path is valid for both systems, and the special
+// Format value Any is returned.
+func Identify(s string) Format {
+	// device paths (e.g., `\\.\COM3`) exist only in Windows
+	if IsDevice(s) {
+		return Windows
+	}
+	// Check if it contains a drive letter prefix
+	if len(s) > 1 {
+		if d := s[0]; (s[1] == ':') &&
+			(('a' <= d && d <= 'z') || ('A' <= d && d <= 'Z')) {
+			return Windows
+		}
+	}
+	for _, c := range s {
+		if c == '\\' {
+			return Windows
+		}
+		if c == '/' {
+			return Unix
+		}
+	}
+	return Any
+}
+
+// BareDrive returns the given string s with a leading bare drive letter, one
+// that is not followed by a colon, rewritten as a Windows drive letter volume.
+// The drive letter must be either the entire string (e.g., "C") or followed by
+// a directory separator (e.g., "C/" or "C\foo"), and the returned path is then
+// anchored to the root of that volume (e.g., "C:\" or "C:\foo"). If s does not
+// begin with a bare drive letter, s is returned unchanged and the returned bool
+// is false.
+func BareDrive(s string) (string, bool) {
+	if len(s) == 0 || !isalpha(s[0]) {
+		return s, false
+	}
+	if len(s) > 1 && s[1] != '/' && s[1] != '\\' {
+		return s, false
+	}
+	rest := strings.ReplaceAll(strings.TrimLeft(s[1:], `/\`), "/", `\`)
+	return s[:1] + `:\` + rest, true
+}
+
+// SplitVolume separates the given file path in Windows Format into volume and
+// path components. Volume may be either a drive letter or a UNC host+share
+// expression. If a volume expression does not exist, or Format is not Windows,
+// then the returned volume is the empty string and path is unchanged.
+//
+// A UNC root with a trailing separator (e.g., `\\host\share\`, as formatted by
+// Explorer) yields the volume `\\host\share` and path `\`.
+//
+// The UNC host may be a name or IPv4 address (e.g., `\\192.168.1.10\share`),
+// whose dots are ordinary host characters, or a bracketed IPv6 literal (e.g.,
+// `\\[fe80::1]\share`), whose colons and other characters are taken verbatim
+// as part of the host. The transcribed IPv6 form (e.g.,
+// `\\fe80--1.ipv6-literal.net\share`) is an ordinary host name.
+//
+// An extended-length path prefix (ExtendedPrefix) is part of the volume that
+// follows it, such that `\\?\C:\x` yields the volume `\\?\C:` and
+// `\\?\UNC\host\share\x` yields the volume `\\?\UNC\host\share`.
+func (f Format) SplitVolume(s string) (volume, path string) {
+
+	// Windows is the only Format that uses volume prefixes
+	if Windows != f {
+		return "", s
+	}
+	// test if we have a drive letter X: prefix
+	if len(s) < 2 {
+		return "", s
+	}
+	if d := s[0]; (s[1] == ':') &&
+		(('a' <= d && d <= 'z') || ('A' <= d && d <= 'Z')) {
+		return s[:2], s[2:]
+	}
+
+	// test if we have an extended-length \\?\ prefix of an ordinary drive
+	// letter or UNC volume, which is part of the volume returned.
+	if n, unc := splitExtended(s); n > 0 {
+		r := s[n:]
+		if unc {
+			r = `\\` + r
+		}
+		if v, p := f.SplitVolume(r); v != "" {
+			return s[:len(s)-len(p)], p
+		}
+		return "", s
+	}
+
+	// test if we have a UNC \\host\share prefix
+	if len(s) < 5 {
+		return "", s
+	}
+	// verify we have leading slashes
+	if s[:2] == `\\` && s[2] != '\\' && s[2] != '.' {
+		n := 3
+		if s[2] == '[' {
+			// skip over the IPv6 literal, which must be followed by a
+			// separator
+			e := strings.IndexByte(s, ']')
+			if e < 0 || e+1 >= len(s) || s[e+1] != '\\' {
+				return "", s
+			}
+			n = e + 1
+		}
+		for ; n < len(s)-1; n++ {
+			// walk over server name until we reach volume separator
+			if s[n] == '\\' {
+				n++
+				if s[n] != '\\' {
+					// we are in volume name, which may contain "." and
+					// "$" (e.g., "share.v2$"). only a "." immediately
+					// following the leading `\\` indicates a device path.
+					//   take remaining chars up to EOS or next separator
+					for ; n < len(s); n++ {
+						if s[n] == '\\' {
+							break
+						}
+					}
+					return s[:n], s[n:]
+				}
+				break
+
+			}
+		}
+	}
+	return "", s
+}
+
+// IsAbs reports whether the given file path is absolute in the receiver Format
+// f. Unix paths are absolute if they begin with "/". Windows paths are absolute
+// if they begin with a UNC host+share volume, or with a drive letter followed
+// by a directory separator. The drive-relative form "C:foo" and the rooted form
+// "\foo" are both anchored to a current directory and are not absolute, even
+// with ExtendedPrefix (e.g., `\\?\C:foo`).
+func (f Format) IsAbs(s string) bool {
+	switch f {
+	case Windows:
+		// an extended-length prefix does not qualify a drive-relative path
+		// (e.g., `\\?\C:foo`), so the path it prefixes is inspected alone.
+		e, _ := TrimExtended(f.normalizeSep(s))
+		v, p := f.SplitVolume(e)
+		if len(v) == 0 {
+			return false
+		}
+		if v[1] == ':' {
+			// drive letter must be followed by a separator
+			return len(p) > 0 && f.issep(rune(p[0]))
+		}
+		// UNC paths are always fully-qualified
+		return true
+	case Unix:
+		return len(s) > 0 && f.issep(rune(s[0]))
+	}
+	return false
+}
+
+// Elements splits the given file path into individual path components based
+// on the receiver Format f's directory separator. Unlike strings.Split, empty
+// components are not added to the returned slice.
+//
+// The path is split bytewise, since all directory separators are ASCII, so
+// that path components which are not valid UTF-8 (legal in Unix file names)
+// are preserved exactly.
+func (f Format) Elements(s string) []string {
+	s = f.normalizeSep(s)
+	e := []string{}
+	n := 0
+	for i := 0; i < len(s); i++ {
+		if f.issep(rune(s[i])) {
+			e = append(e, s[n:i])
+			n = i + 1
+		}
+	}
+	if n < len(s) {
+		e = append(e, s[n:])
+	}
+	return e
+}
+
+// Base returns the last element of the given file path, the Format-aware
+// analog of path/filepath.Base. Trailing directory separators and any volume
+// prefix are removed before extracting the last element. If the path is empty,
+// Base returns ".". If the path consists entirely of a volume and separators
+// (i.e., a root directory), Base returns a single separator.
+func (f Format) Base(s string) string {
+	if s == "" {
+		return "."
+	}
+	_, s = f.SplitVolume(s)
+	// strip trailing separators
+	for len(s) > 0 && f.issep(rune(s[len(s)-1])) {
+		s = s[:len(s)-1]
+	}
+	// find the last element
+	i := len(s) - 1
+	for i >= 0 && !f.issep(rune(s[i])) {
+		i--
+	}
+	s = s[i+1:]
+	if s == "" {
+		return string(f.sep())
+	}
+	return s
+}
+
+// Dir returns all but the last element of the given file path, the
+// Format-aware analog of path/filepath.Dir. The returned path is cleaned and
+// retains any volume prefix. The Dir of a root directory is the root directory
+// itself (e.g., the Dir of `C:\` is `C:\`), and the Dir of a bare file name is
+// ".".
+func (f Format) Dir(s string) string {
+	vol, p := f.SplitVolume(s)
+	i := len(p) - 1
+	for i >= 0 && !f.issep(rune(p[i])) {
+		i--
+	}
+	return f.Clean(vol + p[:i+1])
+}
+
+// Ancestors returns the given file path, cleaned, followed by each of its parent
+// directories in order, up to and including its root directory (e.g., `C:\`).
+// The ancestors of a relative path end with its first element.
+func (f Format) Ancestors(s string) []string {
+	s = f.Clean(s)
+	a := []string{s}
+	for d := f.Dir(s); d != s && d != "."; d = f.Dir(s) {
+		a = append(a, d)
+		s = d
+	}
+	return a
+}
+
+// Depth returns the number of path elements in the given file path below the
+// root of its volume, after cleaning. The depth of a root directory (e.g.,
+// `C:\` or "/") is 0. For Unix file paths, the volume root is the file system
+// root "/", so the depth below a Windows drive's mount point is obtained from
+// the equivalent Windows file path.
+func (f Format) Depth(s string) int {
+	_, p := f.SplitVolume(f.Clean(s))
+	n := 0
+	for _, e := range f.Elements(p) {
+		if e != "" && e != "." {
+			n++
+		}
+	}
+	return n
+}
+
+// Clean is the same as standard Go's path/filepath.Clean, except that it can
+// handle arbitrary directory separators. In particular, it applies the
+// following rules iteratively until no further processing can be done:
+//
+//     1. Replace multiple directory separators with a single one.
+//     2. Eliminate each "." path name element (the current directory).
+//     3. Eliminate each inner-".." path name element (the parent directory)
+//        along with the non-".." element that precedes it.
+//     4. Eliminate ".." elements that begin a rooted path: that is, replace
+//        "/.." by "/" at the beginning of a path, assuming directory separator
+//        is '/'.
+//
+// The volume prefix, if provided as either a drive letter or UNC host+share, is
+// preserved on both absolute and relative file paths.
+//
+// The returned path ends in a slash only if it represents a root directory,
+// such as "/" on Unix or `C:\` on Windows. A bare UNC volume `\\host\share`
+// always refers to its root directory `\\host\share\`.
+//
+// Windows paths beginning with a drive letter or UNC prefix may mix "/" and
+// "\" as directory separators (e.g., "C:/Users/me\Documents"), and the
+// returned path uses only "\".
+//
+// Windows device paths (e.g., `\\.\COM3`) are returned unchanged.
+//
+// If the result of this process is an empty string, "." is returned.
+func (f Format) Clean(s string) string {
+
+	// device paths are not file paths, and are taken verbatim
+	if Windows == f && IsDevice(s) {
+		return s
+	}
+
+	var vol string
+	vol, s = f.SplitVolume(f.normalizeSep(s))
+
+	if len(s) == 0 {
+		// UNC paths are always absolute, so a bare UNC volume refers to
+		// its root directory.
+		if len(vol) > 2 && f.issep(rune(vol[0])) {
+			return vol + string(f.sep())
+		}
+		return vol + "."
+	}
+
+	// replace multiple separator elements with a single one
+	u := string(f.sep())
+	d := u + u
+	for n := 0; n != len(s); {
+		n = len(s)
+		s = strings.ReplaceAll(s, d, u)
+	}
+
+	e := f.Elements(s)
+
+	// remove any "." elements (current dir)
+	p := []string{}
+	for _, u := range e {
+		if u != "." {
+			p = append(p, u)
+		}
+	}
+
+	// remove any (inner) ".." elements and their predecessor (parent dir)
+	for {
+		// create buffer for current pass
+		q := []string{}
+		// keep iterating until no change was performed (done == true)
+		done, skip := true, false
+		// walk over each path element, checking if its following
+		// element is ".."
+		for i := range p {
+			if !skip {
+				// preceding element was not ".."
+				if (i+1 < len(p)) && (p[i] != "..") && (p[i+1] == "..") {
+					if (i == 0) && (p[i] == "") {
+						// keep. leading element is ".."
+						q = append(q, p[i])
+					}
+					// skip. following element is ".."
+					// need to process elements again
+					done, skip = false, true
+				} else {
+					// keep. following element is not ".."
+					q = append(q, p[i])
+				}
+			} else {
+				// skip. current element is ".."
+				skip = false
+			}
+		}
+		// replace final path elements with result of current pass
+		p = q
+		if done {
+			// no change performed in current pass. all done.
+			break
+		}
+	}
+
+	// if no elements remain, use current dir "."
+	if len(p) == 0 {
+		return vol + "."
+	} else {
+		if (len(p) == 1) && (p[0] == "") {
+			return vol + string(f.sep())
+		} else {
+			return vol + strings.Join(p, string(f.sep()))
+		}
+	}
+}
+
+// Format translates the given file path s, interpreted as a path in the
+// receiver Format f, to a file path in given Format t.
+//
+// When translating absolute paths from one file system to the other,
+// environment variables are used to determine relative paths or mount points.
+//
+// Relative Unix paths are first anchored to the current working directory. If
+// the resulting absolute path lies on a mounted Windows volume, the relative
+// path is meaningful in both contexts and is returned relative. Otherwise, the
+// absolute path into the WSL virtual rootfs is returned, unless x is true, in
+// which case an error is returned for any Unix path, relative or absolute, that
+// is not found on a mounted Windows volume.
+//
+// If the Resolver's RelativeTextual is enabled, relative Unix paths are never
+// anchored, and only their separators are translated.
+//
+// If the Resolver's Base is defined, relative paths of its Format are anchored
+// to Base instead of the current working directory, and are always returned
+// absolute.
+//
+// If the Resolver's Absolute is enabled, relative Unix paths are always
+// returned absolute, and Windows paths without a volume are anchored to the
+// current working directory, as with InferVolume.
+//
+// The given path is cleaned before any volume mapping is performed, so that
+// "." and ".." elements are resolved against the real path prefix. A ".."
+// element is never permitted to escape the root of a Windows volume, so
+// "C:\dir\..\.." refers to "C:\". However, a mount point is an ordinary
+// directory in WSL, so ".." elements may escape it: "/mnt/c/dir/.." refers to
+// the mount point itself ("C:\"), but "/mnt/c/.." refers to "/mnt", which is
+// found only in the WSL virtual rootfs. The Unix root "/" itself refers to
+// the rootfs path exactly (e.g., `\\wsl$\Ubuntu`), without a trailing
+// separator.
+//
+// Windows device paths (e.g., `\\.\COM3`, see IsDevice) have no Unix
+// equivalent, and are reported as an error with ErrorCode ErrDevicePath.
+//
+// Path elements are never otherwise altered. In particular, trailing dots and
+// spaces (e.g., `C:\foo.\bar `), which Windows APIs silently strip, are
+// preserved when translating to Unix, where they are significant.
+//
+// The bool return paramter is true if and only if the returned path is
+// a Windows formatted path into the WSL virtual rootfs (i.e., read-only).
+//
+// Format uses the configuration of DefaultResolver, and is safe for concurrent
+// use by multiple goroutines.
+func (f Format) Format(t Format, s string, x bool, z uint) (string, bool, error) {
+	return DefaultResolver.Format(f, t, s, x, z)
+}
+
+// Format translates the given file path s, interpreted as a path in Format f,
+// to a file path in given Format t, using the receiver Resolver r to associate
+// Windows volumes with WSL mount points. See Format.Format for details.
+//
+// If the Resolver's TrailingSep is enabled, a trailing separator of s is
+// preserved in the returned path, unless s is a root directory. If the
+// Resolver's ExtendedLength is enabled, Windows paths returned whose length
+// exceeds MaxPath are given ExtendedPrefix.
+func (r *Resolver) Format(f, t Format, s string, x bool, z uint) (string, bool, error) {
+	p, wsl, err := r.format(f, t, s, x, z)
+	if err == nil && r.TrailingSep {
+		p = keepTrailingSep(f, t, s, p)
+	}
+	if err == nil && Windows == t && len(r.DriveRemaps) > 0 {
+		p = r.remapDrive(p)
+	}
+	if err == nil && Windows == t && r.ExtendedLength {
+		p = extendLength(p)
+	}
+	return p, wsl, err
+}
+
+// keepTrailingSep returns the given path p in Format t with a trailing
+// separator appended if the path s in Format f, from which p was translated,
+// ends in a separator and is not a root directory. The separator is appended
+// only after p is cleaned, so ".." elements in s are collapsed beforehand.
+func keepTrailingSep(f, t Format, s, p string) string {
+	s = f.normalizeSep(s)
+	if len(s) == 0 || !f.issep(rune(s[len(s)-1])) ||
+		len(p) == 0 || t.issep(rune(p[len(p)-1])) {
+		return p
+	}
+	if c := f.Clean(s); len(c) > 0 && f.issep(rune(c[len(c)-1])) {
+		return p // root directories (e.g., "/" or `C:\`)
+	}
+	return p + string(t.sep())
+}
+
+// format implements Resolver.Format, without regard to TrailingSep.
+func (r *Resolver) format(f, t Format, s string, x bool, z uint) (string, bool, error) {
+
+	if Windows == f && Windows != t && IsDevice(s) {
+		return "", false, errorf(ErrDevicePath, "Windows device path has no Unix equivalent: %s", s)
+	}
+
+	if err := r.checkDotDot(f, s); err != nil {
+		return "", false, err
+	}
+	if Windows == f {
+		v, err := r.resolveVolumeGUID(s)
+		if err != nil {
+			return "", false, err
+		}
+		if v != s {
+			r.trace("volume", s, f, "", v)
+			s = v
+		}
+		if e, ok := TrimExtended(s); ok {
+			r.trace("extended", s, f, "", e)
+			s = e
+		}
+	}
+	if Windows == f && r.Expand {
+		e, err := r.expand(s)
+		if err != nil {
+			return "", false, err
+		}
+		r.trace("expand", s, f, "", e)
+		s = e
+	}
+	if Windows == f && r.ResolveSubst {
+		if e := r.resolveSubst(s); e != s {
+			r.trace("subst", s, f, "", e)
+			s = e
+		}
+	}
+	if b, ok := r.base(f); ok && Windows == f && Unix == t {
+		if e, ok := r.anchorVolume(s, b); ok {
+			r.trace("base", s, f, "", e)
+			s = e
+		}
+	}
+	if Windows == f && Unix == t && r.Volume != "" {
+		if e, ok := r.anchorVolume(s, r.Volume); ok {
+			r.trace("volume-anchor", s, f, "", e)
+			s = e
+		}
+	}
+	if Windows == f && Unix == t && (r.InferVolume || r.Absolute) {
+		if e, ok := r.inferVolume(s); ok {
+			r.trace("infer-volume", s, f, "", e)
+			s = e
+		}
+	}
+	if Windows == f {
+		// a drive designator directly following another (e.g., "C:D:\foo")
+		// is a copy-paste error with no meaningful interpretation.
+		if v, p := f.SplitVolume(s); len(v) == 2 && len(p) >= 2 && p[1] == ':' && isalpha(p[0]) {
+			return "", false, errorf(ErrInvalidPath, "malformed path: multiple volume designators: %s", s)
+		}
+		// an element of a relative path that looks like a drive (e.g., "c:"
+		// in `.\c:`) names a file, not a volume, and must not become one
+		// once cleaning removes the elements preceding it.
+		if v, _ := f.SplitVolume(s); v == "" {
+			if cv, _ := f.SplitVolume(f.Clean(s)); cv != "" {
+				return "", false, errorf(ErrInvalidPath, "malformed path: relative path element is a volume designator: %s", s)
+			}
+		}
+	}
+	c := f.Clean(s)
+	r.trace("clean", s, f, "", c)
+	s = c
+	// wsl is true if s is a path into the WSL rootfs, and conv is true once
+	// the separators of s have been translated to Format t.
+	wsl, conv := false, false
+
+	if z > 1 {
+		return "", false, errorf(ErrInvalidPath, "invalid path: %s", s)
+	}
+
+	// Windows file names are UTF-16, which cannot represent arbitrary bytes.
+	// this precedes every translation to Windows, including prefix maps.
+	if Windows == t && !utf8.ValidString(s) {
+		return "", false, errorf(ErrInvalidPath, "path is not valid UTF-8 and cannot be represented on Windows: %q", s)
+	}
+
+	// relative roots and prefix maps take precedence over all volume
+	// mappings
+	if p, ok := r.reanchor(f, t, s); ok {
+		r.trace("relative-root", s, f, "", p)
+		return p, false, nil
+	}
+	if p, ok := r.mapPrefix(f, t, s); ok {
+		r.trace("prefix-map", s, f, "", p)
+		return p, false, nil
+	}
+
+	switch f {
+	case Windows:
+		if Unix == t {
+			// paths into the WSL rootfs take precedence over any volume
+			// mappings, which may otherwise spuriously match the rootfs.
+			if p, ok := r.fromRootfs(s); ok {
+				r.trace("rootfs", s, f, WslRootfsEnvVar, p)
+				return p, false, nil
+			}
+			if p, d, ok := fromDistro(s); ok {
+				// only the file system of the active distribution is
+				// accessible through Unix paths.
+				if a, set := os.LookupEnv(DistroEnvVar); set && !strings.EqualFold(a, d) {
+					return "", false, errorf(ErrNoMapping, "path is in WSL distribution %q, not the active distribution %q: %s", d, a, s)
+				}
+				r.trace("distro", s, f, DistroEnvVar, p)
+				return p, false, nil
+			}
+			v, p := f.SplitVolume(s)
+			if len(v) >= 2 {
+				// absolute path
+				v0, v1 := v[0], v[1]
+				if (v1 == ':') && (('a' <= v0 && v0 <= 'z') || ('A' <= v0 && v0 <= 'Z')) {
+					// convert drive letter to environment variable
+					if dp, dk, err := r.lookupDrive(v0); err == nil {
+						// replace drive letter with value of environment variable.
+						// the drive root is the mount point itself, which Clean
+						// represents as "." following the volume (e.g., "C:."),
+						// and "C:" itself refers to the drive root.
+						if p == "." || p == "" {
+							p = string(f.sep())
+						} else if !f.issep(rune(p[0])) {
+							// drive-relative paths (e.g., "C:foo") are anchored
+							// to the current directory on that drive.
+							cwd, err := r.driveCwd(v0)
+							if err != nil {
+								return "", false, err
+							}
+							_, cp := f.SplitVolume(cwd)
+							r.trace("cwd-on", s, f, "", cwd)
+							p = f.Clean(f.join(cp, p))
+						}
+						a := dp + r.replaceSep(f, t, p)
+						r.trace("drive", s, f, dk, a)
+						s, conv = a, true
+					} else {
+						return "", false, err
+					}
+				} else if len(v) >= 5 {
+					v2 := v[2]
+					if v[:2] == `\\` && v2 != '\\' && v2 != '.' {
+						if m, rest, ok := r.lookupUNC(s); ok {
+							// the UNC root (rest is empty or a lone separator)
+							// maps to the bare mount point once cleaned.
+							a := m.path + string(t.sep()) + r.replaceSep(f, t, rest)
+							r.trace("unc", s, f, m.key, a)
+							s, conv = a, true
+						} else if mp, ok := r.uncMount(v); ok {
+							a := mp + r.replaceSep(f, t, p)
+							r.trace("unc-mount-root", s, f, "", a)
+							s, conv = a, true
+						} else if up, ok := os.LookupEnv(UncPathEnvVar); ok {
+							return "", false, errorf(ErrNoMapping, "UNC volume %q not found in environment variable: %s=%q", v, UncPathEnvVar, up)
+						} else {
+							return "", false, errorf(ErrEnvNotSet, "environment variable not set: %s", UncPathEnvVar)
+						}
+					}
+				}
+			}
+			if !conv {
+				s = r.replaceSep(f, t, s)
+			}
+			c = t.Clean(s)
+			r.trace("result", s, t, "", c)
+			s = c
+		}
+
+	case Unix:
+		if Windows == t {
+			if len(s) > 0 {
+				if s[0] == '/' {
+					// absolute file path
+					//e, err := filepath.EvalSymlinks(s)
+					//if err != nil {
+					//	return "", false, err
+					//}
+					a, err := r.abspath(f, s)
+					if err != nil {
+						return "", false, err
+					}
+					r.trace("abspath", s, f, "", a)
+					s = a
+					// in an unconfigured environment, the drive mounted
+					// under the default automount root (e.g., "/mnt/c") is
+					// found without scanning the environment.
+					if m, p, ok := r.automount(s); ok {
+						a = r.driveVolume(m.drive, m.path) + string(t.sep()) + r.replaceSep(f, t, p)
+						a = t.Clean(a)
+						r.trace("automount", s, f, m.key, a)
+						return a, false, nil
+					}
+					// the longest matching drive mount point is retained
+					// in full, so that its drive and key are reused as
+					// matched, even if mount points are nested (e.g.,
+					// "/mnt" and "/mnt/c").
+					var dm mount
+					var rest, tie string
+					mounts, _ := r.driveMounts()
+					for _, m := range mounts {
+						if p, ok := r.trimMount(s, m.path); ok && (len(m.path) > len(dm.path)) {
+							dm, rest, tie = m, p, ""
+						} else if ok && len(m.path) == len(dm.path) && m.drive != dm.drive && "" == tie {
+							tie = m.key
+						}
+					}
+					if 0 == dm.drive {
+						// no drive mount point is defined in the environment,
+						// so fall back on the drvfs mounts published by WSL.
+						for _, m := range r.tableMounts() {
+							if p, ok := r.trimMount(s, m.path); ok && (len(m.path) > len(dm.path)) {
+								dm, rest = m, p
+							}
+						}
+					}
+					if r.StrictMatch && "" != tie {
+						return "", false, errorf(ErrAmbiguous, "ambiguous volume mapping: %s and %s both match %q: %s", dm.key, tie, dm.path, s)
+					}
+					// the longest matching mount point is used. a UNC share
+					// and a drive mounted at the same point are distinguished
+					// by VolumeStyle.
+					m, urest, uok := r.reverseUNC(s)
+					if uok && (0 == dm.drive || len(m.path) > len(dm.path) ||
+						(len(m.path) == len(dm.path) && UNCStyle == r.VolumeStyle)) {
+						a = m.volume + string(t.sep()) + r.replaceSep(f, t, urest)
+						r.trace("unc", s, f, m.key, a)
+						s, conv = a, true
+					} else if 0 != dm.drive {
+						// append a separator so that the mount point itself
+						// maps to the drive root (e.g., "/mnt/c" to "C:\").
+						a = r.driveVolume(dm.drive, dm.path) + string(t.sep()) + r.replaceSep(f, t, rest)
+						r.trace("drive", s, f, dm.key, a)
+						s, conv = a, true
+					} else {
+						// the Unix root is never a mount point of a Windows
+						// volume, only the root of the WSL rootfs.
+						if x && s == string(f.sep()) {
+							return "", false, errorf(ErrNoMapping, "Unix root has no Windows volume; rootfs fallback disabled: %s", s)
+						}
+						if x {
+							return "", false, errorf(ErrNoMapping, "no volume mapping; rootfs fallback disabled: %s", s)
+						}
+						if up, ok := r.rootfs(); ok {
+							a = fmt.Sprintf("%s%c%s", up, t.sep(), r.replaceSep(f, t, s))
+							r.trace("rootfs", s, f, WslRootfsEnvVar, a)
+							s, conv = a, true
+							wsl = true
+						} else if s == string(f.sep()) {
+							return "", false, errorf(ErrNoMapping, "Unix root has no Windows volume and %s is not set: %s", WslRootfsEnvVar, s)
+						} else {
+							return "", false, errorf(ErrNoMapping, "path substring not found in environment: %s", s)
+						}
+					}
+				} else if !r.RelativeTextual {
+					// relative file path
+					//a, err := filepath.Abs(s)
+					//if err != nil {
+					//	return "", false, err
+					//}
+					// if we cannot resolve the absolute path to a Windows volume, then
+					// the relative path will never make sense in a Windows context.
+					// Instead, construct an absolute path to the WSL rootfs path.
+					a, err := r.abspath(f, s)
+					if err != nil {
+						return "", false, err
+					}
+					p, w, err := r.Format(f, t, a, x, z+1)
+					if err != nil {
+						if x && ErrNoMapping == CodeOf(err) {
+							// report the relative path given, not the absolute
+							// path it was anchored to internally.
+							return "", false, errorf(ErrNoMapping, "no volume mapping for relative path %q (resolved to %s); rootfs fallback disabled", s, a)
+						}
+						return "", false, err
+					}
+					if w {
+						// The absolute path was unresolved to a Windows volume, and we
+						// instead received a path to the virtual WSL rootfs.
+						// Use the absolute WSL rootfs path instead of a relative path.
+						s, wsl, conv = p, true, true
+					} else if _, ok := r.base(f); ok || r.Absolute {
+						// a path relative to Base is not relative to the
+						// working directory of whoever uses the result.
+						s, conv = p, true
+					}
+					// s is either a physical relative path or an absolute virtual path.
+				}
+			}
+
+			if !conv {
+				s = r.replaceSep(f, t, s)
+			}
+			c = t.Clean(s)
+			if wsl {
+				// the rootfs root is the share itself (e.g., "\\wsl$\Ubuntu"),
+				// not the UNC volume root "\\wsl$\Ubuntu\" produced by Clean.
+				if v, p := Windows.SplitVolume(c); len(v) > 2 && p == string(t.sep()) {
+					c = v
+				}
+			}
+			r.trace("result", s, t, "", c)
+			s = c
+		}
+
+	case Any:
+	default:
+	}
+
+	return s, wsl, nil
+}
+
+// abspath returns the absolute path of the given file path s, resolving any
+// symbolic links along the longest prefix of s that exists. Relative paths are
+// anchored to the current working directory, so that a relative path within a
+// mounted Windows volume (e.g., the working directory is "/mnt/c/projects")
+// resolves to that volume even if its leading elements do not yet exist.
+//
+// Each element is resolved relative to the already-resolved prefix preceding
+// it, so no prefix is resolved more than once. A circular chain of symbolic
+// links is reported as an error rather than treated as a nonexistent element.
+func (f Format) abspath(s string) (string, error) {
+	if !f.IsAbs(s) {
+		if wd, err := os.Getwd(); err == nil {
+			s = wd + string(f.sep()) + s
+		}
+	}
+	var act, rel string
+	for _, p := range strings.Split(s, string(f.sep())) {
+		if act == "" && rel == "" && p == "" {
+			p = string(f.sep())
+		} else {
+			if rel != "" {
+				if rel != string(f.sep()) {
+					rel += string(f.sep())
+				}
+				rel += p
+				continue
+			}
+		}
+		t := act
+		if t != "" && t != string(f.sep()) {
+			t += string(f.sep())
+		}
+		t += p
+		es, ee := filepath.EvalSymlinks(t)
+		as, ae := filepath.Abs(es)
+		if ee == nil && ae == nil {
+			act = as
+		} else {
+			if ee != nil && isSymlinkLoop(t) {
+				return "", errorf(ErrInvalidPath, "too many levels of symbolic links: %s", t)
+			}
+			rel = p
+		}
+	}
+	if act != "" {
+		if rel != "" {
+			return f.join(act, rel), nil
+		}
+		return act, nil
+	}
+	if rel != "" {
+		return rel, nil
+	}
+	return "", nil
+}
+
+// isSymlinkLoop returns true if and only if the given path is a symbolic link
+// that cannot be resolved because it is part of a circular chain of links.
+func isSymlinkLoop(s string) bool {
+	if fi, err := os.Lstat(s); err != nil || fi.Mode()&os.ModeSymlink == 0 {
+		return false
+	}
+	_, err := os.Stat(s)
+	return errors.Is(err, syscall.ELOOP)
+}
+
+// trimPrefix returns the path s in the receiver Format f with the given path
+// prefix removed, and true if and only if prefix matches s on a path element
+// boundary. Windows paths are compared case-insensitively. Both s and prefix
+// are expected to be cleaned.
+func (f Format) trimPrefix(s, prefix string) (string, bool) {
+	return f.trimPrefixFold(s, prefix, Windows == f)
+}
+
+// trimPrefixFold is the same as trimPrefix, except that prefix is compared
+// case-insensitively if and only if the given fold is true.
+func (f Format) trimPrefixFold(s, prefix string, fold bool) (string, bool) {
+	if len(s) < len(prefix) {
+		return s, false
+	}
+	head, rest := s[:len(prefix)], s[len(prefix):]
+	if fold {
+		if !strings.EqualFold(head, prefix) {
+			return s, false
+		}
+	} else if head != prefix {
+		return s, false
+	}
+	if len(rest) == 0 || len(prefix) == 0 ||
+		f.issep(rune(prefix[len(prefix)-1])) || f.issep(rune(rest[0])) {
+		return rest, true
+	}
+	return s, false
+}
+
+// issep returns true if and only if the given rune is equal to the receiver
+// Format f's directory separator.
+func (f Format) issep(c rune) bool {
+	switch f {
+	case Windows:
+		return c == '\\'
+	case Unix:
+		return c == '/'
+	case Any:
+		return c == '/' || c == '\\'
+	}
+	return false
+}
+
+// normalizeSep returns the given path s with each "/" replaced by "\" if the
+// receiver Format f is Windows and s begins with a drive letter (e.g.,
+// "C:/Users/me\Documents") or a UNC prefix, since Windows accepts either as a
+// directory separator. Verbatim paths (`\\?\`), in which "/" is an ordinary
+// character, and all other paths are returned unchanged.
+func (f Format) normalizeSep(s string) string {
+	if Windows != f {
+		return s
+	}
+	drive := len(s) > 1 && s[1] == ':' && isalpha(s[0])
+	unc := strings.HasPrefix(s, `\\`) && !strings.HasPrefix(s, `\\?\`)
+	if !drive && !unc {
+		return s
+	}
+	return strings.ReplaceAll(s, "/", `\`)
+}
+
+// String returns the lowercase name of the receiver Format f.
+func (f Format) String() string {
+	switch f {
+	case Windows:
+		return "windows"
+	case Unix:
+		return "unix"
+	case Any:
+		return "any"
+	}
+	return "unknown"
+}
+
+// ParseFormat returns the Format whose lowercase name, as returned by String,
+// equals the given string s, ignoring case.
+func ParseFormat(s string) (Format, error) {
+	for _, f := range []Format{Windows, Unix, Any} {
+		if strings.EqualFold(s, f.String()) {
+			return f, nil
+		}
+	}
+	return Any, fmt.Errorf("unrecognized format: %q", s)
+}
+
+// sep returns the directory separator rune of the receiver Format f.
+func (f Format) sep() rune {
+	if Windows == f {
+		return '\\'
+	}
+	return '/'
+}
@@ -0,0 +1,542 @@
+package wslpath
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsAbs(t *testing.T) {
+	for _, c := range []struct {
+		f    Format
+		in   string
+		want bool
+	}{
+		{Unix, "/a", true},
+		{Unix, "/", true},
+		{Unix, "a/b", false},
+		{Unix, "", false},
+		{Windows, `C:\a`, true},
+		{Windows, `C:/a`, true},
+		{Windows, `C:\`, true},
+		{Windows, `C:a`, false},
+		{Windows, `C:`, false},
+		{Windows, `\\host\share\a`, true},
+		{Windows, `\\host\share`, true},
+		{Windows, `a\b`, false},
+		{Windows, `\a`, false},
+		{Windows, `\\?\C:\a`, true},
+		{Windows, `\\?\C:foo`, false},
+		{Windows, `\\?\UNC\host\share\a`, true},
+		{Any, "a", false},
+	} {
+		if got := c.f.IsAbs(c.in); got != c.want {
+			t.Errorf("%s.IsAbs(%q) = %t; want %t", c.f, c.in, got, c.want)
+		}
+	}
+}
+
+func TestDriveRoot(t *testing.T) {
+	isolate(t)
+	dir := t.TempDir()
+	t.Setenv("C"+NixPathEnvSuffix, dir)
+	r := newResolver()
+	for _, c := range []struct {
+		f, t     Format
+		in, want string
+	}{
+		{Windows, Unix, `C:\`, dir},
+		{Windows, Unix, `C:`, dir},
+		{Windows, Unix, `c:\.`, dir},
+		{Windows, Unix, `C:\\`, dir},
+		{Unix, Windows, dir, `C:\`},
+		{Unix, Windows, dir + "/", `C:\`},
+		{Unix, Windows, dir + "/.", `C:\`},
+	} {
+		got, _, err := r.Format(c.f, c.t, c.in, true, 0)
+		if err != nil || got != c.want {
+			t.Errorf("Format(%d, %d, %q) = %q, %v; want %q", c.f, c.t, c.in, got, err, c.want)
+		}
+	}
+}
+
+func TestDriveForwardSlash(t *testing.T) {
+	isolate(t)
+	for _, s := range []string{`C:/`, `c:/`, `C:/x/y`, `C:\x/y`} {
+		if got := Identify(s); got != Windows {
+			t.Errorf("Identify(%q) = %d; want %d", s, got, Windows)
+		}
+	}
+	if v, p := Windows.SplitVolume(`C:/`); v != "C:" || p != "/" {
+		t.Errorf("SplitVolume(%q) = %q, %q; want %q, %q", `C:/`, v, p, "C:", "/")
+	}
+	t.Setenv("C"+NixPathEnvSuffix, "/mnt/c")
+	r := newResolver()
+	for _, c := range []struct{ in, want string }{
+		{`C:/`, "/mnt/c"},
+		{`c:/`, "/mnt/c"},
+		{`C://`, "/mnt/c"},
+		{`C:/x/y`, "/mnt/c/x/y"},
+	} {
+		got, _, err := r.Format(Windows, Unix, c.in, true, 0)
+		if err != nil || got != c.want {
+			t.Errorf("Format(%q) = %q, %v; want %q", c.in, got, err, c.want)
+		}
+	}
+}
+
+func TestBareDrive(t *testing.T) {
+	for _, c := range []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{`C`, `C:\`, true},
+		{`c`, `c:\`, true},
+		{`C/`, `C:\`, true},
+		{`C\`, `C:\`, true},
+		{`C\foo`, `C:\foo`, true},
+		{`C/foo/bar`, `C:\foo\bar`, true},
+		{`C:\foo`, `C:\foo`, false},
+		{`CD`, `CD`, false},
+		{`1/foo`, `1/foo`, false},
+		{``, ``, false},
+	} {
+		if got, ok := BareDrive(c.in); got != c.want || ok != c.ok {
+			t.Errorf("BareDrive(%q) = %q, %t; want %q, %t", c.in, got, ok, c.want, c.ok)
+		}
+	}
+	// without BareDrive, a bare letter remains a file name
+	if got := Identify("C"); got != Any {
+		t.Errorf("Identify(%q) = %s; want %s", "C", got, Any)
+	}
+}
+
+func TestDotBoundary(t *testing.T) {
+	isolate(t)
+	t.Setenv(WslRootfsEnvVar, `\\wsl$\Ubuntu`)
+	t.Setenv("C"+NixPathEnvSuffix, "/mnt/c")
+	r := newResolver()
+	for _, c := range []struct {
+		f, t     Format
+		in, want string
+		wsl      bool
+	}{
+		{Unix, Windows, "/mnt/c/dir/..", `C:\`, false},
+		{Unix, Windows, "/mnt/c/.", `C:\`, false},
+		{Unix, Windows, "/mnt/c/dir/.", `C:\dir`, false},
+		{Unix, Windows, "/mnt/c/a/b/../..", `C:\`, false},
+		// ".." may escape a mount point into the rootfs
+		{Unix, Windows, "/mnt/c/..", `\\wsl$\Ubuntu\mnt`, true},
+		{Unix, Windows, "/mnt/c/../..", `\\wsl$\Ubuntu`, true},
+		// but never the root of a Windows volume
+		{Windows, Unix, `C:\dir\..`, "/mnt/c", false},
+		{Windows, Unix, `C:\dir\..\..`, "/mnt/c", false},
+		{Windows, Unix, `C:\.`, "/mnt/c", false},
+	} {
+		got, wsl, err := r.Format(c.f, c.t, c.in, false, 0)
+		if err != nil || got != c.want || wsl != c.wsl {
+			t.Errorf("Format(%q) = %q, %t, %v; want %q, %t", c.in, got, wsl, err, c.want, c.wsl)
+		}
+	}
+	if got, _, err := r.Format(Unix, Windows, "/mnt/c/..", true, 0); err == nil {
+		t.Errorf("Format(%q, x) = %q; want error", "/mnt/c/..", got)
+	}
+}
+
+func TestInvalidUTF8(t *testing.T) {
+	isolate(t)
+	const name = "a\xffb"
+
+	// Unix file names are byte strings, which survive lexical processing
+	if got := Unix.Clean("/x/./" + name + "/"); got != "/x/"+name {
+		t.Errorf("Unix.Clean = %q; want %q", got, "/x/"+name)
+	}
+	if got := Unix.Elements("/x/" + name); len(got) == 0 || got[len(got)-1] != name {
+		t.Errorf("Unix.Elements = %q; want last element %q", got, name)
+	}
+	t.Setenv("C"+NixPathEnvSuffix, "/mnt/c")
+	r := newResolver()
+	if got, _, err := r.Format(Windows, Unix, `C:\`+name, false, 0); err != nil || got != "/mnt/c/"+name {
+		t.Errorf("Format(Windows, Unix) = %q, %v; want %q", got, err, "/mnt/c/"+name)
+	}
+
+	// but cannot be represented on Windows, whichever mapping applies
+	for _, c := range []struct {
+		name string
+		r    *Resolver
+	}{
+		{"drive", r},
+		{"prefix-map", &Resolver{MountTable: r.MountTable, Logical: true,
+			PrefixMaps: []PrefixMap{{From: "/mnt/c", To: `X:\`}}}},
+		{"relative-root", &Resolver{MountTable: r.MountTable, Logical: true,
+			RelativeRoots: []PrefixMap{{From: "/mnt/c", To: `X:\`}}}},
+	} {
+		got, _, err := c.r.Format(Unix, Windows, "/mnt/c/"+name, false, 0)
+		if CodeOf(err) != ErrInvalidPath {
+			t.Errorf("%s: Format(Unix, Windows) = %q, %v; want %s", c.name, got, err, ErrInvalidPath)
+		}
+	}
+	unsetenv(t, "C"+NixPathEnvSuffix)
+	if got, _, err := r.Format(Unix, Windows, "/mnt/c/"+name, false, 0); CodeOf(err) != ErrInvalidPath {
+		t.Errorf("automount: Format(Unix, Windows) = %q, %v; want %s", got, err, ErrInvalidPath)
+	}
+}
+
+func TestIdentify(t *testing.T) {
+	for _, c := range []struct {
+		in   string
+		want Format
+	}{
+		{`C:\x`, Windows},
+		{`C:foo`, Windows},
+		{`C:/x`, Windows},
+		{`C:`, Windows},
+		{`\\host\share`, Windows},
+		{`a\b`, Windows},
+		{"/a", Unix},
+		{"./a", Unix},
+		{"a/b", Unix},
+		{"file", Any},
+		{"", Any},
+	} {
+		if got := Identify(c.in); got != c.want {
+			t.Errorf("Identify(%q) = %s; want %s", c.in, got, c.want)
+		}
+	}
+}
+
+func TestBaseDir(t *testing.T) {
+	for _, c := range []struct {
+		f         Format
+		in        string
+		base, dir string
+	}{
+		{Windows, `C:\a\b.txt`, "b.txt", `C:\a`},
+		{Windows, `C:\a\b\`, "b", `C:\a\b`},
+		{Windows, `C:\a`, "a", `C:\`},
+		{Windows, `C:\`, `\`, `C:\`},
+		{Windows, `C:a`, "a", "C:."},
+		{Windows, `\\host\share\a\b`, "b", `\\host\share\a`},
+		{Windows, `\\host\share\a`, "a", `\\host\share\`},
+		{Windows, `\\host\share`, `\`, `\\host\share\`},
+		{Windows, `a\b`, "b", "a"},
+		{Windows, "file.txt", "file.txt", "."},
+		{Windows, "", ".", "."},
+		{Unix, "/a/b.txt", "b.txt", "/a"},
+		{Unix, "/a/b/", "b", "/a/b"},
+		{Unix, "/a", "a", "/"},
+		{Unix, "/", "/", "/"},
+		{Unix, "file.txt", "file.txt", "."},
+		{Unix, "", ".", "."},
+	} {
+		if got := c.f.Base(c.in); got != c.base {
+			t.Errorf("%s.Base(%q) = %q; want %q", c.f, c.in, got, c.base)
+		}
+		if got := c.f.Dir(c.in); got != c.dir {
+			t.Errorf("%s.Dir(%q) = %q; want %q", c.f, c.in, got, c.dir)
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, f := range []Format{Windows, Unix, Any} {
+		for _, s := range []string{f.String(), strings.ToUpper(f.String())} {
+			if got, err := ParseFormat(s); err != nil || got != f {
+				t.Errorf("ParseFormat(%q) = %s, %v; want %s", s, got, err, f)
+			}
+		}
+	}
+	if got, err := ParseFormat("dos"); err == nil {
+		t.Errorf("ParseFormat(%q) = %s; want error", "dos", got)
+	}
+}
+
+func TestSymlinkLoop(t *testing.T) {
+	isolate(t)
+	dir := t.TempDir()
+	for _, l := range []struct{ name, target string }{
+		{"a", "b"},
+		{"b", "a"},
+		{"self", "self"},
+		{"ok", "."},
+		{"dangling", "missing"},
+	} {
+		if err := os.Symlink(l.target, filepath.Join(dir, l.name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("C"+NixPathEnvSuffix, dir)
+	r := &Resolver{MountTable: os.DevNull}
+	for _, c := range []struct{ in, want string }{
+		{dir + "/a", ""},
+		{dir + "/b/x", ""},
+		{dir + "/self", ""},
+		{dir + "/ok/ok/ok/x", `C:\x`},
+		{dir + "/dangling/x", `C:\dangling\x`},
+	} {
+		done := make(chan struct{})
+		var got string
+		var err error
+		go func() {
+			got, _, err = r.Format(Unix, Windows, c.in, true, 0)
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Format(%q) did not return", c.in)
+		}
+		if c.want == "" {
+			if CodeOf(err) != ErrInvalidPath {
+				t.Errorf("Format(%q) = %q, %v; want %s", c.in, got, err, ErrInvalidPath)
+			}
+		} else if err != nil || got != c.want {
+			t.Errorf("Format(%q) = %q, %v; want %q", c.in, got, err, c.want)
+		}
+	}
+}
+
+func TestSplitVolumeIPv6(t *testing.T) {
+	for _, c := range []struct{ in, vol, path string }{
+		{`\\[fe80::1]\share\x`, `\\[fe80::1]\share`, `\x`},
+		{`\\[fe80::1%eth0]\share`, `\\[fe80::1%eth0]\share`, ``},
+		{`\\[::1]\c$\Users`, `\\[::1]\c$`, `\Users`},
+		{`\\fe80--1.ipv6-literal.net\share\x`, `\\fe80--1.ipv6-literal.net\share`, `\x`},
+		{`\\[fe80::1]share`, ``, `\\[fe80::1]share`},
+		{`\\[fe80::1\share`, ``, `\\[fe80::1\share`},
+	} {
+		if v, p := Windows.SplitVolume(c.in); v != c.vol || p != c.path {
+			t.Errorf("SplitVolume(%q) = %q, %q; want %q, %q", c.in, v, p, c.vol, c.path)
+		}
+	}
+	isolate(t)
+	r := newResolver()
+	r.MapUNC(`\\[fe80::1]\share`, "/mnt/share")
+	for _, c := range []struct {
+		f, t     Format
+		in, want string
+	}{
+		{Windows, Unix, `\\[fe80::1]\share\x`, "/mnt/share/x"},
+		{Windows, Unix, `\\[FE80::1]\Share`, "/mnt/share"},
+		{Unix, Windows, "/mnt/share/x", `\\[fe80::1]\share\x`},
+	} {
+		got, _, err := r.Format(c.f, c.t, c.in, true, 0)
+		if err != nil || got != c.want {
+			t.Errorf("Format(%q) = %q, %v; want %q", c.in, got, err, c.want)
+		}
+	}
+}
+
+func TestDepth(t *testing.T) {
+	for _, c := range []struct {
+		f    Format
+		in   string
+		want int
+	}{
+		{Windows, `C:\`, 0},
+		{Windows, `C:\a\b`, 2},
+		{Windows, `C:\a\.\b\..\c\`, 2},
+		{Windows, `\\host\share`, 0},
+		{Windows, `\\host\share\`, 0},
+		{Windows, `\\host\share\a`, 1},
+		{Unix, "/", 0},
+		{Unix, "/mnt/c/a/b", 4},
+		{Unix, "/a/../b", 1},
+		{Unix, "a/b", 2},
+		{Unix, ".", 0},
+	} {
+		if got := c.f.Depth(c.in); got != c.want {
+			t.Errorf("%s.Depth(%q) = %d; want %d", c.f, c.in, got, c.want)
+		}
+	}
+}
+
+func TestUNCRoot(t *testing.T) {
+	isolate(t)
+	for _, c := range []struct{ in, vol, path string }{
+		{`\\host\share\`, `\\host\share`, `\`},
+		{`\\host\share`, `\\host\share`, ``},
+		{`\\host\share\x\`, `\\host\share`, `\x\`},
+	} {
+		if v, p := Windows.SplitVolume(c.in); v != c.vol || p != c.path {
+			t.Errorf("SplitVolume(%q) = %q, %q; want %q, %q", c.in, v, p, c.vol, c.path)
+		}
+	}
+	r := newResolver()
+	r.MapUNC(`\\host\share`, "/mnt/share")
+	for _, c := range []struct {
+		trailing bool
+		in, want string
+	}{
+		{false, `\\host\share\`, "/mnt/share"},
+		{false, `\\host\share`, "/mnt/share"},
+		{false, `\\host\share\\`, "/mnt/share"},
+		// the root of a volume is unaffected by TrailingSep
+		{true, `\\host\share\`, "/mnt/share"},
+		{true, `\\host\share\x\`, "/mnt/share/x/"},
+	} {
+		r.TrailingSep = c.trailing
+		got, _, err := r.Format(Windows, Unix, c.in, true, 0)
+		if err != nil || got != c.want {
+			t.Errorf("TrailingSep=%t: Format(%q) = %q, %v; want %q", c.trailing, c.in, got, err, c.want)
+		}
+	}
+}
+
+func TestMultipleVolumes(t *testing.T) {
+	isolate(t)
+	r := newResolver()
+	r.MapDrive('C', "/mnt/c")
+	r.MapDrive('D', "/mnt/d")
+	for _, s := range []string{`C:D:\foo`, `C:d:foo`, `c:D:`} {
+		if got, _, err := r.Format(Windows, Unix, s, false, 0); CodeOf(err) != ErrInvalidPath {
+			t.Errorf("Format(%q) = %q, %v; want %s", s, got, err, ErrInvalidPath)
+		}
+	}
+	// a colon elsewhere is not a volume designator
+	for _, c := range []struct{ in, want string }{
+		{`C:\D:\foo`, "/mnt/c/D:/foo"},
+		{`C:\1:\foo`, "/mnt/c/1:/foo"},
+	} {
+		if got, _, err := r.Format(Windows, Unix, c.in, false, 0); err != nil || got != c.want {
+			t.Errorf("Format(%q) = %q, %v; want %q", c.in, got, err, c.want)
+		}
+	}
+}
+
+func TestSplitVolumeDottedShare(t *testing.T) {
+	for _, c := range []struct{ in, vol, path string }{
+		{`\\host\share.v2$\dir`, `\\host\share.v2$`, `\dir`},
+		{`\\host\share.v2$`, `\\host\share.v2$`, ``},
+		{`\\host\.share\dir`, `\\host\.share`, `\dir`},
+		{`\\host.example.com\share\dir`, `\\host.example.com\share`, `\dir`},
+		{`\\.\C:\dir`, ``, `\\.\C:\dir`},
+	} {
+		if v, p := Windows.SplitVolume(c.in); v != c.vol || p != c.path {
+			t.Errorf("SplitVolume(%q) = %q, %q; want %q, %q", c.in, v, p, c.vol, c.path)
+		}
+	}
+	isolate(t)
+	r := newResolver()
+	r.MapUNC(`\\host\share.v2$`, "/mnt/share")
+	for _, c := range []struct {
+		f, t     Format
+		in, want string
+	}{
+		{Windows, Unix, `\\host\share.v2$\dir`, "/mnt/share/dir"},
+		{Unix, Windows, "/mnt/share/dir", `\\host\share.v2$\dir`},
+	} {
+		got, _, err := r.Format(c.f, c.t, c.in, true, 0)
+		if err != nil || got != c.want {
+			t.Errorf("Format(%q) = %q, %v; want %q", c.in, got, err, c.want)
+		}
+	}
+}
+
+func TestSplitVolumeIPv4(t *testing.T) {
+	for _, c := range []struct{ in, vol, path string }{
+		{`\\192.168.1.10\share\x`, `\\192.168.1.10\share`, `\x`},
+		{`\\192.168.1.10\share`, `\\192.168.1.10\share`, ``},
+		{`\\127.0.0.1\c$\Users`, `\\127.0.0.1\c$`, `\Users`},
+		{`\\10.0.0.1`, ``, `\\10.0.0.1`},
+	} {
+		if v, p := Windows.SplitVolume(c.in); v != c.vol || p != c.path {
+			t.Errorf("SplitVolume(%q) = %q, %q; want %q, %q", c.in, v, p, c.vol, c.path)
+		}
+	}
+	isolate(t)
+	r := newResolver()
+	r.MapUNC(`\\192.168.1.10\share`, "/mnt/share")
+	for _, c := range []struct {
+		f, t     Format
+		in, want string
+	}{
+		{Windows, Unix, `\\192.168.1.10\share\x`, "/mnt/share/x"},
+		{Unix, Windows, "/mnt/share/x", `\\192.168.1.10\share\x`},
+	} {
+		got, _, err := r.Format(c.f, c.t, c.in, true, 0)
+		if err != nil || got != c.want {
+			t.Errorf("Format(%q) = %q, %v; want %q", c.in, got, err, c.want)
+		}
+	}
+}
+
+func TestTrailingDotSpace(t *testing.T) {
+	isolate(t)
+	r := newResolver()
+	r.MapDrive('C', "/mnt/c")
+	for _, c := range []struct {
+		f, t     Format
+		in, want string
+	}{
+		{Windows, Unix, `C:\foo.\bar `, "/mnt/c/foo./bar "},
+		{Windows, Unix, `C:\foo..\bar. .`, "/mnt/c/foo../bar. ."},
+		{Windows, Unix, `C:\ foo \ `, "/mnt/c/ foo / "},
+		{Unix, Windows, "/mnt/c/foo./bar ", `C:\foo.\bar `},
+	} {
+		if got, _, err := r.Format(c.f, c.t, c.in, false, 0); err != nil || got != c.want {
+			t.Errorf("Format(%q) = %q, %v; want %q", c.in, got, err, c.want)
+		}
+	}
+}
+
+func TestMixedSeparators(t *testing.T) {
+	for _, c := range []struct{ in, want string }{
+		{`C:/Users/me\Documents`, `C:\Users\me\Documents`},
+		{`C:\Users//me\\Documents/`, `C:\Users\me\Documents`},
+		{`\\host/share/doc`, `\\host\share\doc`},
+		// "/" is an ordinary character in verbatim paths
+		{`\\?\C:\a/b`, `\\?\C:\a/b`},
+	} {
+		if got := Windows.Clean(c.in); got != c.want {
+			t.Errorf("Clean(%q) = %q; want %q", c.in, got, c.want)
+		}
+	}
+	for _, s := range []string{`C:/Users/me\Documents`, `c:/x`, `C:/`} {
+		if f := Identify(s); f != Windows {
+			t.Errorf("Identify(%q) = %s; want %s", s, f, Windows)
+		}
+	}
+	if got, want := Windows.Elements(`C:/Users/me\Documents`), []string{"C:", "Users", "me", "Documents"}; strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Elements() = %q; want %q", got, want)
+	}
+	isolate(t)
+	r := newResolver()
+	r.MapDrive('C', "/mnt/c")
+	if got, _, err := r.Format(Windows, Unix, `C:/Users/me\Documents`, false, 0); err != nil || got != "/mnt/c/Users/me/Documents" {
+		t.Errorf("Format() = %q, %v; want %q", got, err, "/mnt/c/Users/me/Documents")
+	}
+}
+
+func TestTrailingSep(t *testing.T) {
+	isolate(t)
+	r := newResolver()
+	r.MapDrive('C', "/mnt/c")
+	for _, c := range []struct {
+		trailing bool
+		f, t     Format
+		in, want string
+	}{
+		{true, Unix, Windows, "/mnt/c/Users/", `C:\Users\`},
+		{false, Unix, Windows, "/mnt/c/Users/", `C:\Users`},
+		{true, Unix, Windows, "/mnt/c/Users", `C:\Users`},
+		{true, Unix, Windows, "/mnt/c/Users//", `C:\Users\`},
+		{true, Unix, Windows, "/mnt/c/Users/me/../", `C:\Users\`},
+		{true, Unix, Windows, "/mnt/c/Users/.", `C:\Users`},
+		{true, Windows, Unix, `C:\Users\`, "/mnt/c/Users/"},
+		{true, Windows, Unix, `C:/Users/`, "/mnt/c/Users/"},
+		{true, Windows, Unix, `C:\Users\me\..\`, "/mnt/c/Users/"},
+		// roots are unaffected
+		{true, Unix, Windows, "/mnt/c/", `C:\`},
+		{true, Windows, Unix, `C:\`, "/mnt/c"},
+		{true, Windows, Unix, `C:\x\..\`, "/mnt/c"},
+	} {
+		r.TrailingSep = c.trailing
+		if got, _, err := r.Format(c.f, c.t, c.in, true, 0); err != nil || got != c.want {
+			t.Errorf("TrailingSep=%t: Format(%q) = %q, %v; want %q", c.trailing, c.in, got, err, c.want)
+		}
+	}
+}
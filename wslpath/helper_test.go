@@ -0,0 +1,37 @@
+package wslpath
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// isolate removes every environment variable consulted by a Resolver for the
+// duration of the test, so that volume mappings of the host running the tests
+// do not interfere. Variables are restored once the test completes.
+func isolate(t testing.TB) {
+	t.Helper()
+	for _, e := range os.Environ() {
+		k := e[:strings.IndexRune(e, '=')]
+		switch {
+		case strings.HasSuffix(k, NixPathEnvSuffix), k == UncPathEnvVar,
+			k == WslRootfsEnvVar, k == DistroEnvVar:
+			unsetenv(t, k)
+		}
+	}
+}
+
+// unsetenv removes the given environment variable for the duration of the
+// test.
+func unsetenv(t testing.TB, key string) {
+	t.Helper()
+	t.Setenv(key, "")
+	os.Unsetenv(key)
+}
+
+// newResolver returns a Resolver that consults no mount table, so that the
+// drvfs mounts of the host running the tests do not interfere, and that does
+// not resolve symbolic links.
+func newResolver() *Resolver {
+	return &Resolver{MountTable: os.DevNull, Logical: true}
+}
@@ -0,0 +1,49 @@
+package wslpath
+
+import (
+	"os"
+	"strings"
+)
+
+// ExpandHome returns the given Unix file path s with a leading "~" element
+// replaced by the user's home directory ($HOME).
+//
+// Only a "~" that makes up the entire first path element is expanded, so "~"
+// and "~/x" are expanded, but neither "~user" nor any "~" embedded in a path
+// element (e.g., the Windows 8.3 short name "PROGRA~1") is changed. Windows
+// file paths are never expanded, even if they begin with "~\". If the home
+// directory is not defined, s is returned unchanged.
+func ExpandHome(s string) string {
+	if s != "~" && !strings.HasPrefix(s, "~/") {
+		return s
+	}
+	home, ok := os.LookupEnv("HOME")
+	if !ok || "" == home {
+		return s
+	}
+	return strings.TrimSuffix(home, "/") + s[1:]
+}
+
+// Tildify returns the given absolute Unix file path s with the user's home
+// directory ($HOME) prefix replaced by "~". It is the inverse of ExpandHome.
+//
+// The result is intended for display only and is not a valid path to any
+// program that does not itself perform home expansion (e.g., this program
+// without -expand-home).
+func Tildify(s string) string {
+	home, ok := os.LookupEnv("HOME")
+	if !ok || "" == home || !Unix.IsAbs(home) {
+		return s
+	}
+	home = Unix.Clean(home)
+	if "/" == home {
+		return s
+	}
+	if rest, ok := Unix.trimPrefix(s, home); ok {
+		if rest = strings.TrimLeft(rest, "/"); "" == rest {
+			return "~"
+		}
+		return "~/" + rest
+	}
+	return s
+}
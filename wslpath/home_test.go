@@ -0,0 +1,48 @@
+package wslpath
+
+import "testing"
+
+func TestExpandHome(t *testing.T) {
+	t.Setenv("HOME", "/home/me/")
+	for _, c := range []struct{ in, want string }{
+		{"~", "/home/me"},
+		{"~/x", "/home/me/x"},
+		{"~/x/y/", "/home/me/x/y/"},
+		{"~user/x", "~user/x"},
+		{"a/~/x", "a/~/x"},
+		// Windows 8.3 short names are never expanded
+		{`C:\PROGRA~1\app`, `C:\PROGRA~1\app`},
+		{`PROGRA~1\app`, `PROGRA~1\app`},
+		{`~\x`, `~\x`},
+	} {
+		if got := ExpandHome(c.in); got != c.want {
+			t.Errorf("ExpandHome(%q) = %q; want %q", c.in, got, c.want)
+		}
+	}
+	unsetenv(t, "HOME")
+	if got := ExpandHome("~/x"); got != "~/x" {
+		t.Errorf("ExpandHome(%q) without HOME = %q; want unchanged", "~/x", got)
+	}
+}
+
+func TestTildify(t *testing.T) {
+	for _, c := range []struct{ home, in, want string }{
+		{"/home/me", "/home/me", "~"},
+		{"/home/me/", "/home/me/x/y", "~/x/y"},
+		{"/home/me", "/home/meow/x", "/home/meow/x"},
+		{"/home/me", "/mnt/c/Users/me", "/mnt/c/Users/me"},
+		{"/", "/x", "/x"},
+		{"me", "me/x", "me/x"},
+		{"", "/home/me/x", "/home/me/x"},
+	} {
+		t.Setenv("HOME", c.home)
+		if got := Tildify(c.in); got != c.want {
+			t.Errorf("HOME=%q: Tildify(%q) = %q; want %q", c.home, c.in, got, c.want)
+		}
+	}
+	// the inverse of ExpandHome
+	t.Setenv("HOME", "/home/me")
+	if got := ExpandHome(Tildify("/home/me/x")); got != "/home/me/x" {
+		t.Errorf("ExpandHome(Tildify(%q)) = %q", "/home/me/x", got)
+	}
+}
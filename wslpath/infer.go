@@ -0,0 +1,66 @@
+package wslpath
+
+import "os"
+
+// inferVolume returns the given Windows path s anchored to the current working
+// directory, if s has no volume and the current working directory lies on a
+// mounted Windows volume. Relative paths (e.g., `sub\file`) are joined to the
+// Windows path of the working directory, and rooted paths (e.g., `\Windows`)
+// are prefixed with its volume, as Windows itself anchors them. The returned
+// bool is false, and s is returned unchanged, otherwise.
+func (r *Resolver) inferVolume(s string) (string, bool) {
+	if v, _ := Windows.SplitVolume(s); v != "" || len(s) == 0 {
+		return s, false
+	}
+	if len(s) > 1 && s[0] == '\\' && s[1] == '\\' {
+		return s, false
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return s, false
+	}
+	cwd, _, err := r.Format(Unix, Windows, wd, true, 0)
+	if err != nil {
+		return s, false
+	}
+	v, _ := Windows.SplitVolume(cwd)
+	if v == "" {
+		return s, false
+	}
+	if s[0] == '\\' {
+		return v + s, true
+	}
+	return Windows.join(cwd, s), true
+}
+
+// anchorVolume returns the given Windows path s anchored to the Windows volume
+// or directory dir (e.g., the receiver Resolver r's Volume), if s has no
+// volume. Relative paths (e.g., `sub\file`) are joined to dir, and rooted paths
+// (e.g., `\sub\file`) are prefixed with its volume. The returned bool is false,
+// and s is returned unchanged, otherwise.
+func (r *Resolver) anchorVolume(s, dir string) (string, bool) {
+	if v, _ := Windows.SplitVolume(s); v != "" || len(s) == 0 || dir == "" {
+		return s, false
+	}
+	if len(s) > 1 && s[0] == '\\' && s[1] == '\\' {
+		return s, false
+	}
+	v, _ := Windows.SplitVolume(dir)
+	if s[0] == '\\' || s[0] == '/' {
+		return v + s, true
+	}
+	return Windows.join(dir, s), true
+}
+
+// base returns the receiver Resolver r's Base if it is defined and may anchor
+// relative paths in Format f, i.e., Base is not a path of the other Format.
+func (r *Resolver) base(f Format) (string, bool) {
+	if r.Base == "" {
+		return "", false
+	}
+	switch Identify(r.Base) {
+	case f, Any:
+		return r.Base, true
+	}
+	return "", false
+}
@@ -0,0 +1,95 @@
+package wslpath
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInferVolume(t *testing.T) {
+	isolate(t)
+	mnt := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(mnt, "projects"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("C"+NixPathEnvSuffix, mnt)
+	r := newResolver()
+	for _, c := range []struct {
+		infer    bool
+		wd       string
+		in, want string
+	}{
+		{true, "projects", `sub\file`, mnt + "/projects/sub/file"},
+		{true, "projects", `..\x`, mnt + "/x"},
+		{true, "projects", `\Windows`, mnt + "/Windows"},
+		{true, "projects", `C:\x`, mnt + "/x"},
+		{true, "", `sub\file`, mnt + "/sub/file"},
+		// relative paths are kept without inference
+		{false, "projects", `sub\file`, "sub/file"},
+	} {
+		t.Chdir(filepath.Join(mnt, c.wd))
+		t.Setenv("PWD", filepath.Join(mnt, c.wd))
+		r.InferVolume = c.infer
+		got, _, err := r.Format(Windows, Unix, c.in, false, 0)
+		if err != nil || got != c.want {
+			t.Errorf("InferVolume=%t, wd=%q: Format(%q) = %q, %v; want %q", c.infer, c.wd, c.in, got, err, c.want)
+		}
+	}
+	// nor if the working directory is not on a mounted volume
+	t.Chdir(t.TempDir())
+	r.InferVolume = true
+	if got, _, err := r.Format(Windows, Unix, `sub\file`, false, 0); err != nil || got != "sub/file" {
+		t.Errorf("Format(%q) outside mount = %q, %v; want %q", `sub\file`, got, err, "sub/file")
+	}
+}
+
+func TestAnchorVolume(t *testing.T) {
+	isolate(t)
+	r := newResolver()
+	r.MapDrive('D', "/mnt/d")
+	r.MapUNC(`\\host\share`, "/mnt/share")
+	for _, c := range []struct {
+		vol      string
+		in, want string
+	}{
+		{`\\host\share`, `sub\file`, "/mnt/share/sub/file"},
+		{`\\host\share\`, `sub\file`, "/mnt/share/sub/file"},
+		{`\\host\share\dir`, `sub\file`, "/mnt/share/dir/sub/file"},
+		{`\\host\share\dir`, `\sub\file`, "/mnt/share/sub/file"},
+		{`\\HOST\Share`, `..\file`, "/mnt/share/file"},
+		{`D:\`, `sub\file`, "/mnt/d/sub/file"},
+		// paths with a volume are not anchored
+		{`\\host\share`, `D:\file`, "/mnt/d/file"},
+		{`D:\`, `\\host\share\file`, "/mnt/share/file"},
+	} {
+		r.Volume = c.vol
+		got, _, err := r.Format(Windows, Unix, c.in, false, 0)
+		if err != nil || got != c.want {
+			t.Errorf("Volume=%q: Format(%q) = %q, %v; want %q", c.vol, c.in, got, err, c.want)
+		}
+	}
+}
+
+func TestBase(t *testing.T) {
+	isolate(t)
+	r := newResolver()
+	r.MapDrive('C', "/mnt/c")
+	for _, c := range []struct {
+		base     string
+		f, t     Format
+		in, want string
+	}{
+		{"/mnt/c/proj", Unix, Windows, "src/main.go", `C:\proj\src\main.go`},
+		{"/mnt/c/proj", Unix, Windows, "../x", `C:\x`},
+		{`C:\proj`, Windows, Unix, `src\main.go`, "/mnt/c/proj/src/main.go"},
+		{`C:\proj`, Windows, Unix, `..\x`, "/mnt/c/x"},
+		// absolute paths are not anchored
+		{"/mnt/c/proj", Unix, Windows, "/mnt/c/x", `C:\x`},
+		{`C:\proj`, Windows, Unix, `C:\x`, "/mnt/c/x"},
+	} {
+		r.Base = c.base
+		if got, _, err := r.Format(c.f, c.t, c.in, true, 0); err != nil || got != c.want {
+			t.Errorf("Base=%q: Format(%q) = %q, %v; want %q", c.base, c.in, got, err, c.want)
+		}
+	}
+}
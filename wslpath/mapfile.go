@@ -0,0 +1,120 @@
+package wslpath
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// LoadMapFile reads volume mappings from the named file into the receiver
+// Resolver r. See ReadMaps for the file format.
+func (r *Resolver) LoadMapFile(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return r.ReadMaps(f, name)
+}
+
+// ReadMaps reads volume mappings from the given io.Reader into the receiver
+// Resolver r, using name to identify the source in errors and traces.
+//
+// Each line defines a single mapping of the form "DRIVE=MOUNT" (e.g.,
+// "C:=/mnt/c" or "C=/mnt/c") or "UNC=MOUNT" (e.g., "\\host\share=/mnt/share").
+// Blank lines and lines beginning with "#" are ignored. Mappings read take
+// precedence over those defined in the environment, and later mappings of the
+// same volume replace earlier ones.
+func (r *Resolver) ReadMaps(rd io.Reader, name string) error {
+	s := bufio.NewScanner(rd)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		key := fmt.Sprintf("%s:%d", name, n)
+		m := strings.SplitN(line, "=", 2)
+		if len(m) != 2 || len(m[0]) == 0 || len(m[1]) == 0 {
+			return fmt.Errorf("%s: expected VOLUME=MOUNT: %q", key, line)
+		}
+		vol, path := strings.TrimSpace(m[0]), strings.TrimSpace(m[1])
+		if !Unix.IsAbs(path) {
+			return fmt.Errorf("%s: mount point is not an absolute path: %q", key, path)
+		}
+		if d := strings.TrimSuffix(vol, ":"); len(d) == 1 && isalpha(d[0]) {
+			r.mapDrive(d[0], path, key)
+			continue
+		}
+		if v, _ := Windows.SplitVolume(vol); len(v) > 2 && v[1] != ':' {
+			r.mapUNC(vol, path, key)
+			continue
+		}
+		return fmt.Errorf("%s: invalid drive letter or UNC volume: %q", key, vol)
+	}
+	return s.Err()
+}
+
+// LoadUNCFile reads UNC volume mappings from the named file into the receiver
+// Resolver r, one mapping per line of the form "UNC=MOUNT" (e.g.,
+// `\\host\share\dir=/mnt/share`). Blank lines and lines beginning with "#" are
+// ignored. Mappings read take precedence over those defined in UncPathEnvVar,
+// and later mappings of the same volume replace earlier ones.
+func (r *Resolver) LoadUNCFile(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var lines []string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		lines = append(lines, s.Text())
+	}
+	if err := s.Err(); err != nil {
+		return err
+	}
+	u, err := parseUNCMappings(lines, func(n int) string {
+		return fmt.Sprintf("%s:%d", name, n+1)
+	})
+	if err != nil {
+		return err
+	}
+	for _, m := range u {
+		r.mapUNC(m.volume, m.path, m.key)
+	}
+	return nil
+}
+
+// parseUNCMappings returns the UNC volume mappings of the form "UNC=MOUNT"
+// held by the given entries, each identified by the key returned by key for
+// its index. Blank entries and entries beginning with "#" are ignored. All
+// valid entries are returned, along with an error describing the first
+// invalid entry, if any.
+func parseUNCMappings(entries []string, key func(int) string) ([]uncMapping, error) {
+	var u []uncMapping
+	var err error
+	for i, e := range entries {
+		e = strings.TrimSpace(e)
+		if len(e) == 0 || e[0] == '#' {
+			continue
+		}
+		m := strings.SplitN(e, "=", 2)
+		if len(m) != 2 || len(m[0]) == 0 || len(m[1]) == 0 {
+			if err == nil {
+				err = fmt.Errorf("%s: expected UNC=MOUNT: %q", key(i), e)
+			}
+			continue
+		}
+		vol, path := strings.TrimSpace(m[0]), strings.TrimSpace(m[1])
+		if v, _ := Windows.SplitVolume(vol); len(v) <= 2 || v[1] == ':' {
+			if err == nil {
+				err = fmt.Errorf("%s: invalid UNC volume: %q", key(i), vol)
+			}
+			continue
+		}
+		u = append(u, uncMapping{volume: Windows.Clean(vol), path: Unix.Clean(path), key: key(i)})
+	}
+	return u, err
+}
@@ -0,0 +1,129 @@
+package wslpath
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadMaps(t *testing.T) {
+	isolate(t)
+	t.Setenv("C"+NixPathEnvSuffix, "/mnt/c")
+	r := newResolver()
+	err := r.ReadMaps(strings.NewReader(`
+# drives
+C:=/data/c
+d=/mnt/d
+  \\host\share = /mnt/share
+\\host\share\deep=/mnt/deep
+`), "maps")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct{ in, want string }{
+		// mappings read take precedence over the environment
+		{`C:\x`, "/data/c/x"},
+		{`D:\x`, "/mnt/d/x"},
+		{`\\host\share\x`, "/mnt/share/x"},
+		{`\\HOST\Share\deep\x`, "/mnt/deep/x"},
+	} {
+		got, _, err := r.Format(Windows, Unix, c.in, true, 0)
+		if err != nil || got != c.want {
+			t.Errorf("Format(%q) = %q, %v; want %q", c.in, got, err, c.want)
+		}
+	}
+	for _, c := range []struct{ in, want string }{
+		{"C:=/mnt/c\nD:/mnt/d\n", "maps:2: expected VOLUME=MOUNT"},
+		{"\n\nC:=mnt/c\n", "maps:3: mount point is not an absolute path"},
+		{"CD:=/mnt/cd\n", "maps:1: invalid drive letter or UNC volume"},
+		{`\\host=/mnt/host`, "maps:1: invalid drive letter or UNC volume"},
+	} {
+		err := newResolver().ReadMaps(strings.NewReader(c.in), "maps")
+		if err == nil || !strings.HasPrefix(err.Error(), c.want) {
+			t.Errorf("ReadMaps(%q) = %v; want %q", c.in, err, c.want)
+		}
+	}
+}
+
+func TestLoadMapFile(t *testing.T) {
+	isolate(t)
+	name := filepath.Join(t.TempDir(), "maps")
+	if err := ioutil.WriteFile(name, []byte("C=/data/c\n\\\\host\\share=/mnt/share\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	r := newResolver()
+	if err := r.LoadMapFile(name); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct{ in, want string }{
+		{"/data/c/x", `C:\x`},
+		{"/mnt/share/x", `\\host\share\x`},
+	} {
+		got, _, err := r.Format(Unix, Windows, c.in, true, 0)
+		if err != nil || got != c.want {
+			t.Errorf("Format(%q) = %q, %v; want %q", c.in, got, err, c.want)
+		}
+	}
+	if err := r.LoadMapFile(name + ".missing"); err == nil {
+		t.Error("LoadMapFile(missing) = nil; want error")
+	}
+}
+
+func TestLoadUNCFile(t *testing.T) {
+	isolate(t)
+	t.Setenv(UncPathEnvVar, `\\host\share=/mnt/env;\\host\other=/mnt/other`)
+	name := filepath.Join(t.TempDir(), "unc")
+	content := `# shares
+\\host\share=/mnt/file
+
+  \\host\docs\dir = /mnt/docs
+\\HOST\Docs\dir=/mnt/docs2
+`
+	if err := ioutil.WriteFile(name, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	r := newResolver()
+	if err := r.LoadUNCFile(name); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct{ in, want string }{
+		// mappings read take precedence over the environment
+		{`\\host\share\x`, "/mnt/file/x"},
+		// and merge with it
+		{`\\host\other\x`, "/mnt/other/x"},
+		// later mappings of the same volume win
+		{`\\host\docs\dir\x`, "/mnt/docs2/x"},
+	} {
+		got, _, err := r.Format(Windows, Unix, c.in, true, 0)
+		if err != nil || got != c.want {
+			t.Errorf("Format(%q) = %q, %v; want %q", c.in, got, err, c.want)
+		}
+	}
+	if err := r.LoadUNCFile(name + ".missing"); err == nil {
+		t.Error("LoadUNCFile(missing) = nil; want error")
+	}
+}
+
+func TestParseUNCMappings(t *testing.T) {
+	key := func(n int) string { return fmt.Sprintf("unc:%d", n+1) }
+	for _, c := range []struct {
+		in   []string
+		n    int
+		want string
+	}{
+		{[]string{`\\h\s=/a`, "# c", "", `\\h\t\dir=/b`}, 2, ""},
+		{[]string{`\\h\s=/a`, `\\h\t`}, 1, "unc:2: expected UNC=MOUNT"},
+		{[]string{`C:\x=/a`, `\\h\s=/a`}, 1, "unc:1: invalid UNC volume"},
+		{[]string{`\\h=/a`, `=/a`}, 0, "unc:1: invalid UNC volume"},
+	} {
+		u, err := parseUNCMappings(c.in, key)
+		if len(u) != c.n {
+			t.Errorf("parseUNCMappings(%q) = %d mappings; want %d", c.in, len(u), c.n)
+		}
+		if (c.want == "") != (err == nil) || (err != nil && !strings.HasPrefix(err.Error(), c.want)) {
+			t.Errorf("parseUNCMappings(%q) error = %v; want %q", c.in, err, c.want)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package wslpath
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// DefaultMountTable is the mount table consulted for drvfs mounts if a
+// Resolver's MountTable is undefined.
+const DefaultMountTable = "/proc/mounts"
+
+// tableEntry is a single mount point listed in a mount table.
+type tableEntry struct {
+	path   string
+	fstype string
+	// drive is the uppercase drive letter whose root is mounted with drvfs
+	// (including drvfs over 9p, as in WSL 2), or 0 for all other mounts.
+	drive byte
+}
+
+// readTable returns the entries of the receiver Resolver r's mount table. The
+// mount table is read only once, on first use, and cached for the lifetime of
+// r. If it cannot be read, no entries are returned.
+func (r *Resolver) readTable() []tableEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.tabled {
+		return r.table
+	}
+	r.tabled = true
+	f, err := os.Open(r.mountTable())
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		// device, mount point, file system type, options, ...
+		e := strings.Fields(s.Text())
+		if len(e) < 3 {
+			continue
+		}
+		t := tableEntry{path: Unix.Clean(unescapeMount(e[1])), fstype: e[2]}
+		// only the root of a drive maps the drive itself
+		if d := unescapeMount(e[0]); (e[2] == "drvfs" || e[2] == "9p") &&
+			len(d) >= 2 && d[1] == ':' && isalpha(d[0]) && strings.Trim(d[2:], `\/`) == "" {
+			t.drive = upper(d[0])
+		}
+		r.table = append(r.table, t)
+	}
+	return r.table
+}
+
+// mountTable returns the path of the receiver Resolver r's mount table.
+func (r *Resolver) mountTable() string {
+	if r.MountTable == "" {
+		return DefaultMountTable
+	}
+	return r.MountTable
+}
+
+// tableMounts returns the mount points of the Windows drives mounted with the
+// drvfs file system, as published by WSL in the receiver Resolver r's mount
+// table.
+func (r *Resolver) tableMounts() []mount {
+	var m []mount
+	for _, t := range r.readTable() {
+		if t.drive != 0 {
+			m = append(m, mount{drive: t.drive, path: t.path, key: r.mountTable()})
+		}
+	}
+	return m
+}
+
+// CheckMount returns an error if the given absolute Unix path s lies under the
+// mount point of a drive defined in the environment or given explicitly (e.g.,
+// C_VOLUME_PATH=/mnt/c), and that mount point is not a drvfs mount of the same
+// drive in the receiver Resolver r's mount table, such as a stale mapping of a
+// drive that has since been unmounted. The longest matching mount point is
+// checked.
+func (r *Resolver) CheckMount(s string) error {
+	var m mount
+	mounts, _ := r.driveMounts()
+	for _, e := range mounts {
+		if _, ok := r.trimMount(s, e.path); ok && len(e.path) > len(m.path) {
+			m = e
+		}
+	}
+	if m.path == "" {
+		return nil
+	}
+	// a later mount at the same point hides all earlier ones
+	table := r.readTable()
+	for i := len(table) - 1; i >= 0; i-- {
+		t := table[i]
+		if t.path != m.path {
+			continue
+		}
+		switch t.drive {
+		case m.drive:
+			return nil
+		case 0:
+			return errorf(ErrNoMapping, "%s=%s is a %s mount, not a drvfs mount of %c:", m.key, m.path, t.fstype, m.drive)
+		default:
+			return errorf(ErrNoMapping, "%s=%s is a drvfs mount of %c:, not %c:", m.key, m.path, t.drive, m.drive)
+		}
+	}
+	return errorf(ErrNoMapping, "%s=%s is not mounted (not found in %s)", m.key, m.path, r.mountTable())
+}
+
+// tableMount returns the mount point of the given drive letter found in the
+// mount table of the receiver Resolver r.
+func (r *Resolver) tableMount(drive byte) (mount, bool) {
+	for _, m := range r.tableMounts() {
+		if m.drive == upper(drive) {
+			return m, true
+		}
+	}
+	return mount{}, false
+}
+
+// unescapeMount returns the given field of a mount table entry with each octal
+// escape sequence (e.g., "\040" for a space, or "\134" for a backslash)
+// replaced by the byte it encodes.
+func unescapeMount(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+4 <= len(s) {
+			if c, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,117 @@
+package wslpath
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// mountTable writes the given content to a new mount table file, which is
+// removed once the test completes, and returns its path.
+func mountTable(t testing.TB, content string) string {
+	t.Helper()
+	name := filepath.Join(t.TempDir(), "mounts")
+	if err := ioutil.WriteFile(name, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+const testMounts = `none /mnt/wsl tmpfs rw,relatime 0 0
+C:\134 /mnt/c 9p rw,noatime,dirsync,aname=drvfs;path=C:\;uid=1000 0 0
+D:\134 /mnt/d drvfs rw,noatime 0 0
+E:\134Data /mnt/data drvfs rw,noatime 0 0
+F: /mnt/my\040drive drvfs rw,noatime 0 0
+/dev/sdb / ext4 rw,relatime 0 0
+`
+
+func TestTableMounts(t *testing.T) {
+	isolate(t)
+	r := &Resolver{MountTable: mountTable(t, testMounts), Logical: true}
+	t.Setenv("D"+NixPathEnvSuffix, "/media/d")
+	for _, c := range []struct {
+		f, t     Format
+		in, want string
+	}{
+		{Windows, Unix, `C:\Users`, "/mnt/c/Users"},
+		{Unix, Windows, "/mnt/c/Users", `C:\Users`},
+		{Windows, Unix, `F:\x`, "/mnt/my drive/x"},
+		{Unix, Windows, "/mnt/my drive/x", `F:\x`},
+		// the environment takes precedence over the mount table
+		{Windows, Unix, `D:\x`, "/media/d/x"},
+		{Unix, Windows, "/media/d/x", `D:\x`},
+	} {
+		if got, _, err := r.Format(c.f, c.t, c.in, true, 0); err != nil || got != c.want {
+			t.Errorf("Format(%q) = %q, %v; want %q", c.in, got, err, c.want)
+		}
+	}
+	// only the root of a drive maps the drive itself
+	if got, _, err := r.Format(Windows, Unix, `E:\x`, true, 0); CodeOf(err) != ErrEnvNotSet {
+		t.Errorf("Format(%q) = %q, %v; want %s", `E:\x`, got, err, ErrEnvNotSet)
+	}
+}
+
+func TestUnescapeMount(t *testing.T) {
+	for _, c := range []struct{ in, want string }{
+		{`/mnt/my\040drive`, "/mnt/my drive"},
+		{`C:\134`, `C:\`},
+		{`a\011b\012`, "a\tb\n"},
+		{`\04`, `\04`},
+		{`\999`, `\999`},
+		{"/mnt/c", "/mnt/c"},
+	} {
+		if got := unescapeMount(c.in); got != c.want {
+			t.Errorf("unescapeMount(%q) = %q; want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestMountTableCache(t *testing.T) {
+	isolate(t)
+	name := mountTable(t, testMounts)
+	r := &Resolver{MountTable: name, Logical: true}
+	if got, _, err := r.Format(Windows, Unix, `C:\x`, true, 0); err != nil || got != "/mnt/c/x" {
+		t.Fatalf("Format(%q) = %q, %v; want %q", `C:\x`, got, err, "/mnt/c/x")
+	}
+	// the mount table is read once for the lifetime of the Resolver
+	if err := ioutil.WriteFile(name, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got, _, err := r.Format(Windows, Unix, `C:\x`, true, 0); err != nil || got != "/mnt/c/x" {
+		t.Errorf("Format(%q) after change = %q, %v; want %q", `C:\x`, got, err, "/mnt/c/x")
+	}
+}
+
+func TestCheckMount(t *testing.T) {
+	isolate(t)
+	r := &Resolver{MountTable: mountTable(t, testMounts), Logical: true}
+	t.Setenv("C"+NixPathEnvSuffix, "/mnt/c")
+	t.Setenv("D"+NixPathEnvSuffix, "/mnt/c/d")
+	t.Setenv("G"+NixPathEnvSuffix, "/mnt/d")
+	t.Setenv("H"+NixPathEnvSuffix, "/mnt/wsl")
+	t.Setenv("K"+NixPathEnvSuffix, "/mnt/k")
+	for _, c := range []struct {
+		in, want string
+	}{
+		{"/mnt/c/Users", ""},
+		{"/mnt/c", ""},
+		// paths not under a configured mount point are not checked
+		{"/home/me", ""},
+		{"/mnt/c/d/x", "D_VOLUME_PATH=/mnt/c/d is not mounted"},
+		{"/mnt/d/x", "G_VOLUME_PATH=/mnt/d is a drvfs mount of D:, not G:"},
+		{"/mnt/wsl/x", "H_VOLUME_PATH=/mnt/wsl is a tmpfs mount, not a drvfs mount of H:"},
+		{"/mnt/k", "K_VOLUME_PATH=/mnt/k is not mounted"},
+	} {
+		err := r.CheckMount(c.in)
+		if c.want == "" {
+			if err != nil {
+				t.Errorf("CheckMount(%q) = %v; want nil", c.in, err)
+			}
+			continue
+		}
+		if CodeOf(err) != ErrNoMapping || !strings.HasPrefix(err.Error(), c.want) {
+			t.Errorf("CheckMount(%q) = %v; want %s: %s", c.in, err, ErrNoMapping, c.want)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package wslpath
+
+import "strings"
+
+// lookalikes maps compatibility characters that resemble the ASCII characters
+// significant in file paths, outside of the fullwidth forms, to those ASCII
+// characters.
+var lookalikes = map[rune]rune{
+	'∕': '/',  // DIVISION SLASH
+	'⁄': '/',  // FRACTION SLASH
+	'⧵': '\\', // REVERSE SOLIDUS OPERATOR
+	'﹨': '\\', // SMALL REVERSE SOLIDUS
+	'∶': ':',  // RATIO
+	'꞉': ':',  // MODIFIER LETTER COLON
+	'﹕': ':',  // SMALL COLON
+	'　': ' ',  // IDEOGRAPHIC SPACE
+}
+
+// NormalizeUnicode returns the given string s with each fullwidth form of an
+// ASCII character (U+FF01 to U+FF5E, e.g., "：" or "＼", as produced by some
+// input methods) replaced by that ASCII character, and each common lookalike of
+// a directory separator or colon (e.g., "∕" DIVISION SLASH) replaced by the
+// character it resembles. Such a path would otherwise not be recognized (e.g.,
+// "Ｃ：＼Users" as a Windows drive path).
+//
+// These characters are legal in file names, so s should be normalized only if
+// it is known to be mistyped or pasted from a source that substitutes them.
+func NormalizeUnicode(s string) string {
+	return strings.Map(func(c rune) rune {
+		if '！' <= c && c <= '～' {
+			return c - '！' + '!'
+		}
+		if a, ok := lookalikes[c]; ok {
+			return a
+		}
+		return c
+	}, s)
+}
@@ -0,0 +1,26 @@
+package wslpath
+
+import "testing"
+
+func TestNormalizeUnicode(t *testing.T) {
+	for _, c := range []struct{ in, want string }{
+		{`C：\Users\me`, `C:\Users\me`},
+		{`Ｃ：＼Users＼me`, `C:\Users\me`},
+		{`C∶⧵Users﹨me`, `C:\Users\me`},
+		{"∕mnt⁄c", "/mnt/c"},
+		{"my　file.txt", "my file.txt"},
+		// other non-ASCII characters are unchanged
+		{`C:\Users\müller\日本`, `C:\Users\müller\日本`},
+	} {
+		if got := NormalizeUnicode(c.in); got != c.want {
+			t.Errorf("NormalizeUnicode(%q) = %q; want %q", c.in, got, c.want)
+		}
+	}
+	// a fullwidth drive path is recognized only once normalized
+	if f := Identify(`C：＼Users`); f == Windows {
+		t.Errorf("Identify(%q) = %s; want not %s", `C：＼Users`, f, Windows)
+	}
+	if f := Identify(NormalizeUnicode(`C：＼Users`)); f != Windows {
+		t.Errorf("Identify(NormalizeUnicode(%q)) = %s; want %s", `C：＼Users`, f, Windows)
+	}
+}
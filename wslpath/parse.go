@@ -0,0 +1,75 @@
+package wslpath
+
+import "strings"
+
+// ParsedPath is the structure of a cleaned file path, as returned by
+// Format.Parse.
+type ParsedPath struct {
+	// Format is the Format in which the path was parsed.
+	Format Format
+	// Volume is the Windows volume of the path, either a drive letter (e.g.,
+	// "C:") or a UNC host+share (e.g., `\\host\share`), and empty for Unix
+	// paths and Windows paths without a volume.
+	Volume string
+	// Absolute is true if and only if the path is absolute, as reported by
+	// Format.IsAbs.
+	Absolute bool
+	// Rooted is true if and only if the path following Volume begins with a
+	// directory separator. Rooted paths without a volume (e.g., `\foo`) and
+	// drive-relative paths (e.g., "C:foo") are not Absolute.
+	Rooted bool
+	// Elements are the path components following Volume, excluding all
+	// directory separators.
+	Elements []string
+}
+
+// Parse returns the structure of the given file path s, cleaned, in the
+// receiver Format f. If f is Any, the Format of s is first identified with
+// Identify. An error is returned if s is empty or, in Windows Format, has more
+// than one volume designator (e.g., "C:D:\foo").
+func (f Format) Parse(s string) (ParsedPath, error) {
+	if s == "" {
+		return ParsedPath{}, errorf(ErrInvalidPath, "empty path")
+	}
+	if Any == f {
+		f = Identify(s)
+	}
+	var v, p string
+	if Windows == f {
+		v, p = f.SplitVolume(f.Clean(s))
+		if len(v) == 2 && len(p) >= 2 && p[1] == ':' && isalpha(p[0]) {
+			return ParsedPath{}, errorf(ErrInvalidPath, "malformed path: multiple volume designators: %s", s)
+		}
+	} else {
+		p = f.Clean(s)
+	}
+	pp := ParsedPath{
+		Format:   f,
+		Volume:   v,
+		Absolute: f.IsAbs(v + p),
+		Rooted:   len(p) > 0 && f.issep(rune(p[0])),
+		Elements: []string{},
+	}
+	for _, e := range f.Elements(p) {
+		if e != "" && e != "." {
+			pp.Elements = append(pp.Elements, e)
+		}
+	}
+	return pp, nil
+}
+
+// String returns the file path represented by the receiver ParsedPath p, which
+// is the cleaned path given to Format.Parse.
+func (p ParsedPath) String() string {
+	sep := string(p.Format.sep())
+	s := p.Volume
+	if p.Rooted {
+		s += sep
+	}
+	s += strings.Join(p.Elements, sep)
+	if len(p.Elements) == 0 && !p.Rooted && !strings.HasPrefix(p.Volume, `\\`) {
+		// the current directory, possibly on a given drive (e.g., "C:.")
+		s += "."
+	}
+	return s
+}
@@ -0,0 +1,54 @@
+package wslpath
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	for _, c := range []struct {
+		f    Format
+		in   string
+		want ParsedPath
+	}{
+		{Windows, `C:\Users\me`, ParsedPath{Windows, "C:", true, true, []string{"Users", "me"}}},
+		{Windows, `C:foo\bar`, ParsedPath{Windows, "C:", false, false, []string{"foo", "bar"}}},
+		{Windows, `\\host\share\doc`, ParsedPath{Windows, `\\host\share`, true, true, []string{"doc"}}},
+		{Windows, `\foo`, ParsedPath{Windows, "", false, true, []string{"foo"}}},
+		{Any, `a\b`, ParsedPath{Windows, "", false, false, []string{"a", "b"}}},
+		{Any, "/mnt/c/x/", ParsedPath{Unix, "", true, true, []string{"mnt", "c", "x"}}},
+		{Unix, "a/./b", ParsedPath{Unix, "", false, false, []string{"a", "b"}}},
+	} {
+		if got, err := c.f.Parse(c.in); err != nil || !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s.Parse(%q) = %+v, %v; want %+v", c.f, c.in, got, err, c.want)
+		}
+	}
+	for _, s := range []string{"", `C:D:\foo`} {
+		if got, err := Windows.Parse(s); CodeOf(err) != ErrInvalidPath {
+			t.Errorf("Parse(%q) = %+v, %v; want %s", s, got, err, ErrInvalidPath)
+		}
+	}
+}
+
+func TestParseString(t *testing.T) {
+	for _, c := range []struct {
+		f  Format
+		in string
+	}{
+		{Windows, `C:\Users\me\`},
+		{Windows, `C:foo`},
+		{Windows, `C:`},
+		{Windows, `C:\`},
+		{Windows, `\\host\share\doc`},
+		{Windows, `a\..\b`},
+		{Windows, `.`},
+		{Unix, "/"},
+		{Unix, "/mnt//c/./x"},
+		{Unix, "../a"},
+	} {
+		p, err := c.f.Parse(c.in)
+		if want := c.f.Clean(c.in); err != nil || p.String() != want {
+			t.Errorf("%s.Parse(%q).String() = %q, %v; want %q", c.f, c.in, p.String(), err, want)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package wslpath
+
+import "strings"
+
+// ListSeparator returns the separator of path list entries (e.g., the PATH
+// environment variable) in the receiver Format f.
+func (f Format) ListSeparator() string {
+	if Windows == f {
+		return ";"
+	}
+	return ":"
+}
+
+// FormatList converts each entry of the given path list s from Format f to
+// Format t, returning the converted entries joined by the list separator of t.
+//
+// Each entry is identified individually, and entries already in Format t are
+// copied unchanged. If drop is true, entries that cannot be converted without
+// falling back to the WSL rootfs (e.g., "/usr/bin") are omitted from the result
+// instead of converted, but any other error is still returned; otherwise, x is
+// passed to Format for each entry. Empty entries are preserved.
+func (r *Resolver) FormatList(f, t Format, s string, x, drop bool) (string, error) {
+	return r.FormatListSep(f, t, s, f.ListSeparator(), x, drop)
+}
+
+// FormatListSep is like FormatList, but the entries of the given path list s
+// are separated by sep instead of the list separator of Format f (e.g., a
+// ";"-separated list of Unix paths on a single line of a log file). The
+// converted entries are still joined by the list separator of Format t.
+func (r *Resolver) FormatListSep(f, t Format, s, sep string, x, drop bool) (string, error) {
+	var list []string
+	for _, e := range strings.Split(s, sep) {
+		if "" == e || t == Identify(e) {
+			list = append(list, e)
+			continue
+		}
+		p, _, err := r.Format(f, t, e, x || drop, 0)
+		if nil != err {
+			// only entries without a volume mapping are dropped; any other
+			// error (e.g., a malformed entry) is not merely unconvertible.
+			if drop && ErrNoMapping == CodeOf(err) {
+				continue
+			}
+			return "", err
+		}
+		list = append(list, p)
+	}
+	return strings.Join(list, t.ListSeparator()), nil
+}
@@ -0,0 +1,68 @@
+package wslpath
+
+import "testing"
+
+func TestFormatList(t *testing.T) {
+	isolate(t)
+	t.Setenv(WslRootfsEnvVar, `\\wsl$\Ubuntu`)
+	r := newResolver()
+	r.MapDrive('C', "/mnt/c")
+	const path = "/usr/local/bin:/usr/bin:/mnt/c/Windows/system32:/mnt/c/Windows:" +
+		"/mnt/c/Program Files/Git/cmd::/home/me/.local/bin"
+	for _, c := range []struct {
+		drop bool
+		want string
+	}{
+		{false, `\\wsl$\Ubuntu\usr\local\bin;\\wsl$\Ubuntu\usr\bin;C:\Windows\system32;C:\Windows;` +
+			`C:\Program Files\Git\cmd;;\\wsl$\Ubuntu\home\me\.local\bin`},
+		{true, `C:\Windows\system32;C:\Windows;C:\Program Files\Git\cmd;`},
+	} {
+		if got, err := r.FormatList(Unix, Windows, path, false, c.drop); err != nil || got != c.want {
+			t.Errorf("FormatList(drop=%t) = %q, %v; want %q", c.drop, got, err, c.want)
+		}
+	}
+	// only entries found solely in the rootfs are dropped, not malformed ones
+	for _, c := range []struct {
+		f, t Format
+		in   string
+		code ErrorCode
+	}{
+		{Unix, Windows, "/usr/bin:/mnt/c/a\xffb", ErrInvalidPath},
+		{Windows, Unix, `C:\x;Z:\x`, ErrEnvNotSet},
+	} {
+		if got, err := r.FormatList(c.f, c.t, c.in, false, true); CodeOf(err) != c.code {
+			t.Errorf("FormatList(%q, drop) = %q, %v; want %s", c.in, got, err, c.code)
+		}
+	}
+	// without drop, x applies to each entry
+	if got, err := r.FormatList(Unix, Windows, path, true, false); err == nil {
+		t.Errorf("FormatList(x) = %q; want error", got)
+	}
+	// entries already in the target Format are copied unchanged
+	const win = `C:\Windows;/mnt/c/x;%SystemRoot%\system32`
+	if got, err := r.FormatList(Windows, Unix, win, false, false); err != nil || got != "/mnt/c/Windows:/mnt/c/x:%SystemRoot%/system32" {
+		t.Errorf("FormatList(%q) = %q, %v", win, got, err)
+	}
+}
+
+func TestFormatListSep(t *testing.T) {
+	isolate(t)
+	r := newResolver()
+	r.MapDrive('C', "/mnt/c")
+	r.MapDrive('D', "/mnt/d")
+	for _, c := range []struct {
+		f, t    Format
+		in, sep string
+		want    string
+	}{
+		{Windows, Unix, `C:\a;D:\b`, ";", "/mnt/c/a:/mnt/d/b"},
+		{Windows, Unix, `C:\a|D:\b|`, "|", "/mnt/c/a:/mnt/d/b:"},
+		{Unix, Windows, "/mnt/c/a;/mnt/d/b", ";", `C:\a;D:\b`},
+		{Unix, Windows, "/mnt/c/a, /mnt/d/b", ", ", `C:\a;D:\b`},
+		{Windows, Unix, `C:\a`, ";", "/mnt/c/a"},
+	} {
+		if got, err := r.FormatListSep(c.f, c.t, c.in, c.sep, false, false); err != nil || got != c.want {
+			t.Errorf("FormatListSep(%q, %q) = %q, %v; want %q", c.in, c.sep, got, err, c.want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package wslpath
+
+import (
+	"os"
+	"strings"
+)
+
+// RealCase returns the given Unix file path s with each path element replaced
+// by the case of its actual name on disk. This is useful for file systems that
+// are case-insensitive (e.g., Windows volumes mounted with drvfs), where the
+// given path may refer to an existing file using different case.
+//
+// Path elements are resolved from left to right. Each element found verbatim
+// is kept. Otherwise, its parent directory is searched for a single entry that
+// differs only in case. Once an element cannot be resolved, it and all
+// remaining elements are kept unchanged.
+func RealCase(s string) string {
+	e := Unix.Elements(s)
+	dir := "."
+	if Unix.IsAbs(s) {
+		dir = "/"
+	}
+	for i, name := range e {
+		if name == "" || name == "." || name == ".." {
+			dir = Unix.join(dir, name)
+			continue
+		}
+		real, ok := lookupFold(dir, name)
+		if !ok {
+			break
+		}
+		e[i] = real
+		dir = Unix.join(dir, real)
+	}
+	return strings.Join(e, string(Unix.sep()))
+}
+
+// lookupFold returns the name of the entry in directory dir matching the given
+// name, preferring an exact match, or otherwise a unique case-insensitive
+// match. The returned bool is false if no unique match exists.
+func lookupFold(dir, name string) (string, bool) {
+	if _, err := os.Lstat(Unix.join(dir, name)); err == nil {
+		// the name may exist verbatim only because the file system is
+		// case-insensitive; keep searching for its real case.
+		if !foldable(name) {
+			return name, true
+		}
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return name, false
+	}
+	defer d.Close()
+	names, err := d.Readdirnames(-1)
+	if err != nil {
+		return name, false
+	}
+	match, n := name, 0
+	for _, m := range names {
+		if m == name {
+			return m, true
+		}
+		if strings.EqualFold(m, name) {
+			match, n = m, n+1
+		}
+	}
+	return match, n == 1
+}
+
+// foldable returns true if and only if the given string contains any character
+// with distinct upper and lower case forms.
+func foldable(s string) bool {
+	return strings.ToUpper(s) != strings.ToLower(s)
+}
+
+// join returns the concatenation of the given directory and file name, using
+// the receiver Format f's directory separator.
+func (f Format) join(dir, name string) string {
+	if len(dir) > 0 && f.issep(rune(dir[len(dir)-1])) {
+		return dir + name
+	}
+	return dir + string(f.sep()) + name
+}
@@ -0,0 +1,40 @@
+package wslpath
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRealCase(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "Users", "Me", "Documents"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"Report.TXT", "dup", "DUP"} {
+		f, err := os.Create(filepath.Join(dir, "Users", "Me", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+	}
+	for _, c := range []struct{ in, want string }{
+		{"/users/me/documents", "/Users/Me/Documents"},
+		{"/USERS/ME/report.txt", "/Users/Me/Report.TXT"},
+		{"/Users/Me/Report.TXT", "/Users/Me/Report.TXT"},
+		// elements that do not exist, and all following, are kept
+		{"/users/me/missing/documents", "/Users/Me/missing/documents"},
+		// as are elements with more than one case-insensitive match
+		{"/users/me/Dup", "/Users/Me/Dup"},
+		{"/users/me/dup", "/Users/Me/dup"},
+		{"/users/me/./documents/..", "/Users/Me/./Documents/.."},
+	} {
+		if got := RealCase(dir + c.in); got != dir+c.want {
+			t.Errorf("RealCase(%q) = %q; want %q", dir+c.in, got, dir+c.want)
+		}
+	}
+	t.Chdir(dir)
+	if got := RealCase("users/me"); got != "Users/Me" {
+		t.Errorf("RealCase(%q) = %q; want %q", "users/me", got, "Users/Me")
+	}
+}
@@ -0,0 +1,25 @@
+package wslpath
+
+// SetDriveRemap declares that the given drive letter is replaced with the
+// given drive letter to in Windows paths translated from Unix paths.
+func (r *Resolver) SetDriveRemap(drive, to byte) {
+	if r.DriveRemaps == nil {
+		r.DriveRemaps = map[byte]byte{}
+	}
+	r.DriveRemaps[upper(drive)] = upper(to)
+}
+
+// remapDrive returns the given Windows path s with its drive letter replaced
+// according to the receiver Resolver r's DriveRemaps. Paths that do not begin
+// with a remapped drive, including UNC paths, are returned unchanged.
+func (r *Resolver) remapDrive(s string) string {
+	v, _ := Windows.SplitVolume(s)
+	if len(v) != 2 || v[1] != ':' {
+		return s
+	}
+	to, ok := r.DriveRemaps[upper(v[0])]
+	if !ok {
+		return s
+	}
+	return string(to) + s[1:]
+}
@@ -0,0 +1,34 @@
+package wslpath
+
+import "testing"
+
+func TestDriveRemap(t *testing.T) {
+	isolate(t)
+	t.Setenv(WslRootfsEnvVar, `\\wsl$\Ubuntu`)
+	r := newResolver()
+	r.MapDrive('C', "/mnt/c")
+	r.MapDrive('E', "/mnt/e")
+	r.MapDrive('F', "/mnt/f")
+	r.MapUNC(`\\host\share`, "/mnt/share")
+	r.SetDriveRemap('c', 'd')
+	r.SetDriveRemap('E', 'C')
+	for _, c := range []struct {
+		f, t     Format
+		in, want string
+	}{
+		{Unix, Windows, "/mnt/c/Users/me", `D:\Users\me`},
+		{Unix, Windows, "/mnt/c", `D:\`},
+		// remaps are not chained
+		{Unix, Windows, "/mnt/e/x", `C:\x`},
+		// non-matching drives and other volumes are untouched
+		{Unix, Windows, "/mnt/f/x", `F:\x`},
+		{Unix, Windows, "/mnt/share/x", `\\host\share\x`},
+		{Unix, Windows, "/etc", `\\wsl$\Ubuntu\etc`},
+		// only Windows output is remapped
+		{Windows, Unix, `C:\x`, "/mnt/c/x"},
+	} {
+		if got, _, err := r.Format(c.f, c.t, c.in, false, 0); err != nil || got != c.want {
+			t.Errorf("Format(%q) = %q, %v; want %q", c.in, got, err, c.want)
+		}
+	}
+}
@@ -0,0 +1,637 @@
+package wslpath
+
+import (
+	"os"
+	"strings"
+	"sync"
+)
+
+// Resolver holds the configuration used to associate Windows volumes with their
+// mount points in the WSL user space. The zero value is ready to use and
+// consults only the conventional environment variables described by
+// NixPathEnvSuffix, UncPathEnvVar, and WslRootfsEnvVar.
+//
+// A Resolver is safe for concurrent use by multiple goroutines translating file
+// paths (e.g., with Format), provided its configuration is not modified
+// concurrently: its fields must not be assigned, and its Set and Map methods
+// must not be called, while paths are being translated. Its Timing, if
+// defined, is not safe for concurrent use. A Resolver must not be copied after
+// first use.
+type Resolver struct {
+	// DriveVars maps an uppercase drive letter to an ordered list of
+	// environment variable identifiers holding that drive's mount point.
+	// The first variable defined in the environment is used. Drives without
+	// an entry use the single identifier constructed from the drive letter
+	// and NixPathEnvSuffix.
+	DriveVars map[byte][]string
+
+	// PrefixMaps defines arbitrary path prefix rewriting rules, which take
+	// precedence over all volume mappings defined in the environment.
+	PrefixMaps []PrefixMap
+
+	// RelativeRoots defines path prefix reanchoring rules, which are applied
+	// only from the Format of From to the Format of To (e.g., paths under
+	// "/mnt/c/proj" are reanchored under `D:\build`, but not the reverse).
+	// They take precedence over PrefixMaps.
+	RelativeRoots []PrefixMap
+
+	// Expand enables the expansion of environment variable references
+	// (e.g., "%SystemDrive%") in Windows paths, and anchors Windows paths
+	// that are rooted but have no volume (e.g., "\Windows") to the system
+	// drive.
+	Expand bool
+
+	// Vars maps an uppercase Windows environment variable identifier to the
+	// value used when Expand is enabled, overriding the environment.
+	Vars map[string]string
+
+	// SystemDrive overrides the Windows system drive (e.g., "C:") used when
+	// Expand is enabled.
+	SystemDrive string
+
+	// VolumeGUIDs maps a lowercase volume GUID (without braces) to the drive
+	// letter (e.g., "C:") to which that volume is mounted.
+	VolumeGUIDs map[string]string
+
+	// LookupVolumeGUID returns the drive letter to which the volume with the
+	// given GUID is mounted, for volumes not found in VolumeGUIDs. If nil,
+	// the Windows mountvol utility is consulted via WSL interop.
+	LookupVolumeGUID func(guid string) (string, error)
+
+	// DriveCwds maps an uppercase drive letter to the current directory on
+	// that drive (e.g., `C:\Users\me`), which anchors drive-relative paths
+	// such as "C:foo".
+	DriveCwds map[byte]string
+
+	// LookupDriveCwd returns the current directory on the given drive, for
+	// drives not found in DriveCwds. If nil, the Windows command interpreter
+	// is consulted via WSL interop.
+	LookupDriveCwd func(drive byte) (string, error)
+
+	// Substs maps an uppercase drive letter to the Windows path targeted by
+	// that virtual drive, created with the Windows subst utility. If nil, the
+	// subst utility is consulted via WSL interop.
+	Substs map[byte]string
+
+	// ResolveSubst enables the replacement of subst drives with their target
+	// before volume mapping. Otherwise, subst drives are mapped like any
+	// other drive.
+	ResolveSubst bool
+
+	// Root, if defined, is the directory treated as the root of the file
+	// system when inspecting absolute Unix paths (e.g., a mounted image of a
+	// WSL file system). Paths are matched against volume mappings as seen
+	// from within Root.
+	Root string
+
+	// VolumeStyle selects how drives are addressed in Windows paths produced
+	// from Unix paths.
+	VolumeStyle VolumeStyle
+
+	// Volume, if defined, is the Windows volume or directory (e.g., "D:",
+	// `\\host\share`, or `\\host\share\dir`) to which Windows paths that
+	// have no volume are anchored. It takes precedence over InferVolume.
+	Volume string
+
+	// Base, if defined, is the directory (e.g., "/home/me/proj" or
+	// `C:\proj`) against which relative paths are resolved instead of the
+	// current working directory. Base applies only to paths of its own Format,
+	// so it must be given in the Format of the paths translated from. Relative
+	// paths resolved against Base are translated to absolute paths. Base takes
+	// precedence over Volume and InferVolume.
+	Base string
+
+	// InferVolume enables anchoring Windows paths that have no volume (e.g.,
+	// `sub\file` or `\Windows`) to the current working directory, if it lies
+	// on a mounted Windows volume.
+	InferVolume bool
+
+	// Absolute enables translating relative paths to absolute paths, as with
+	// cygpath -a. Relative Unix paths are anchored to the current working
+	// directory (or Base) and always returned absolute, and Windows paths that
+	// have no volume are anchored as with InferVolume.
+	Absolute bool
+
+	// MountCaseInsensitive enables case-insensitive matching of mount points
+	// in Unix paths (e.g., "/MNT/C/x" matches "/mnt/c"), as found on drvfs
+	// mounts. The case of the remainder of the path is preserved.
+	MountCaseInsensitive bool
+
+	// RelativeTextual disables anchoring relative Unix paths to the current
+	// working directory when translating them to Windows paths, so that they
+	// are always translated textually (e.g., "a/b/c" to `a\b\c`) without
+	// inspecting the file system, even if they would not resolve to a mounted
+	// Windows volume.
+	RelativeTextual bool
+
+	// Logical disables resolving symbolic links in Unix paths, so that a path
+	// through a symbolic link (e.g., to a mounted Windows volume) is
+	// translated as given, rather than as the path of the link target.
+	Logical bool
+
+	// ExtendedLength enables prefixing Windows paths translated from Unix
+	// paths with ExtendedPrefix (e.g., `\\?\C:\path`) if their length exceeds
+	// MaxPath, so that legacy Windows APIs accept them.
+	ExtendedLength bool
+
+	// DriveRemaps maps an uppercase drive letter to the uppercase drive letter
+	// by which it is replaced in Windows paths translated from Unix paths, as
+	// a final step (e.g., when a drive mapped as "C:" in WSL is known as "D:"
+	// on the Windows host where the paths are used).
+	DriveRemaps map[byte]byte
+
+	// TrailingSep enables preserving a trailing separator of a translated
+	// path (e.g., "/mnt/c/Users/" to `C:\Users\`), which conventionally
+	// denotes a directory. Root directories are unaffected.
+	TrailingSep bool
+
+	// StrictMatch enables reporting an error when the mount points of two
+	// different drives equally match a Unix path (e.g., two drives mounted at
+	// the same directory), rather than arbitrarily choosing either drive.
+	StrictMatch bool
+
+	// SepReplacer translates the directory separators of a path (never
+	// including its volume) from one Format to another. If nil, ReplaceSep
+	// is used. See StructuralSep for an alternative that preserves literal
+	// separator characters within path elements.
+	SepReplacer func(f, t Format, s string) string
+
+	// RootfsHost, if defined, is the UNC host (e.g., "wsl$" or
+	// "wsl.localhost") used to address the WSL virtual rootfs in Windows
+	// paths, with the share named by DistroEnvVar (e.g., `\\wsl$\Ubuntu`),
+	// instead of the path held by WslRootfsEnvVar.
+	RootfsHost string
+
+	// UNCMountRoot, if defined, is the directory (e.g., "/mnt") under which
+	// UNC volumes that are not mapped in the environment are conventionally
+	// mounted, such that `\\host\share` translates to "/mnt/host/share".
+	// Explicit UNC mappings always take precedence. Unix paths are never
+	// translated to UNC volumes by this convention.
+	UNCMountRoot string
+
+	// MountTable is the path of the mount table (e.g., "/proc/mounts") in
+	// which drvfs mounts are found for drives whose mount point is not
+	// defined in the environment. If empty, DefaultMountTable is used.
+	MountTable string
+
+	// MaxDotDot limits the number of ".." elements permitted in a file path,
+	// which guards Clean against pathological inputs. If zero, the limit is
+	// DefaultMaxDotDot. If negative, no limit is enforced.
+	MaxDotDot int
+
+	// Timing, if defined, accumulates the time spent inspecting the file
+	// system while translating file paths.
+	Timing *Timing
+
+	// Trace, if defined, receives a TraceEvent for each step performed while
+	// translating a file path.
+	Trace func(TraceEvent)
+
+	// drives and uncs hold volume mappings given explicitly (e.g., from a
+	// map file), which take precedence over the environment.
+	drives []mount
+	uncs   []uncMapping
+
+	// mu guards the state cached while translating file paths: the mount
+	// table, and the results of lookups by way of WSL interop.
+	mu sync.Mutex
+
+	// table caches the entries of the mount table, and tabled is true once
+	// the mount table has been read.
+	table  []tableEntry
+	tabled bool
+
+	// cwds and guids cache the current directories of drives not found in
+	// DriveCwds, and the drive letters of volumes not found in VolumeGUIDs,
+	// as found by LookupDriveCwd and LookupVolumeGUID, respectively.
+	cwds  map[byte]string
+	guids map[string]string
+
+	// substs caches the virtual drives reported by the Windows subst utility
+	// if Substs is undefined. The utility is invoked only once, guarded by
+	// substOnce rather than mu.
+	substs    map[byte]string
+	substOnce sync.Once
+
+	// slow disables the automount fast path, so that tests can compare its
+	// results with those of the general path.
+	slow bool
+}
+
+// mount associates a drive letter with its mount point in WSL user space.
+type mount struct {
+	drive byte
+	path  string
+	key   string
+}
+
+// DefaultMaxDotDot is the default limit on the number of ".." elements
+// permitted in a file path, far beyond the depth of any real file system.
+const DefaultMaxDotDot = 4096
+
+// uncMapping associates a UNC volume, with optional path, with its mount point
+// in WSL user space.
+type uncMapping struct {
+	volume string
+	path   string
+	key    string
+}
+
+// AutomountRoot is the default directory in which WSL mounts each drive, using
+// the lowercase drive letter as mount point name (e.g., "/mnt/c").
+const AutomountRoot = "/mnt"
+
+// PrefixMap associates a path prefix in one Format with a path prefix in
+// another. Paths matching either prefix are translated by replacing the
+// matched prefix with the other, regardless of any volume conventions.
+type PrefixMap struct {
+	From, To string
+}
+
+// DefaultResolver is the Resolver used by Format.Format. Like any Resolver, it
+// is safe for concurrent use, provided it is configured beforehand.
+var DefaultResolver = &Resolver{}
+
+// SetDriveVars defines the ordered list of environment variable identifiers
+// consulted for the mount point of the given drive letter.
+func (r *Resolver) SetDriveVars(drive byte, vars ...string) {
+	if r.DriveVars == nil {
+		r.DriveVars = map[byte][]string{}
+	}
+	r.DriveVars[upper(drive)] = vars
+}
+
+// driveVars returns the ordered list of environment variable identifiers
+// consulted for the mount point of the given drive letter.
+func (r *Resolver) driveVars(drive byte) []string {
+	if v, ok := r.DriveVars[upper(drive)]; ok && len(v) > 0 {
+		return v
+	}
+	return []string{string(upper(drive)) + NixPathEnvSuffix}
+}
+
+// lookupDrive returns the mount point of the given drive letter from the first
+// environment variable defined in its list of identifiers, along with the
+// identifier of that variable. If none are defined, the drive's drvfs mount
+// point found in the mount table is returned. Otherwise, the returned error
+// names every identifier searched.
+func (r *Resolver) lookupDrive(drive byte) (string, string, error) {
+	for _, m := range r.drives {
+		if m.drive == upper(drive) {
+			return m.path, m.key, nil
+		}
+	}
+	vars := r.driveVars(drive)
+	for _, e := range vars {
+		if dp, ok := os.LookupEnv(e); ok {
+			// cleaned as in driveMounts, so that both directions agree on
+			// the mount point (e.g., "/mnt/c/" is "/mnt/c").
+			if dp != "" {
+				dp = Unix.Clean(dp)
+			}
+			return dp, e, nil
+		}
+	}
+	if m, ok := r.tableMount(drive); ok {
+		return m.path, m.key, nil
+	}
+	return "", "", errorf(ErrEnvNotSet, "environment variable not set: %s",
+		strings.Join(vars, ", "))
+}
+
+// driveMounts returns the drive mount points given explicitly and defined in
+// the environment, in order of precedence, and true if any volume mappings
+// (drive or UNC) are defined. The environment is scanned on each call, so that
+// changes to the environment are always observed.
+func (r *Resolver) driveMounts() ([]mount, bool) {
+	mounts := append([]mount{}, r.drives...)
+	for _, e := range os.Environ() {
+		n := strings.IndexRune(e, '=')
+		if (-1 != n) && (len(e) > n+1) {
+			k, v := e[:n], Unix.Clean(e[n+1:])
+			if d, ok := r.driveOf(k); ok {
+				mounts = append(mounts, mount{drive: d, path: v, key: k})
+			}
+		}
+	}
+	_, unc := os.LookupEnv(UncPathEnvVar)
+	return mounts, unc || len(mounts) > 0 || len(r.uncs) > 0
+}
+
+// configured returns true if and only if any volume mapping (drive or UNC) is
+// defined in the environment. Only the identifiers that may hold a mapping are
+// looked up, so the environment is never scanned in full.
+func (r *Resolver) configured() bool {
+	if len(r.drives) > 0 || len(r.uncs) > 0 {
+		return true
+	}
+	if _, ok := os.LookupEnv(UncPathEnvVar); ok {
+		return true
+	}
+	set := func(key string) bool {
+		v, ok := os.LookupEnv(key)
+		return ok && v != ""
+	}
+	for _, vars := range r.DriveVars {
+		for _, e := range vars {
+			if set(e) {
+				return true
+			}
+		}
+	}
+	for d := byte('A'); d <= 'Z'; d++ {
+		if set(string(d) + NixPathEnvSuffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// MapDrive associates the given drive letter with a mount point, overriding
+// any mapping defined in the environment.
+func (r *Resolver) MapDrive(drive byte, path string) {
+	r.mapDrive(drive, path, "map")
+}
+
+func (r *Resolver) mapDrive(drive byte, path, key string) {
+	m := mount{drive: upper(drive), path: Unix.Clean(path), key: key}
+	for i := range r.drives {
+		if r.drives[i].drive == m.drive {
+			r.drives[i] = m
+			return
+		}
+	}
+	r.drives = append(r.drives, m)
+}
+
+// MapUNC associates the given UNC volume, with optional path (e.g.,
+// "\\host\share\dir"), with a mount point, overriding any mapping defined in
+// the environment.
+func (r *Resolver) MapUNC(volume, path string) {
+	r.mapUNC(volume, path, "map")
+}
+
+func (r *Resolver) mapUNC(volume, path, key string) {
+	u := uncMapping{volume: Windows.Clean(volume), path: Unix.Clean(path), key: key}
+	for i := range r.uncs {
+		if strings.EqualFold(r.uncs[i].volume, u.volume) {
+			r.uncs[i] = u
+			return
+		}
+	}
+	r.uncs = append(r.uncs, u)
+}
+
+// uncMappings returns the UNC volume mappings given explicitly and defined in
+// the environment, in order of precedence. Invalid entries in the environment
+// are ignored, as are those of a volume that is mapped explicitly.
+func (r *Resolver) uncMappings() []uncMapping {
+	u := append([]uncMapping{}, r.uncs...)
+	if up, ok := os.LookupEnv(UncPathEnvVar); ok {
+		env, _ := parseUNCMappings(strings.Split(up, `;`), func(int) string {
+			return UncPathEnvVar
+		})
+		for _, e := range env {
+			if !r.mapsUNC(e.volume) {
+				u = append(u, e)
+			}
+		}
+	}
+	return u
+}
+
+// mapsUNC returns true if and only if the given UNC volume is mapped
+// explicitly in the receiver Resolver r.
+func (r *Resolver) mapsUNC(volume string) bool {
+	for _, m := range r.uncs {
+		if strings.EqualFold(m.volume, volume) {
+			return true
+		}
+	}
+	return false
+}
+
+// lookupUNC returns the mapping whose UNC volume (and path) is the longest
+// prefix of the given Windows path s, along with the remainder of s. The
+// returned bool is false if no mapping matches.
+func (r *Resolver) lookupUNC(s string) (uncMapping, string, bool) {
+	var m uncMapping
+	var rest string
+	n := -1
+	for _, u := range r.uncMappings() {
+		if p, ok := Windows.trimPrefix(s, u.volume); ok && len(u.volume) > n {
+			m, rest, n = u, p, len(u.volume)
+		}
+	}
+	return m, rest, n >= 0
+}
+
+// uncMount returns the mount point of the given UNC volume v (e.g.,
+// `\\host\share`) by convention under UNCMountRoot (e.g., "/mnt/host/share"),
+// and false if UNCMountRoot is undefined.
+func (r *Resolver) uncMount(v string) (string, bool) {
+	if r.UNCMountRoot == "" {
+		return "", false
+	}
+	hs := r.replaceSep(Windows, Unix, strings.TrimLeft(v, `\`))
+	return Unix.Clean(r.UNCMountRoot + string(Unix.sep()) + hs), true
+}
+
+// reverseUNC returns the mapping whose mount point is the longest prefix of
+// the given Unix path s, along with the remainder of s. The returned bool is
+// false if no mapping matches.
+func (r *Resolver) reverseUNC(s string) (uncMapping, string, bool) {
+	var m uncMapping
+	var rest string
+	n := -1
+	for _, u := range r.uncMappings() {
+		if p, ok := r.trimMount(s, u.path); ok && len(u.path) > n {
+			m, rest, n = u, p, len(u.path)
+		}
+	}
+	return m, rest, n >= 0
+}
+
+// automount returns the drvfs mount of the drive in the default AutomountRoot
+// under which the given absolute Unix path s lies, named by its lowercase drive
+// letter (e.g., "/mnt/c"), along with the remainder of s. The returned bool is
+// false unless that drive is mounted there according to the mount table, no
+// other drvfs mount in the mount table better matches s, and no volume
+// mappings are configured that could otherwise take precedence.
+//
+// This is a fast path for the overwhelmingly common case in an unconfigured
+// environment, which bypasses the environment scan, but produces the same
+// result as the general path.
+func (r *Resolver) automount(s string) (mount, string, bool) {
+	n := len(AutomountRoot) + 2
+	if r.slow || len(s) < n || s[n-1] < 'a' || 'z' < s[n-1] {
+		return mount{}, s, false
+	}
+	if len(s) > n && s[n] != '/' {
+		return mount{}, s, false
+	}
+	if _, ok := r.trimMount(s[:n-1], AutomountRoot+"/"); !ok {
+		return mount{}, s, false
+	}
+	if r.configured() {
+		return mount{}, s, false
+	}
+	var dm mount
+	var rest string
+	for _, m := range r.tableMounts() {
+		if p, ok := r.trimMount(s, m.path); ok && len(m.path) > len(dm.path) {
+			dm, rest = m, p
+		}
+	}
+	if dm.drive != upper(s[n-1]) || len(dm.path) != n {
+		return mount{}, s, false
+	}
+	return dm, rest, true
+}
+
+// trimMount returns the given Unix path s with the given mount point prefix
+// removed, and true if and only if prefix matches s on a path element boundary,
+// case-insensitively if MountCaseInsensitive is enabled.
+func (r *Resolver) trimMount(s, prefix string) (string, bool) {
+	return Unix.trimPrefixFold(s, prefix, r.MountCaseInsensitive)
+}
+
+// driveOf returns the drive letter whose mount point is held by the given
+// environment variable identifier, and false if the identifier is not
+// associated with any drive. Besides the identifiers of DriveVars, only an
+// uppercase drive letter followed by NixPathEnvSuffix (e.g., "C_VOLUME_PATH")
+// names a drive; identifiers such as "DATA_VOLUME_PATH" name no drive at all.
+func (r *Resolver) driveOf(key string) (byte, bool) {
+	for d, vars := range r.DriveVars {
+		for _, e := range vars {
+			if e == key {
+				return d, true
+			}
+		}
+	}
+	if len(key) == 1+len(NixPathEnvSuffix) && 'A' <= key[0] && key[0] <= 'Z' &&
+		strings.HasSuffix(key, NixPathEnvSuffix) {
+		return key[0], true
+	}
+	return 0, false
+}
+
+// checkDotDot returns an error if the given path s in Format f contains more
+// ".." elements than permitted by the receiver Resolver r's MaxDotDot.
+func (r *Resolver) checkDotDot(f Format, s string) error {
+	max := r.MaxDotDot
+	if max == 0 {
+		max = DefaultMaxDotDot
+	}
+	if max < 0 {
+		return nil
+	}
+	n := 0
+	for _, e := range f.Elements(s) {
+		if e == ".." {
+			if n++; n > max {
+				if len(s) > 64 {
+					s = s[:61] + "..."
+				}
+				return errorf(ErrInvalidPath, "too many \"..\" elements (limit %d): %s", max, s)
+			}
+		}
+	}
+	return nil
+}
+
+// rootfs returns the Windows path to the WSL virtual rootfs defined in the
+// environment, and false if it is undefined. If RootfsHost is defined, and the
+// active distribution is named in the environment, the UNC path of that
+// distribution's share on RootfsHost is returned instead.
+func (r *Resolver) rootfs() (string, bool) {
+	if r.RootfsHost != "" {
+		if d, ok := os.LookupEnv(DistroEnvVar); ok && d != "" {
+			return `\\` + r.RootfsHost + `\` + d, true
+		}
+	}
+	up, ok := os.LookupEnv(WslRootfsEnvVar)
+	if !ok {
+		return "", false
+	}
+	// Remove trailing line delimiters in case of misconfiguration
+	// caused by subtle interop (e.g., calling reg.exe from WSL will
+	// leave a hard-to-detect carriage return \x0D in its output).
+	//
+	// It should be very unlikely that someone intentionally wanted
+	// a newline or carriage return at the very end of a file name.
+	return strings.TrimRight(up, "\r\n"), true
+}
+
+// fromRootfs returns the absolute Unix path of the given Windows path s if it
+// refers to a file in the WSL virtual rootfs, and false otherwise.
+func (r *Resolver) fromRootfs(s string) (string, bool) {
+	up, ok := r.rootfs()
+	if !ok || len(up) == 0 {
+		return s, false
+	}
+	p, ok := Windows.trimPrefix(s, Windows.Clean(up))
+	if !ok {
+		return s, false
+	}
+	p = r.replaceSep(Windows, Unix, p)
+	return Unix.Clean(string(Unix.sep()) + p), true
+}
+
+// mapPrefix translates the given path s in Format f to Format t using the
+// longest matching prefix among all of the receiver Resolver r's PrefixMaps.
+// Each PrefixMap is applied in whichever direction matches Formats f and t.
+// The returned bool is false if no PrefixMap matches s.
+func (r *Resolver) mapPrefix(f, t Format, s string) (string, bool) {
+	return r.matchPrefix(r.PrefixMaps, true, f, t, s)
+}
+
+// reanchor translates the given path s in Format f to Format t using the
+// longest matching prefix among all of the receiver Resolver r's
+// RelativeRoots, each applied only from the Format of From to that of To. The
+// returned bool is false if no RelativeRoot matches s.
+func (r *Resolver) reanchor(f, t Format, s string) (string, bool) {
+	return r.matchPrefix(r.RelativeRoots, false, f, t, s)
+}
+
+// matchPrefix translates the given path s in Format f to Format t by
+// replacing the longest matching prefix among the given PrefixMaps, each
+// applied in the reverse direction only if both is true.
+func (r *Resolver) matchPrefix(maps []PrefixMap, both bool, f, t Format, s string) (string, bool) {
+	if f == t || f == Any || t == Any {
+		return s, false
+	}
+	var to, rest string
+	n := -1
+	for _, m := range maps {
+		from, into := m.From, m.To
+		if both && Identify(from) != f {
+			from, into = into, from
+		}
+		if Identify(from) != f || Identify(into) != t {
+			continue
+		}
+		from = f.Clean(from)
+		if p, ok := f.trimPrefix(s, from); ok && len(from) > n {
+			to, rest, n = t.Clean(into), p, len(from)
+		}
+	}
+	if n < 0 {
+		return s, false
+	}
+	rest = r.replaceSep(f, t, rest)
+	return t.Clean(to + string(t.sep()) + rest), true
+}
+
+// upper returns the uppercase form of the given ASCII letter.
+func upper(c byte) byte {
+	if 'a' <= c && c <= 'z' {
+		return c - 'a' + 'A'
+	}
+	return c
+}
+
+// isalpha returns true if and only if the given byte is an ASCII letter.
+func isalpha(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
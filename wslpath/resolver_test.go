@@ -0,0 +1,662 @@
+package wslpath
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestConcurrentFormat(t *testing.T) {
+	isolate(t)
+	t.Setenv("C"+NixPathEnvSuffix, "/mnt/c")
+	r := newResolver()
+	r.LookupDriveCwd = func(byte) (string, error) { return `C:\work`, nil }
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			for n := 0; n < 100; n++ {
+				if p, _, err := r.Format(Unix, Windows, "/mnt/c/x", false, 0); err != nil || p != `C:\x` {
+					t.Errorf("Format(/mnt/c/x) = %q, %v", p, err)
+					return
+				}
+				if p, _, err := r.Format(Windows, Unix, `C:x`, false, 0); err != nil || p != "/mnt/c/work/x" {
+					t.Errorf("Format(C:x) = %q, %v", p, err)
+					return
+				}
+				r.tableMounts()
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+}
+
+func TestEnvironmentChange(t *testing.T) {
+	isolate(t)
+	dir := t.TempDir()
+	r := newResolver()
+	t.Setenv("D"+NixPathEnvSuffix, "/mnt/d")
+	if p, _, err := r.Format(Unix, Windows, dir+"/x", true, 0); err == nil {
+		t.Fatalf("Format(%s/x) = %q; want error", dir, p)
+	}
+	t.Setenv("D"+NixPathEnvSuffix, dir)
+	if p, _, err := r.Format(Unix, Windows, dir+"/x", true, 0); err != nil || p != `D:\x` {
+		t.Errorf("Format(%s/x) = %q, %v; want %q", dir, p, err, `D:\x`)
+	}
+}
+
+// automountPaths are Unix paths under the default automount root.
+var automountPaths = []string{
+	"/mnt/c", "/mnt/c/", "/mnt/c/Users/me", "/mnt/z/a b/c.txt",
+	"/mnt/d/./x/../y", "/mnt/c/..", "/mnt/c/../d/x", "/mnt/data/x",
+}
+
+func TestAutomountEquivalence(t *testing.T) {
+	isolate(t)
+	t.Setenv(WslRootfsEnvVar, `\\wsl$\Ubuntu`)
+	table := mountTable(t, testMounts)
+	fast := &Resolver{MountTable: table, Logical: true}
+	general := &Resolver{MountTable: table, Logical: true, slow: true}
+	for _, s := range automountPaths {
+		want, wwsl, werr := general.Format(Unix, Windows, s, false, 0)
+		got, gwsl, gerr := fast.Format(Unix, Windows, s, false, 0)
+		if got != want || gwsl != wwsl || (gerr == nil) != (werr == nil) {
+			t.Errorf("Format(%q) = %q, %t, %v; want %q, %t, %v", s, got, gwsl, gerr, want, wwsl, werr)
+		}
+	}
+}
+
+func TestAutomount(t *testing.T) {
+	isolate(t)
+	r := &Resolver{MountTable: mountTable(t, testMounts), Logical: true}
+	for _, c := range []struct {
+		in    string
+		drive byte
+		want  string
+	}{
+		{"/mnt/c", 'C', ""},
+		{"/mnt/c/x", 'C', "/x"},
+		{"/mnt/d/x", 'D', "/x"},
+		// not mounted, or not a drvfs mount of that drive
+		{"/mnt/z/x", 0, ""},
+		{"/mnt/wsl/x", 0, ""},
+		{"/mnt/C/x", 0, ""},
+		{"/mnt/cd/x", 0, ""},
+		{"/mnt/1/x", 0, ""},
+		{"/mnt", 0, ""},
+		{"/media/c/x", 0, ""},
+	} {
+		m, p, ok := r.automount(c.in)
+		if ok != (c.drive != 0) || (ok && (m.drive != c.drive || p != c.want)) {
+			t.Errorf("automount(%q) = %q, %q, %t; want %q, %q", c.in, m.drive, p, ok, c.drive, c.want)
+		}
+	}
+	// any configured mapping takes precedence
+	t.Setenv("D"+NixPathEnvSuffix, "/data")
+	if _, _, ok := r.automount("/mnt/c/x"); ok {
+		t.Error("automount(/mnt/c/x) = true with D" + NixPathEnvSuffix + " defined; want false")
+	}
+}
+
+func TestAutomountSymmetric(t *testing.T) {
+	isolate(t)
+	r := &Resolver{MountTable: mountTable(t, testMounts), Logical: true}
+	for _, c := range []struct {
+		f, t     Format
+		in, want string
+		code     ErrorCode
+	}{
+		{Unix, Windows, "/mnt/c/Users", `C:\Users`, ""},
+		{Windows, Unix, `C:\Users`, "/mnt/c/Users", ""},
+		{Unix, Windows, "/mnt/z/x", "", ErrNoMapping},
+		{Windows, Unix, `Z:\x`, "", ErrEnvNotSet},
+	} {
+		got, _, err := r.Format(c.f, c.t, c.in, true, 0)
+		if got != c.want || (err != nil) != (c.code != "") || (err != nil && CodeOf(err) != c.code) {
+			t.Errorf("Format(%s, %s, %q) = %q, %v; want %q, %q", c.f, c.t, c.in, got, err, c.want, c.code)
+		}
+	}
+}
+
+func BenchmarkAutomount(b *testing.B) {
+	isolate(b)
+	r := &Resolver{MountTable: mountTable(b, testMounts), Logical: true}
+	for i := 0; i < b.N; i++ {
+		r.Format(Unix, Windows, "/mnt/c/Users/me/file.txt", false, 0)
+	}
+}
+
+func BenchmarkGeneral(b *testing.B) {
+	isolate(b)
+	r := &Resolver{MountTable: mountTable(b, testMounts), Logical: true, slow: true}
+	for i := 0; i < b.N; i++ {
+		r.Format(Unix, Windows, "/mnt/c/Users/me/file.txt", false, 0)
+	}
+}
+
+func BenchmarkMapped(b *testing.B) {
+	isolate(b)
+	b.Setenv("C"+NixPathEnvSuffix, "/mnt/c")
+	r := newResolver()
+	for i := 0; i < b.N; i++ {
+		r.Format(Unix, Windows, "/mnt/c/Users/me/file.txt", false, 0)
+	}
+}
+
+func TestDriveVars(t *testing.T) {
+	isolate(t)
+	unsetenv(t, "C_MOUNT")
+	unsetenv(t, "WINDOWS_C")
+	dir := t.TempDir()
+	// a mapping of another drive disables the automount convention
+	t.Setenv("D"+NixPathEnvSuffix, dir+"/d")
+	r := newResolver()
+	r.SetDriveVars('c', "C"+NixPathEnvSuffix, "C_MOUNT", "WINDOWS_C")
+	if _, _, err := r.Format(Windows, Unix, `C:\x`, false, 0); CodeOf(err) != ErrEnvNotSet {
+		t.Errorf("Format(C:\\x) error = %v; want %s", err, ErrEnvNotSet)
+	} else if !strings.Contains(err.Error(), "C_MOUNT, WINDOWS_C") {
+		t.Errorf("Format(C:\\x) error = %q; want every identifier named", err)
+	}
+	t.Setenv("WINDOWS_C", dir+"/win/c")
+	t.Setenv("C_MOUNT", dir+"/data/c")
+	for _, c := range []struct {
+		f, t     Format
+		in, want string
+	}{
+		// the first candidate is unset, and the second provides the mapping
+		{Windows, Unix, `C:\x`, dir + "/data/c/x"},
+		{Unix, Windows, dir + "/data/c/x", `C:\x`},
+		{Unix, Windows, dir + "/win/c/x", `C:\x`},
+	} {
+		got, _, err := r.Format(c.f, c.t, c.in, true, 0)
+		if err != nil || got != c.want {
+			t.Errorf("Format(%q) = %q, %v; want %q", c.in, got, err, c.want)
+		}
+	}
+	t.Setenv("C"+NixPathEnvSuffix, "/mnt/c")
+	if got, _, err := r.Format(Windows, Unix, `C:\x`, true, 0); err != nil || got != "/mnt/c/x" {
+		t.Errorf("Format(C:\\x) = %q, %v; want %q", got, err, "/mnt/c/x")
+	}
+}
+
+func TestPrefixMap(t *testing.T) {
+	isolate(t)
+	dir := t.TempDir()
+	t.Setenv("C"+NixPathEnvSuffix, dir)
+	r := newResolver()
+	r.PrefixMaps = []PrefixMap{
+		{From: "/old/base", To: `C:\new\base`},
+		{From: "/old/base/deep", To: `D:\deep`},
+		{From: `E:\src`, To: "/workspace"},
+		{From: `E:\src\vendor`, To: "/vendor"},
+	}
+	for _, c := range []struct {
+		f, t     Format
+		in, want string
+	}{
+		{Unix, Windows, "/old/base", `C:\new\base`},
+		{Unix, Windows, "/old/base/x/y", `C:\new\base\x\y`},
+		// the longest matching prefix applies
+		{Unix, Windows, "/old/base/deep/x", `D:\deep\x`},
+		{Unix, Windows, "/old/base/deeper", `C:\new\base\deeper`},
+		{Windows, Unix, `E:\src\app\main.go`, "/workspace/app/main.go"},
+		{Windows, Unix, `e:\src\vendor\lib`, "/vendor/lib"},
+		// in either direction
+		{Windows, Unix, `D:\deep\x`, "/old/base/deep/x"},
+		{Unix, Windows, "/vendor/lib", `E:\src\vendor\lib`},
+		// prefixes match whole path elements only
+		{Unix, Windows, "/old/basement", ""},
+		{Unix, Windows, dir + "/old/base", `C:\old\base`},
+	} {
+		got, _, err := r.Format(c.f, c.t, c.in, true, 0)
+		if c.want == "" {
+			if err == nil {
+				t.Errorf("Format(%q) = %q; want error", c.in, got)
+			}
+		} else if err != nil || got != c.want {
+			t.Errorf("Format(%q) = %q, %v; want %q", c.in, got, err, c.want)
+		}
+	}
+}
+
+func TestMaxDotDot(t *testing.T) {
+	isolate(t)
+	deep := "/mnt/c" + strings.Repeat("/d", DefaultMaxDotDot+1) + strings.Repeat("/..", DefaultMaxDotDot+1) + "/x"
+	for _, c := range []struct {
+		max  int
+		in   string
+		want string
+	}{
+		{0, "/mnt/c/a/b/../../x", `C:\x`},
+		{0, deep, ""},
+		{-1, deep, `C:\x`},
+		{2, "/mnt/c/a/b/../../x", `C:\x`},
+		{2, "/mnt/c/a/b/c/../../../x", ""},
+		{2, `C:\a\b\c\..\..\..\x`, ""},
+	} {
+		t.Setenv("C"+NixPathEnvSuffix, "/mnt/c")
+		r := newResolver()
+		r.MaxDotDot = c.max
+		f, to := Identify(c.in), Windows
+		if f == Windows {
+			to = Unix
+		}
+		got, _, err := r.Format(f, to, c.in, true, 0)
+		if c.want == "" {
+			if CodeOf(err) != ErrInvalidPath {
+				t.Errorf("MaxDotDot=%d: Format(%.32q) = %q, %v; want %s", c.max, c.in, got, err, ErrInvalidPath)
+			}
+		} else if err != nil || got != c.want {
+			t.Errorf("MaxDotDot=%d: Format(%.32q) = %q, %v; want %q", c.max, c.in, got, err, c.want)
+		}
+	}
+}
+
+func TestRootfsPrecedence(t *testing.T) {
+	isolate(t)
+	t.Setenv(WslRootfsEnvVar, `\\wsl$\Ubuntu`)
+	t.Setenv("C"+NixPathEnvSuffix, "/mnt/c")
+	t.Setenv(UncPathEnvVar, `\\wsl$\Ubuntu=/mnt/wsl/ubuntu;\\server\share=/mnt/share`)
+	r := newResolver()
+	for _, c := range []struct{ in, want string }{
+		// the rootfs takes precedence over an overlapping UNC mapping
+		{`\\wsl$\Ubuntu\etc\hosts`, "/etc/hosts"},
+		{`\\wsl$\ubuntu\home`, "/home"},
+		{`\\wsl$\Ubuntu`, "/"},
+		// which still applies to volumes outside of the rootfs
+		{`\\server\share\x`, "/mnt/share/x"},
+		{`C:\x`, "/mnt/c/x"},
+	} {
+		got, _, err := r.Format(Windows, Unix, c.in, false, 0)
+		if err != nil || got != c.want {
+			t.Errorf("Format(%q) = %q, %v; want %q", c.in, got, err, c.want)
+		}
+	}
+	// the reverse translation is unaffected
+	if got, wsl, err := r.Format(Unix, Windows, "/mnt/wsl/ubuntu/x", false, 0); err != nil || got != `\\wsl$\Ubuntu\x` {
+		t.Errorf("Format(/mnt/wsl/ubuntu/x) = %q, %t, %v; want %q", got, wsl, err, `\\wsl$\Ubuntu\x`)
+	}
+}
+
+func TestMountCaseInsensitive(t *testing.T) {
+	isolate(t)
+	r := newResolver()
+	r.MapDrive('C', "/mnt/c")
+	r.MapUNC(`\\host\share`, "/mnt/Share")
+	for _, c := range []struct {
+		fold     bool
+		in, want string
+	}{
+		{false, "/mnt/c/Users/Me", `C:\Users\Me`},
+		{false, "/MNT/C/Users/Me", ""},
+		{false, "/mnt/share/x", ""},
+		// the remainder keeps its original case
+		{true, "/MNT/C/Users/Me", `C:\Users\Me`},
+		{true, "/Mnt/c", `C:\`},
+		{true, "/mnt/share/X", `\\host\share\X`},
+		{true, "/MNT/CD/x", ""},
+	} {
+		r.MountCaseInsensitive = c.fold
+		got, _, err := r.Format(Unix, Windows, c.in, true, 0)
+		if c.want == "" {
+			if err == nil {
+				t.Errorf("MountCaseInsensitive=%t: Format(%q) = %q; want error", c.fold, c.in, got)
+			}
+		} else if err != nil || got != c.want {
+			t.Errorf("MountCaseInsensitive=%t: Format(%q) = %q, %v; want %q", c.fold, c.in, got, err, c.want)
+		}
+	}
+}
+
+func TestNoRootfsFallback(t *testing.T) {
+	isolate(t)
+	t.Setenv(WslRootfsEnvVar, `\\wsl$\Ubuntu`)
+	r := newResolver()
+	r.MapDrive('C', "/mnt/c")
+	t.Chdir("/")
+	for _, c := range []struct {
+		in, want string
+	}{
+		{"/home/me", "no volume mapping; rootfs fallback disabled: /home/me"},
+		{"/", "Unix root has no Windows volume; rootfs fallback disabled: /"},
+		{"home/me", `no volume mapping for relative path "home/me" (resolved to /home/me); rootfs fallback disabled`},
+	} {
+		got, _, err := r.Format(Unix, Windows, c.in, true, 0)
+		if CodeOf(err) != ErrNoMapping || !strings.HasSuffix(err.Error(), c.want) {
+			t.Errorf("Format(%q) = %q, %v; want %s: %s", c.in, got, err, ErrNoMapping, c.want)
+		}
+	}
+	// without x, the same paths fall back on the rootfs
+	if got, _, err := r.Format(Unix, Windows, "home/me", false, 0); err != nil || got != `\\wsl$\Ubuntu\home\me` {
+		t.Errorf("Format(%q) = %q, %v; want %q", "home/me", got, err, `\\wsl$\Ubuntu\home\me`)
+	}
+}
+
+func TestRelativeTextual(t *testing.T) {
+	isolate(t)
+	r := newResolver()
+	r.MapDrive('C', "/mnt/c")
+	r.RelativeTextual = true
+	// anchoring to the working directory would fail, since it no longer
+	// exists, so any successful conversion did not consult the file system.
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if err := os.Remove(dir); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		f, t     Format
+		in, want string
+	}{
+		{Unix, Windows, "a/b/c", `a\b\c`},
+		{Unix, Windows, "a/../b/./c/", `b\c`},
+		{Unix, Windows, "../a", `..\a`},
+		{Windows, Unix, `a\b\c`, "a/b/c"},
+		{Unix, Windows, "/mnt/c/a", `C:\a`},
+	} {
+		if got, _, err := r.Format(c.f, c.t, c.in, true, 0); err != nil || got != c.want {
+			t.Errorf("Format(%q) = %q, %v; want %q", c.in, got, err, c.want)
+		}
+	}
+	r.RelativeTextual = false
+	if got, _, err := r.Format(Unix, Windows, "a/b/c", true, 0); err == nil {
+		t.Errorf("Format(%q) = %q; want error without RelativeTextual", "a/b/c", got)
+	}
+}
+
+func TestStrictMatch(t *testing.T) {
+	isolate(t)
+	t.Setenv("C"+NixPathEnvSuffix, "/mnt/data")
+	t.Setenv("D"+NixPathEnvSuffix, "/mnt/data")
+	t.Setenv("E"+NixPathEnvSuffix, "/mnt/e")
+	r := newResolver()
+	for _, c := range []struct {
+		strict bool
+		in     string
+		ok     bool
+	}{
+		{false, "/mnt/data/x", true},
+		{true, "/mnt/data/x", false},
+		{true, "/mnt/data", false},
+		{true, "/mnt/e/x", true},
+	} {
+		r.StrictMatch = c.strict
+		got, _, err := r.Format(Unix, Windows, c.in, true, 0)
+		if c.ok {
+			if err != nil {
+				t.Errorf("Format(%q) [strict=%t] = %q, %v; want success", c.in, c.strict, got, err)
+			}
+			continue
+		}
+		if CodeOf(err) != ErrAmbiguous {
+			t.Errorf("Format(%q) [strict=%t] = %q, %v; want %s", c.in, c.strict, got, err, ErrAmbiguous)
+			continue
+		}
+		// both candidate variables are reported
+		for _, k := range []string{"C" + NixPathEnvSuffix, "D" + NixPathEnvSuffix} {
+			if !strings.Contains(err.Error(), k) {
+				t.Errorf("Format(%q) error = %v; want it to name %s", c.in, err, k)
+			}
+		}
+	}
+}
+
+func TestRootfsRoot(t *testing.T) {
+	isolate(t)
+	r := newResolver()
+	r.MapDrive('C', "/mnt/c")
+	for _, c := range []struct {
+		rootfs, in, want string
+	}{
+		{`\\wsl$\Ubuntu`, "/", `\\wsl$\Ubuntu`},
+		{`\\wsl$\Ubuntu\`, "/", `\\wsl$\Ubuntu`},
+		{`\\wsl$\Ubuntu`, "//", `\\wsl$\Ubuntu`},
+		{`\\wsl$\Ubuntu`, "/etc/..", `\\wsl$\Ubuntu`},
+		{`\\wsl$\Ubuntu`, "/etc", `\\wsl$\Ubuntu\etc`},
+		{`C:\rootfs\`, "/", `C:\rootfs`},
+	} {
+		t.Setenv(WslRootfsEnvVar, c.rootfs)
+		got, wsl, err := r.Format(Unix, Windows, c.in, false, 0)
+		if err != nil || got != c.want || !wsl {
+			t.Errorf("%s=%q: Format(%q) = %q, %t, %v; want %q, true", WslRootfsEnvVar, c.rootfs, c.in, got, wsl, err, c.want)
+		}
+	}
+}
+
+func TestDriveOf(t *testing.T) {
+	r := &Resolver{}
+	r.SetDriveVars('E', "WINDOWS_E", "DATA"+NixPathEnvSuffix)
+	for _, c := range []struct {
+		key   string
+		drive byte
+	}{
+		{"C" + NixPathEnvSuffix, 'C'},
+		{"Z" + NixPathEnvSuffix, 'Z'},
+		{"WINDOWS_E", 'E'},
+		{"DATA" + NixPathEnvSuffix, 'E'},
+		{"D" + NixPathEnvSuffix + "X", 0},
+		{"DISK" + NixPathEnvSuffix, 0},
+		{"c" + NixPathEnvSuffix, 0},
+		{"1" + NixPathEnvSuffix, 0},
+		{NixPathEnvSuffix, 0},
+	} {
+		d, ok := r.driveOf(c.key)
+		if d != c.drive || ok != (c.drive != 0) {
+			t.Errorf("driveOf(%q) = %q, %t; want %q", c.key, d, ok, c.drive)
+		}
+	}
+}
+
+func TestNestedDriveMounts(t *testing.T) {
+	isolate(t)
+	t.Setenv("C"+NixPathEnvSuffix, "/mnt")
+	t.Setenv("D"+NixPathEnvSuffix, "/mnt/data")
+	t.Setenv("DATA"+NixPathEnvSuffix, "/mnt/other")
+	r := newResolver()
+	for _, c := range []struct{ in, want string }{
+		{"/mnt/x", `C:\x`},
+		{"/mnt/data/x", `D:\x`},
+		{"/mnt/other/x", `C:\other\x`},
+	} {
+		got, _, err := r.Format(Unix, Windows, c.in, true, 0)
+		if err != nil || got != c.want {
+			t.Errorf("Format(%q) = %q, %v; want %q", c.in, got, err, c.want)
+		}
+	}
+}
+
+func TestNestedMounts(t *testing.T) {
+	isolate(t)
+	t.Setenv("C"+NixPathEnvSuffix, "/mnt")
+	t.Setenv("D"+NixPathEnvSuffix, "/mnt/c")
+	t.Setenv("E"+NixPathEnvSuffix, "/mnt/c/e")
+	r := newResolver()
+	for _, c := range []struct {
+		f, t     Format
+		in, want string
+	}{
+		{Unix, Windows, "/mnt/x", `C:\x`},
+		{Unix, Windows, "/mnt/c", `D:\`},
+		{Unix, Windows, "/mnt/c/x", `D:\x`},
+		{Unix, Windows, "/mnt/cc", `C:\cc`},
+		{Unix, Windows, "/mnt/c/e/x", `E:\x`},
+		{Windows, Unix, `C:\c\x`, "/mnt/c/x"},
+		{Windows, Unix, `D:\e`, "/mnt/c/e"},
+		{Windows, Unix, `E:\x`, "/mnt/c/e/x"},
+	} {
+		if got, _, err := r.Format(c.f, c.t, c.in, true, 0); err != nil || got != c.want {
+			t.Errorf("Format(%q) = %q, %v; want %q", c.in, got, err, c.want)
+		}
+	}
+	// the drive is taken from the matched mapping, not its key
+	r.DriveVars = map[byte][]string{'F': {"DATA_MOUNT"}}
+	t.Setenv("DATA_MOUNT", "/mnt/c/data")
+	if got, _, err := r.Format(Unix, Windows, "/mnt/c/data/x", true, 0); err != nil || got != `F:\x` {
+		t.Errorf("Format(%q) = %q, %v; want %q", "/mnt/c/data/x", got, err, `F:\x`)
+	}
+}
+
+func TestUNCMountRoot(t *testing.T) {
+	isolate(t)
+	t.Setenv(WslRootfsEnvVar, `\\wsl$\Ubuntu`)
+	r := newResolver()
+	r.MapUNC(`\\host\mapped`, "/media/mapped")
+	for _, c := range []struct {
+		root, in, want string
+	}{
+		{"/mnt", `\\host\share\x`, "/mnt/host/share/x"},
+		{"/mnt/", `\\host\share`, "/mnt/host/share"},
+		{"/net", `\\192.168.1.10\c$\Users`, "/net/192.168.1.10/c$/Users"},
+		// explicit mappings take precedence
+		{"/mnt", `\\host\mapped\x`, "/media/mapped/x"},
+		{"", `\\host\mapped\x`, "/media/mapped/x"},
+	} {
+		r.UNCMountRoot = c.root
+		if got, _, err := r.Format(Windows, Unix, c.in, true, 0); err != nil || got != c.want {
+			t.Errorf("UNCMountRoot=%q: Format(%q) = %q, %v; want %q", c.root, c.in, got, err, c.want)
+		}
+	}
+	r.UNCMountRoot = ""
+	if got, _, err := r.Format(Windows, Unix, `\\host\share\x`, true, 0); err == nil {
+		t.Errorf("Format(%q) = %q; want error without UNCMountRoot", `\\host\share\x`, got)
+	}
+	// Unix paths are never translated to UNC volumes by convention
+	r.UNCMountRoot = "/mnt"
+	if got, wsl, err := r.Format(Unix, Windows, "/mnt/host/share/x", false, 0); err != nil || got != `\\wsl$\Ubuntu\mnt\host\share\x` || !wsl {
+		t.Errorf("Format(%q) = %q, %t, %v; want rootfs path", "/mnt/host/share/x", got, wsl, err)
+	}
+}
+
+func TestMountTrailingSep(t *testing.T) {
+	isolate(t)
+	t.Setenv("C"+NixPathEnvSuffix, "/mnt/c/")
+	t.Setenv("D"+NixPathEnvSuffix, "/mnt/d//")
+	t.Setenv(UncPathEnvVar, `\\host\share\=/mnt/share/`)
+	r := newResolver()
+	r.MapDrive('E', "/mnt/e/")
+	for _, c := range []struct {
+		f, t     Format
+		in, want string
+	}{
+		{Windows, Unix, `C:\Windows`, "/mnt/c/Windows"},
+		{Windows, Unix, `C:\`, "/mnt/c"},
+		{Windows, Unix, `D:\x`, "/mnt/d/x"},
+		{Windows, Unix, `E:\x`, "/mnt/e/x"},
+		{Windows, Unix, `\\host\share\x`, "/mnt/share/x"},
+		{Unix, Windows, "/mnt/c/Windows", `C:\Windows`},
+		{Unix, Windows, "/mnt/c", `C:\`},
+		{Unix, Windows, "/mnt/c/", `C:\`},
+		{Unix, Windows, "/mnt/d/x", `D:\x`},
+		{Unix, Windows, "/mnt/e/x", `E:\x`},
+		{Unix, Windows, "/mnt/share/x", `\\host\share\x`},
+	} {
+		if got, _, err := r.Format(c.f, c.t, c.in, true, 0); err != nil || got != c.want {
+			t.Errorf("Format(%q) = %q, %v; want %q", c.in, got, err, c.want)
+		}
+	}
+}
+
+func TestLogical(t *testing.T) {
+	isolate(t)
+	dir := t.TempDir()
+	mnt := filepath.Join(dir, "c")
+	if err := os.MkdirAll(filepath.Join(mnt, "real"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range []struct{ target, name string }{
+		{"real", filepath.Join(mnt, "link")},
+		{filepath.Join(mnt, "real"), filepath.Join(dir, "outside")},
+	} {
+		if err := os.Symlink(l.target, l.name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv(WslRootfsEnvVar, `\\wsl$\Ubuntu`)
+	rootfs := `\\wsl$\Ubuntu` + strings.ReplaceAll(dir, "/", `\`)
+	for _, c := range []struct {
+		logical  bool
+		in, want string
+	}{
+		{true, mnt + "/link/f", `C:\link\f`},
+		{false, mnt + "/link/f", `C:\real\f`},
+		{true, dir + "/outside/f", rootfs + `\outside\f`},
+		{false, dir + "/outside/f", `C:\real\f`},
+		// paths without symbolic links are unaffected
+		{true, mnt + "/real/f", `C:\real\f`},
+		{false, mnt + "/real/f", `C:\real\f`},
+	} {
+		r := &Resolver{MountTable: os.DevNull, Logical: c.logical}
+		r.MapDrive('C', mnt)
+		if got, _, err := r.Format(Unix, Windows, c.in, false, 0); err != nil || got != c.want {
+			t.Errorf("Logical=%t: Format(%q) = %q, %v; want %q", c.logical, c.in, got, err, c.want)
+		}
+	}
+}
+
+func TestRelativeRoots(t *testing.T) {
+	isolate(t)
+	r := newResolver()
+	r.MapDrive('C', "/mnt/c")
+	r.MapDrive('D', "/mnt/d")
+	r.RelativeRoots = []PrefixMap{
+		{From: "/mnt/c/proj", To: `D:\build`},
+		{From: `C:\src`, To: "/workspace"},
+	}
+	for _, c := range []struct {
+		f, t     Format
+		in, want string
+	}{
+		{Unix, Windows, "/mnt/c/proj/src/a.c", `D:\build\src\a.c`},
+		{Unix, Windows, "/mnt/c/proj", `D:\build`},
+		{Windows, Unix, `C:\src\app\main.go`, "/workspace/app/main.go"},
+		// paths not under OLD follow normal conversion
+		{Unix, Windows, "/mnt/c/project/a.c", `C:\project\a.c`},
+		{Unix, Windows, "/mnt/c/other/a.c", `C:\other\a.c`},
+		{Windows, Unix, `C:\srcs\x`, "/mnt/c/srcs/x"},
+		// each root applies only from the Format of OLD
+		{Windows, Unix, `D:\build\src\a.c`, "/mnt/d/build/src/a.c"},
+		{Unix, Windows, "/workspace/app", ""},
+	} {
+		got, _, err := r.Format(c.f, c.t, c.in, true, 0)
+		if c.want == "" {
+			if err == nil {
+				t.Errorf("Format(%q) = %q; want error", c.in, got)
+			}
+			continue
+		}
+		if err != nil || got != c.want {
+			t.Errorf("Format(%q) = %q, %v; want %q", c.in, got, err, c.want)
+		}
+	}
+}
+
+func TestUnixRoot(t *testing.T) {
+	isolate(t)
+	r := newResolver()
+	r.MapDrive('C', "/mnt/c")
+	for _, s := range []string{"/", "//", "/."} {
+		// without a rootfs, the Unix root has no Windows path
+		if got, _, err := r.Format(Unix, Windows, s, false, 0); CodeOf(err) != ErrNoMapping {
+			t.Errorf("Format(%q) = %q, %v; want %s", s, got, err, ErrNoMapping)
+		}
+	}
+	t.Setenv(WslRootfsEnvVar, `\\wsl$\Ubuntu`)
+	if got, wsl, err := r.Format(Unix, Windows, "/", false, 0); err != nil || got != `\\wsl$\Ubuntu` || !wsl {
+		t.Errorf("Format(/) = %q, %t, %v; want %q, true", got, wsl, err, `\\wsl$\Ubuntu`)
+	}
+	if got, _, err := r.Format(Unix, Windows, "/", true, 0); CodeOf(err) != ErrNoMapping || !strings.Contains(err.Error(), "Unix root") {
+		t.Errorf("Format(/) [x] = %q, %v; want %s for the Unix root", got, err, ErrNoMapping)
+	}
+	// drive roots translate to their mount points
+	for _, s := range []string{`C:\`, `C:`, `c:/`, `C:\.`, `C:\..`} {
+		if got, _, err := r.Format(Windows, Unix, s, true, 0); err != nil || got != "/mnt/c" {
+			t.Errorf("Format(%q) = %q, %v; want %q", s, got, err, "/mnt/c")
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package wslpath
+
+import "strings"
+
+// DrvfsEscapeBase is the base of the Unicode private use range in which WSL
+// drvfs stores characters that are valid in Unix file names but not in Windows
+// file names (e.g., "\" is stored as U+F05C).
+const DrvfsEscapeBase = 0xF000
+
+// ReplaceSep translates each directory separator in the given path s from that
+// of Format f to that of Format t, replacing every occurrence regardless of
+// whether it separates path elements. This is the default separator
+// translation used by Resolver.
+func ReplaceSep(f, t Format, s string) string {
+	return strings.ReplaceAll(s, string(f.sep()), string(t.sep()))
+}
+
+// StructuralSep translates only the structural directory separators in the
+// given path s, i.e., those recognized by Format f's Elements, from that of
+// Format f to that of Format t. A character within a path element that would
+// be a directory separator in Format t is escaped as WSL drvfs does, by mapping
+// it into the private use range at DrvfsEscapeBase, and escaped characters are
+// restored when translating back to Unix.
+//
+// For example, the Unix file name `a\b` translates to the Windows file name
+// "a\uF05Cb", instead of two separate elements "a" and "b".
+func StructuralSep(f, t Format, s string) string {
+	lit := string(t.sep())
+	esc := string(rune(DrvfsEscapeBase + t.sep()))
+	e := f.Elements(s)
+	for i := range e {
+		switch t {
+		case Windows:
+			e[i] = strings.ReplaceAll(e[i], lit, esc)
+		case Unix:
+			e[i] = strings.ReplaceAll(e[i], string(rune(DrvfsEscapeBase+f.sep())), string(f.sep()))
+		}
+	}
+	return strings.Join(e, lit)
+}
+
+// replaceSep translates the directory separators of the given path s from
+// Format f to Format t, using the receiver Resolver r's SepReplacer if defined,
+// otherwise ReplaceSep.
+func (r *Resolver) replaceSep(f, t Format, s string) string {
+	if r.SepReplacer != nil {
+		return r.SepReplacer(f, t, s)
+	}
+	return ReplaceSep(f, t, s)
+}
+
+// Mixed returns the given Windows path s with each directory separator written
+// as a forward slash (e.g., "C:/Users/me" or "//host/share/dir"), as preferred
+// by tools such as Git Bash, MSVC, and CMake. Windows file names cannot contain
+// a forward slash, so the result is still recognized as a Windows path.
+//
+// A forward slash is not a directory separator in an extended-length path, so
+// ExtendedPrefix is removed (e.g., `\\?\C:\Users` is "C:/Users").
+func Mixed(s string) string {
+	s, _ = TrimExtended(s)
+	return ReplaceSep(Windows, Unix, s)
+}
@@ -0,0 +1,61 @@
+package wslpath
+
+import "testing"
+
+func TestStructuralSep(t *testing.T) {
+	for _, c := range []struct {
+		f, t     Format
+		in, want string
+	}{
+		{Unix, Windows, `a\b/c`, "a\uF05Cb\\c"},
+		{Unix, Windows, "a/b/c", `a\b\c`},
+		{Windows, Unix, "a\uF05Cb\\c", `a\b/c`},
+		{Windows, Unix, `a\b`, "a/b"},
+	} {
+		if got := StructuralSep(c.f, c.t, c.in); got != c.want {
+			t.Errorf("StructuralSep(%s, %s, %q) = %q; want %q", c.f, c.t, c.in, got, c.want)
+		}
+	}
+	if got := ReplaceSep(Unix, Windows, `a\b/c`); got != `a\b\c` {
+		t.Errorf("ReplaceSep(%q) = %q; want %q", `a\b/c`, got, `a\b\c`)
+	}
+}
+
+func TestSepReplacer(t *testing.T) {
+	isolate(t)
+	r := newResolver()
+	r.MapDrive('C', "/mnt/c")
+	// a Unix file name may contain a backslash, which is not a separator
+	const name = `/mnt/c/dir/a\b.txt`
+	if got, _, err := r.Format(Unix, Windows, name, true, 0); err != nil || got != `C:\dir\a\b.txt` {
+		t.Errorf("ReplaceSep: Format(%q) = %q, %v; want %q", name, got, err, `C:\dir\a\b.txt`)
+	}
+	r.SepReplacer = StructuralSep
+	want := "C:\\dir\\a\uF05Cb.txt"
+	got, _, err := r.Format(Unix, Windows, name, true, 0)
+	if err != nil || got != want {
+		t.Errorf("StructuralSep: Format(%q) = %q, %v; want %q", name, got, err, want)
+	}
+	// and is restored on the way back
+	if back, _, err := r.Format(Windows, Unix, got, true, 0); err != nil || back != name {
+		t.Errorf("StructuralSep: Format(%q) = %q, %v; want %q", got, back, err, name)
+	}
+}
+
+func TestMixed(t *testing.T) {
+	for _, c := range []struct{ in, want string }{
+		{`C:\Users\me`, "C:/Users/me"},
+		{`C:\`, "C:/"},
+		{`\\host\share\dir`, "//host/share/dir"},
+		{`a\b`, "a/b"},
+		{`\\?\C:\Users\me`, "C:/Users/me"},
+		{`\\?\UNC\host\share\dir`, "//host/share/dir"},
+	} {
+		if got := Mixed(c.in); got != c.want {
+			t.Errorf("Mixed(%q) = %q; want %q", c.in, got, c.want)
+		}
+		if f := Identify(Mixed(c.in)); c.in[1] == ':' && f != Windows {
+			t.Errorf("Identify(Mixed(%q)) = %s; want %s", c.in, f, Windows)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package wslpath
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// cmdSpecial are the characters that cmd.exe interprets even within a quoted
+// argument, or that terminate the quoted argument or the command itself.
+const cmdSpecial = "\"%!^&|<>\r\n"
+
+// ShortPathFunc returns the 8.3 short form of the given absolute Windows path.
+// The default implementation queries the Windows command interpreter by way of
+// WSL interop, and it refuses paths containing any character that cmd.exe
+// would interpret, rather than pass them on the command line.
+var ShortPathFunc = func(s string) (string, error) {
+	if strings.ContainsAny(s, cmdSpecial) {
+		return "", errorf(ErrInvalidPath, "cannot pass to cmd.exe: %q", s)
+	}
+	out, err := exec.Command("cmd.exe", "/d", "/v:off", "/c",
+		fmt.Sprintf(`for %%I in ("%s") do @echo %%~sI`, s)).Output()
+	if err != nil {
+		return "", errorf(ErrInterop, "cmd.exe: %v", err)
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
+
+// ShortPath returns the 8.3 short form of the given absolute Windows path s.
+//
+// Volumes with 8.3 name generation disabled have no short alias for any path
+// element, in which case the long path is returned unchanged. The returned
+// bool is false only in that case, when some element of the path required a
+// short alias but none was available.
+func ShortPath(s string) (string, bool, error) {
+	p, err := ShortPathFunc(s)
+	if err != nil {
+		return "", false, err
+	}
+	if p == "" {
+		return s, false, nil
+	}
+	if strings.EqualFold(p, s) {
+		for _, e := range Windows.Elements(s) {
+			if !isShortName(e) {
+				return s, false, nil
+			}
+		}
+	}
+	return p, true, nil
+}
+
+// isShortName returns true if and only if the given path element is a valid
+// 8.3 file name, which therefore needs no short alias.
+func isShortName(e string) bool {
+	name, ext := e, ""
+	if n := strings.LastIndex(e, "."); n >= 0 {
+		name, ext = e[:n], e[n+1:]
+	}
+	return len(name) <= 8 && len(ext) <= 3 && !strings.ContainsAny(e, " +,;=[]") &&
+		strings.Count(e, ".") <= 1
+}
@@ -0,0 +1,71 @@
+package wslpath
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestShortPathFunc(t *testing.T) {
+	// the default implementation must never hand cmd.exe a path it would
+	// interpret, which is refused before cmd.exe is ever invoked
+	for _, s := range []string{
+		`C:\a"&calc&"b`,
+		`C:\%PATH%`,
+		`C:\a^b`,
+		`C:\a&b`,
+		`C:\a!b!`,
+		"C:\\a\nb",
+	} {
+		if p, err := ShortPathFunc(s); CodeOf(err) != ErrInvalidPath {
+			t.Errorf("ShortPathFunc(%q) = %q, %v; want %s", s, p, err, ErrInvalidPath)
+		}
+	}
+}
+
+func TestShortPath(t *testing.T) {
+	short := ShortPathFunc
+	t.Cleanup(func() { ShortPathFunc = short })
+	for _, c := range []struct {
+		in, alias string
+		want      string
+		ok        bool
+	}{
+		{`C:\Program Files\app`, `C:\PROGRA~1\app`, `C:\PROGRA~1\app`, true},
+		// 8.3 name generation disabled: the long name is returned
+		{`C:\Program Files\app`, `C:\Program Files\app`, `C:\Program Files\app`, false},
+		{`C:\Program Files\app`, `c:\program files\APP`, `C:\Program Files\app`, false},
+		{`C:\Program Files\app`, "", `C:\Program Files\app`, false},
+		// but paths already in 8.3 form need no alias
+		{`C:\Windows\win.ini`, `C:\Windows\win.ini`, `C:\Windows\win.ini`, true},
+	} {
+		alias := c.alias
+		ShortPathFunc = func(string) (string, error) { return alias, nil }
+		if got, ok, err := ShortPath(c.in); err != nil || got != c.want || ok != c.ok {
+			t.Errorf("ShortPath(%q) [alias %q] = %q, %t, %v; want %q, %t", c.in, alias, got, ok, err, c.want, c.ok)
+		}
+	}
+	ShortPathFunc = func(string) (string, error) { return "", errorf(ErrInterop, "cmd.exe: %v", errors.New("not found")) }
+	if _, _, err := ShortPath(`C:\x`); CodeOf(err) != ErrInterop {
+		t.Errorf("ShortPath() error = %v; want %s", err, ErrInterop)
+	}
+}
+
+func TestIsShortName(t *testing.T) {
+	for _, c := range []struct {
+		in   string
+		want bool
+	}{
+		{"WIN.INI", true},
+		{"abcdefgh.txt", true},
+		{"abcdefghi.txt", false},
+		{"a.html", false},
+		{"a b", false},
+		{"a.b.c", false},
+		{"a+b", false},
+		{"", true},
+	} {
+		if got := isShortName(c.in); got != c.want {
+			t.Errorf("isShortName(%q) = %t; want %t", c.in, got, c.want)
+		}
+	}
+}
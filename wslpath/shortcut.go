@@ -0,0 +1,187 @@
+package wslpath
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+)
+
+// Shell Link (.lnk) structure constants, as specified by [MS-SHLLINK].
+const (
+	lnkHeaderSize      = 0x4C
+	lnkHasTargetIDList = 0x01
+	lnkHasLinkInfo     = 0x02
+	lnkLocalBasePath   = 0x01
+	lnkNetworkRelative = 0x02
+	lnkInfoHeaderSize  = 0x1C
+	lnkInfoUnicodeSize = 0x24
+	lnkNetworkLinkSize = 0x14
+)
+
+// lnkCLSID is the class identifier of every Shell Link,
+// {00021401-0000-0000-C000-000000000046}, as stored in its header.
+var lnkCLSID = []byte{
+	0x01, 0x14, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0xC0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x46,
+}
+
+// IsShortcut returns true if and only if the given file path, in either
+// Windows or Unix Format, names a Windows shortcut (.lnk) file.
+func IsShortcut(s string) bool {
+	return strings.HasSuffix(strings.ToLower(s), ".lnk")
+}
+
+// ReadShortcut returns the Windows target path of the Windows shortcut (.lnk)
+// file at the given path, in either Windows or Unix Format. Windows paths are
+// first translated to Unix paths using the receiver Resolver r, since the file
+// system is only accessible through Unix paths, and absolute Unix paths are
+// read within Root, if defined.
+func (r *Resolver) ReadShortcut(s string) (string, error) {
+	name := s
+	if Windows == Identify(s) {
+		u, _, err := r.Format(Windows, Unix, s, false, 0)
+		if err != nil {
+			return "", err
+		}
+		name = u
+	}
+	if r.Root != "" && Unix.IsAbs(name) {
+		name = filepath.Join(Unix.Clean(r.Root), name)
+	}
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		return "", err
+	}
+	t, err := ShortcutTarget(b)
+	if err != nil {
+		return "", errorf(CodeOf(err), "%s: %v", s, err)
+	}
+	return t, nil
+}
+
+// ShortcutTarget returns the Windows target path held by the given content of
+// a Windows shortcut (.lnk) file. Only the LinkInfo structure is read, which
+// holds either a local path (e.g., `C:\dir\file`) or a UNC path (e.g.,
+// `\\host\share\file`). Shortcuts to shell namespace items that are not files
+// (e.g., Control Panel) have no target path and are reported as an error.
+func ShortcutTarget(b []byte) (string, error) {
+	le := binary.LittleEndian
+	if len(b) < lnkHeaderSize || le.Uint32(b) != lnkHeaderSize ||
+		!bytes.Equal(b[4:20], lnkCLSID) {
+		return "", errorf(ErrInvalidPath, "not a shell link")
+	}
+	flags := le.Uint32(b[0x14:])
+	n := lnkHeaderSize
+	if flags&lnkHasTargetIDList != 0 {
+		if len(b) < n+2 {
+			return "", lnkMalformed("LinkTargetIDList", "truncated")
+		}
+		n += 2 + int(le.Uint16(b[n:]))
+	}
+	if flags&lnkHasLinkInfo == 0 {
+		return "", errorf(ErrInvalidPath, "shortcut has no target path (not a file or directory)")
+	}
+	if len(b) < n+lnkInfoHeaderSize {
+		return "", lnkMalformed("LinkInfo", "truncated")
+	}
+	info := b[n:]
+	size := le.Uint32(info)
+	if size < lnkInfoHeaderSize || uint64(size) > uint64(len(info)) {
+		return "", lnkMalformed("LinkInfo", "invalid size")
+	}
+	info = info[:size]
+	wide := le.Uint32(info[4:]) >= lnkInfoUnicodeSize && len(info) >= lnkInfoUnicodeSize
+	infoFlags := le.Uint32(info[8:])
+
+	var suffix string
+	var ok bool
+	if wide {
+		suffix, ok = lnkString(info, le.Uint32(info[0x20:]), true)
+	} else {
+		suffix, ok = lnkString(info, le.Uint32(info[0x18:]), false)
+	}
+	if !ok {
+		return "", lnkMalformed("CommonPathSuffix", "invalid offset")
+	}
+
+	switch {
+	case infoFlags&lnkLocalBasePath != 0:
+		var base string
+		if wide {
+			base, ok = lnkString(info, le.Uint32(info[0x1C:]), true)
+		} else {
+			base, ok = lnkString(info, le.Uint32(info[0x10:]), false)
+		}
+		if !ok || base == "" {
+			return "", lnkMalformed("LocalBasePath", "invalid offset")
+		}
+		return lnkJoin(base, suffix), nil
+
+	case infoFlags&lnkNetworkRelative != 0:
+		i := le.Uint32(info[0x14:])
+		if uint64(i)+lnkNetworkLinkSize > uint64(len(info)) {
+			return "", lnkMalformed("CommonNetworkRelativeLink", "invalid offset")
+		}
+		link := info[i:]
+		var net string
+		if o := le.Uint32(link[8:]); o > lnkNetworkLinkSize && len(link) >= lnkNetworkLinkSize+4 {
+			net, ok = lnkString(link, le.Uint32(link[lnkNetworkLinkSize:]), true)
+		} else {
+			net, ok = lnkString(link, o, false)
+		}
+		if !ok || net == "" {
+			return "", lnkMalformed("NetName", "invalid offset")
+		}
+		return lnkJoin(net, suffix), nil
+	}
+	return "", errorf(ErrInvalidPath, "shortcut has no target path (not a file or directory)")
+}
+
+// lnkMalformed returns an error describing the given malformed field of a
+// Shell Link.
+func lnkMalformed(field, why string) error {
+	return errorf(ErrInvalidPath, "malformed shortcut: %s: %s", field, why)
+}
+
+// lnkString returns the NUL-terminated string at offset i of b, which is
+// decoded from UTF-16 if wide is true. Otherwise, the string is in a Windows
+// code page unknown here, and each byte is decoded as the equivalent Latin-1
+// character. The returned bool is false if the string lies outside of b.
+func lnkString(b []byte, i uint32, wide bool) (string, bool) {
+	if uint64(i) >= uint64(len(b)) {
+		return "", false
+	}
+	b = b[i:]
+	if !wide {
+		n := bytes.IndexByte(b, 0)
+		if n < 0 {
+			return "", false
+		}
+		r := make([]rune, n)
+		for k := range r {
+			r[k] = rune(b[k])
+		}
+		return string(r), true
+	}
+	var u []uint16
+	for k := 0; k+1 < len(b); k += 2 {
+		c := binary.LittleEndian.Uint16(b[k:])
+		if c == 0 {
+			return string(utf16.Decode(u)), true
+		}
+		u = append(u, c)
+	}
+	return "", false
+}
+
+// lnkJoin returns the Windows path of a shortcut target composed of the given
+// base path and common path suffix, either of which may be empty.
+func lnkJoin(base, suffix string) string {
+	if suffix == "" {
+		return base
+	}
+	return Windows.join(base, suffix)
+}
@@ -0,0 +1,118 @@
+package wslpath
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// lnk returns the content of a minimal Windows shortcut (.lnk) file whose
+// LinkInfo holds the given ANSI base path and common path suffix. If net is
+// true, base is the NetName of a CommonNetworkRelativeLink instead of a
+// LocalBasePath. If ids is true, an empty LinkTargetIDList precedes LinkInfo.
+func lnk(base, suffix string, net, ids bool) []byte {
+	le := binary.LittleEndian
+	b := make([]byte, lnkHeaderSize)
+	le.PutUint32(b, lnkHeaderSize)
+	copy(b[4:], lnkCLSID)
+	flags := uint32(lnkHasLinkInfo)
+	if ids {
+		flags |= lnkHasTargetIDList
+		b = append(b, 2, 0, 0, 0) // IDListSize, TerminalID
+	}
+	le.PutUint32(b[0x14:], flags)
+
+	info := make([]byte, lnkInfoHeaderSize)
+	le.PutUint32(info[4:], lnkInfoHeaderSize)
+	if net {
+		le.PutUint32(info[8:], lnkNetworkRelative)
+		le.PutUint32(info[0x14:], uint32(len(info)))
+		link := make([]byte, lnkNetworkLinkSize)
+		le.PutUint32(link[8:], lnkNetworkLinkSize)
+		link = append(link, base+"\x00"...)
+		le.PutUint32(link, uint32(len(link)))
+		info = append(info, link...)
+	} else {
+		le.PutUint32(info[8:], lnkLocalBasePath)
+		le.PutUint32(info[0x10:], uint32(len(info)))
+		info = append(info, base+"\x00"...)
+	}
+	le.PutUint32(info[0x18:], uint32(len(info)))
+	info = append(info, suffix+"\x00"...)
+	le.PutUint32(info, uint32(len(info)))
+	return append(b, info...)
+}
+
+func TestShortcutTarget(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		b    []byte
+		want string
+	}{
+		{"local", lnk(`C:\Users\me\file.txt`, "", false, false), `C:\Users\me\file.txt`},
+		{"suffix", lnk(`C:\Users\`, `me\file.txt`, false, false), `C:\Users\me\file.txt`},
+		{"idlist", lnk(`D:\data`, "", false, true), `D:\data`},
+		{"network", lnk(`\\host\share`, `dir\file`, true, false), `\\host\share\dir\file`},
+	} {
+		if got, err := ShortcutTarget(c.b); err != nil || got != c.want {
+			t.Errorf("%s: ShortcutTarget() = %q, %v; want %q", c.name, got, err, c.want)
+		}
+	}
+}
+
+func TestShortcutTargetMalformed(t *testing.T) {
+	valid := lnk(`C:\x`, "", false, false)
+	noInfo := append([]byte{}, valid[:lnkHeaderSize]...)
+	binary.LittleEndian.PutUint32(noInfo[0x14:], 0)
+	badSize := append([]byte{}, valid...)
+	binary.LittleEndian.PutUint32(badSize[lnkHeaderSize:], uint32(len(valid)))
+	badOffset := append([]byte{}, valid...)
+	binary.LittleEndian.PutUint32(badOffset[lnkHeaderSize+0x10:], 0xFFFF)
+	for _, c := range []struct {
+		name string
+		b    []byte
+	}{
+		{"empty", nil},
+		{"text", []byte("[InternetShortcut]\nURL=file:///C:/x\n")},
+		{"truncated", valid[:lnkHeaderSize+4]},
+		{"no link info", noInfo},
+		{"bad size", badSize},
+		{"bad offset", badOffset},
+	} {
+		if got, err := ShortcutTarget(c.b); CodeOf(err) != ErrInvalidPath {
+			t.Errorf("%s: ShortcutTarget() = %q, %v; want %s", c.name, got, err, ErrInvalidPath)
+		}
+	}
+}
+
+func TestReadShortcut(t *testing.T) {
+	isolate(t)
+	mnt := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(mnt, "App.lnk"), lnk(`C:\Program Files\App\app.exe`, "", false, false), 0644); err != nil {
+		t.Fatal(err)
+	}
+	r := newResolver()
+	r.MapDrive('C', mnt)
+	for _, s := range []string{`C:\App.lnk`, mnt + "/App.lnk"} {
+		if got, err := r.ReadShortcut(s); err != nil || got != `C:\Program Files\App\app.exe` {
+			t.Errorf("ReadShortcut(%q) = %q, %v; want %q", s, got, err, `C:\Program Files\App\app.exe`)
+		}
+	}
+	if got, err := r.ReadShortcut(`C:\missing.lnk`); err == nil {
+		t.Errorf("ReadShortcut(missing) = %q; want error", got)
+	}
+	for _, c := range []struct {
+		in   string
+		want bool
+	}{
+		{`C:\App.lnk`, true},
+		{"/mnt/c/app.LNK", true},
+		{`C:\app.exe`, false},
+		{"lnk", false},
+	} {
+		if got := IsShortcut(c.in); got != c.want {
+			t.Errorf("IsShortcut(%q) = %t; want %t", c.in, got, c.want)
+		}
+	}
+}
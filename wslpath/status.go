@@ -0,0 +1,49 @@
+package wslpath
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Status represents the existence of the file referred to by a path, as far as
+// it can be determined from within WSL.
+type Status int
+
+const (
+	// Unchecked paths were not (or could not be) inspected, such as paths into
+	// the WSL rootfs or paths denied by permissions.
+	Unchecked Status = iota
+	// Exists paths refer to a file present on the file system.
+	Exists
+	// Missing paths refer to no file on the file system.
+	Missing
+)
+
+// String returns a short name of the receiver Status s.
+func (s Status) String() string {
+	switch s {
+	case Exists:
+		return "exists"
+	case Missing:
+		return "missing"
+	}
+	return "unchecked"
+}
+
+// Stat returns the Status of the file referred to by the given Unix path s.
+// Absolute paths are inspected within the receiver Resolver r's Root, if
+// defined. Symbolic links are followed, so a dangling link is Missing.
+func (r *Resolver) Stat(s string) Status {
+	defer r.Timing.AddFS(time.Now())
+	if r.Root != "" && Unix.IsAbs(s) {
+		s = filepath.Join(Unix.Clean(r.Root), s)
+	}
+	switch _, err := os.Stat(s); {
+	case err == nil:
+		return Exists
+	case os.IsNotExist(err):
+		return Missing
+	}
+	return Unchecked
+}
@@ -0,0 +1,50 @@
+package wslpath
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStat(t *testing.T) {
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "file"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("nowhere", filepath.Join(dir, "dangling")); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		root, in string
+		want     Status
+	}{
+		{"", filepath.Join(dir, "file"), Exists},
+		{"", dir, Exists},
+		{"", filepath.Join(dir, "missing"), Missing},
+		{"", filepath.Join(dir, "dangling"), Missing},
+		{dir, "/file", Exists},
+		{dir, "/missing", Missing},
+	} {
+		r := newResolver()
+		r.Root = c.root
+		if got := r.Stat(c.in); got != c.want {
+			t.Errorf("Root=%q: Stat(%q) = %s; want %s", c.root, c.in, got, c.want)
+		}
+	}
+}
+
+func TestStatusString(t *testing.T) {
+	for _, c := range []struct {
+		s    Status
+		want string
+	}{
+		{Exists, "exists"},
+		{Missing, "missing"},
+		{Unchecked, "unchecked"},
+	} {
+		if got := c.s.String(); got != c.want {
+			t.Errorf("%d.String() = %q; want %q", c.s, got, c.want)
+		}
+	}
+}
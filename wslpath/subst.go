@@ -0,0 +1,65 @@
+package wslpath
+
+import (
+	"bufio"
+	"bytes"
+	"os/exec"
+	"strings"
+)
+
+// SetSubst declares the given drive letter as a virtual drive created with the
+// Windows subst utility, whose root refers to the given Windows path target.
+func (r *Resolver) SetSubst(drive byte, target string) {
+	if r.Substs == nil {
+		r.Substs = map[byte]string{}
+	}
+	r.Substs[upper(drive)] = Windows.Clean(target)
+}
+
+// resolveSubst rewrites the given Windows path s, if it begins with a drive
+// letter created by subst, by replacing that drive with the subst target.
+// Drive letters are resolved using the receiver Resolver r's Substs, or if
+// undefined, by way of WSL interop with the Windows subst utility. Paths that
+// do not begin with a subst drive are returned unchanged.
+//
+// The path is cleaned before the drive is replaced, so that ".." elements
+// cannot escape the root of the subst drive into its target's parent.
+func (r *Resolver) resolveSubst(s string) string {
+	v, p := Windows.SplitVolume(Windows.Clean(s))
+	if len(v) != 2 || v[1] != ':' {
+		return s
+	}
+	m := r.Substs
+	if m == nil {
+		// subst is consulted only once, and its drives are cached
+		// privately, so that they are never confused with those given
+		// explicitly.
+		r.substOnce.Do(func() { r.substs = substs() })
+		m = r.substs
+	}
+	target, ok := m[upper(v[0])]
+	if !ok {
+		return s
+	}
+	return Windows.Clean(target + string(Windows.sep()) + p)
+}
+
+// substs returns each virtual drive reported by the Windows subst utility, via
+// WSL interop. Errors are ignored, since subst drives are merely an optional
+// refinement of the drive letters in any path.
+func substs() map[byte]string {
+	m := map[byte]string{}
+	out, err := exec.Command("subst.exe").Output()
+	if err != nil {
+		return m
+	}
+	// subst lists each virtual drive with its target: "D:\: => C:\data"
+	s := bufio.NewScanner(bytes.NewReader(out))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if n := strings.Index(line, "=>"); n > 1 && isalpha(line[0]) && line[1] == ':' {
+			m[upper(line[0])] = Windows.Clean(strings.TrimSpace(line[n+2:]))
+		}
+	}
+	return m
+}
@@ -0,0 +1,48 @@
+package wslpath
+
+import "testing"
+
+func TestSubst(t *testing.T) {
+	isolate(t)
+	r := newResolver()
+	r.MapDrive('C', "/mnt/c")
+	r.MapDrive('D', "/mnt/d")
+	r.SetSubst('d', `C:\data\`)
+	for _, c := range []struct {
+		resolve  bool
+		in, want string
+	}{
+		// subst drives are first-class unless resolved
+		{false, `D:\x`, "/mnt/d/x"},
+		{false, `D:\`, "/mnt/d"},
+		{true, `D:\x`, "/mnt/c/data/x"},
+		{true, `d:\`, "/mnt/c/data"},
+		{true, `D:\..\x`, "/mnt/c/data/x"},
+		{true, `C:\x`, "/mnt/c/x"},
+		{true, `\\host\share\x`, ""},
+	} {
+		r.ResolveSubst = c.resolve
+		got, _, err := r.Format(Windows, Unix, c.in, true, 0)
+		if c.want == "" {
+			if err == nil {
+				t.Errorf("ResolveSubst=%t: Format(%q) = %q; want error", c.resolve, c.in, got)
+			}
+		} else if err != nil || got != c.want {
+			t.Errorf("ResolveSubst=%t: Format(%q) = %q, %v; want %q", c.resolve, c.in, got, err, c.want)
+		}
+	}
+}
+
+func TestSubstInterop(t *testing.T) {
+	isolate(t)
+	r := newResolver()
+	r.MapDrive('C', "/mnt/c")
+	r.ResolveSubst = true
+	// the drives reported by subst, if any, are never added to Substs
+	if _, _, err := r.Format(Windows, Unix, `C:\x`, true, 0); err != nil {
+		t.Fatal(err)
+	}
+	if r.Substs != nil {
+		t.Errorf("Substs = %q; want nil", r.Substs)
+	}
+}
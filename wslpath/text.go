@@ -0,0 +1,47 @@
+package wslpath
+
+import "strings"
+
+// FormatQuoted converts each quoted substring ("..." or '...') of the given
+// text s that is a file path to Format t, leaving all other text unchanged
+// (e.g., `run "C:\tools\x.exe" --opt /mnt/c/data` converts only the quoted
+// Windows path). If t is Any, each quoted path is converted to the opposite
+// of its identified Format.
+//
+// Quoted substrings that are not file paths (i.e., of Format Any), or that are
+// already in Format t, are copied unchanged, as is an unterminated quote. The
+// backslash is a Windows directory separator, so it never escapes a quote.
+func (r *Resolver) FormatQuoted(t Format, s string, x bool) (string, error) {
+	var b strings.Builder
+	for {
+		i := strings.IndexAny(s, `"'`)
+		if i < 0 {
+			break
+		}
+		n := strings.IndexByte(s[i+1:], s[i])
+		if n < 0 {
+			break
+		}
+		q := s[i+1 : i+1+n]
+		b.WriteString(s[:i+1])
+		if f := Identify(q); Any != f && t != f {
+			to := t
+			if Any == to {
+				to = Unix
+				if Unix == f {
+					to = Windows
+				}
+			}
+			p, _, err := r.Format(f, to, q, x, 0)
+			if err != nil {
+				return "", err
+			}
+			q = p
+		}
+		b.WriteString(q)
+		b.WriteByte(s[i])
+		s = s[i+n+2:]
+	}
+	b.WriteString(s)
+	return b.String(), nil
+}
@@ -0,0 +1,33 @@
+package wslpath
+
+import "testing"
+
+func TestFormatQuoted(t *testing.T) {
+	isolate(t)
+	r := newResolver()
+	r.MapDrive('C', "/mnt/c")
+	for _, c := range []struct {
+		t        Format
+		in, want string
+	}{
+		{Unix, `run "C:\tools\x.exe" --opt /mnt/c/data`, `run "/mnt/c/tools/x.exe" --opt /mnt/c/data`},
+		{Windows, `cp '/mnt/c/a b' "/mnt/c/c"`, `cp 'C:\a b' "C:\c"`},
+		{Any, `diff "C:\a" '/mnt/c/b'`, `diff "/mnt/c/a" 'C:\b'`},
+		// quoted text that is not a path, or already in Format t, is unchanged
+		{Unix, `echo "hello" "/mnt/c/x" 'C:\y'`, `echo "hello" "/mnt/c/x" '/mnt/c/y'`},
+		// quotes of the other kind are literal within a quoted string
+		{Unix, `say "it's here"`, `say "it's here"`},
+		{Unix, `say "C:\it's"`, `say "/mnt/c/it's"`},
+		// an unterminated quote is unchanged
+		{Unix, `run "C:\x`, `run "C:\x`},
+		{Unix, `no quotes C:\x`, `no quotes C:\x`},
+		{Unix, `""`, `""`},
+	} {
+		if got, err := r.FormatQuoted(c.t, c.in, false); err != nil || got != c.want {
+			t.Errorf("FormatQuoted(%s, %q) = %q, %v; want %q", c.t, c.in, got, err, c.want)
+		}
+	}
+	if got, err := r.FormatQuoted(Unix, `run "Z:\x"`, true); err == nil {
+		t.Errorf("FormatQuoted(unmapped) = %q; want error", got)
+	}
+}
@@ -0,0 +1,52 @@
+package wslpath
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// Timing accumulates the time spent translating file paths, distinguishing the
+// time spent inspecting the file system (e.g., resolving symbolic links) from
+// the purely lexical processing of paths. All methods are no-ops on a nil
+// *Timing.
+type Timing struct {
+	Paths int
+	Total time.Duration
+	FS    time.Duration
+}
+
+// Add counts a single translated path, which took the given duration overall.
+func (t *Timing) Add(d time.Duration) {
+	if t == nil {
+		return
+	}
+	t.Paths++
+	t.Total += d
+}
+
+// AddFS accounts the time elapsed since the given start time as time spent
+// inspecting the file system.
+func (t *Timing) AddFS(start time.Time) {
+	if t == nil {
+		return
+	}
+	t.FS += time.Since(start)
+}
+
+// Report writes a summary of the accumulated times to w.
+func (t *Timing) Report(w io.Writer) {
+	if t == nil {
+		return
+	}
+	var avg time.Duration
+	if t.Paths > 0 {
+		avg = t.Total / time.Duration(t.Paths)
+	}
+	lex := t.Total - t.FS
+	if lex < 0 {
+		lex = 0
+	}
+	fmt.Fprintf(w, "timing: %d paths in %v (%v/path): file system %v, lexical %v\n",
+		t.Paths, t.Total, avg, t.FS, lex)
+}
@@ -0,0 +1,59 @@
+package wslpath
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTiming(t *testing.T) {
+	for _, c := range []struct {
+		name  string
+		paths []time.Duration
+		fs    time.Duration
+		want  string
+	}{
+		{"none", nil, 0, "timing: 0 paths in 0s (0s/path): file system 0s, lexical 0s\n"},
+		{"lexical", []time.Duration{time.Second, 3 * time.Second}, 0,
+			"timing: 2 paths in 4s (2s/path): file system 0s, lexical 4s\n"},
+		{"fs", []time.Duration{4 * time.Second}, time.Second,
+			"timing: 1 paths in 4s (4s/path): file system 1s, lexical 3s\n"},
+		{"clamp", []time.Duration{time.Second}, 2 * time.Second,
+			"timing: 1 paths in 1s (1s/path): file system 2s, lexical 0s\n"},
+	} {
+		tm := &Timing{FS: c.fs}
+		for _, d := range c.paths {
+			tm.Add(d)
+		}
+		var b bytes.Buffer
+		tm.Report(&b)
+		if got := b.String(); got != c.want {
+			t.Errorf("%s: Report() = %q; want %q", c.name, got, c.want)
+		}
+	}
+}
+
+func TestTimingNil(t *testing.T) {
+	var tm *Timing
+	tm.Add(time.Second)
+	tm.AddFS(time.Now())
+	var b bytes.Buffer
+	tm.Report(&b)
+	if b.Len() != 0 {
+		t.Errorf("Report() = %q; want empty", b.String())
+	}
+}
+
+func TestTimingAddFS(t *testing.T) {
+	tm := &Timing{}
+	tm.AddFS(time.Now().Add(-time.Second))
+	if tm.FS < time.Second {
+		t.Errorf("AddFS() = %v; want at least 1s", tm.FS)
+	}
+	var b bytes.Buffer
+	tm.Report(&b)
+	if !strings.HasPrefix(b.String(), "timing: 0 paths") {
+		t.Errorf("Report() = %q", b.String())
+	}
+}
@@ -0,0 +1,38 @@
+package wslpath
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// TraceEvent describes a single step performed by Resolver.Format while
+// translating a file path.
+type TraceEvent struct {
+	// Event identifies the conversion step (e.g., "clean", "drive", "unc",
+	// "rootfs", "result").
+	Event string `json:"event"`
+	// Input is the file path given to the conversion step.
+	Input string `json:"input"`
+	// Format is the Format of Input.
+	Format string `json:"format"`
+	// Var is the environment variable or mapping consulted, if any.
+	Var string `json:"var,omitempty"`
+	// Result is the file path produced by the conversion step.
+	Result string `json:"result"`
+}
+
+// trace emits a TraceEvent to the receiver Resolver r's Trace sink, if defined.
+func (r *Resolver) trace(event, input string, f Format, key, result string) {
+	if r.Trace != nil {
+		r.Trace(TraceEvent{
+			Event: event, Input: input, Format: f.String(), Var: key, Result: result,
+		})
+	}
+}
+
+// JSONTrace returns a Trace sink that writes each TraceEvent to w as a single
+// line of JSON.
+func JSONTrace(w io.Writer) func(TraceEvent) {
+	enc := json.NewEncoder(w)
+	return func(e TraceEvent) { _ = enc.Encode(e) }
+}
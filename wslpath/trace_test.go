@@ -0,0 +1,55 @@
+package wslpath
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestJSONTrace(t *testing.T) {
+	isolate(t)
+	t.Setenv("C"+NixPathEnvSuffix, "/mnt/c")
+	for _, c := range []struct {
+		f, t   Format
+		in     string
+		events []TraceEvent
+	}{
+		{Windows, Unix, `C:\a\..\b`, []TraceEvent{
+			{Event: "clean", Input: `C:\a\..\b`, Format: "windows", Result: `C:\b`},
+			{Event: "drive", Input: `C:\b`, Format: "windows", Var: "C" + NixPathEnvSuffix, Result: "/mnt/c/b"},
+			{Event: "result", Input: "/mnt/c/b", Format: "unix", Result: "/mnt/c/b"},
+		}},
+		{Unix, Windows, "/mnt/c/x/", []TraceEvent{
+			{Event: "clean", Input: "/mnt/c/x/", Format: "unix", Result: "/mnt/c/x"},
+			{Event: "abspath", Input: "/mnt/c/x", Format: "unix", Result: "/mnt/c/x"},
+			{Event: "drive", Input: "/mnt/c/x", Format: "unix", Var: "C" + NixPathEnvSuffix, Result: `C:\\x`},
+			{Event: "result", Input: `C:\\x`, Format: "windows", Result: `C:\x`},
+		}},
+	} {
+		var buf bytes.Buffer
+		r := newResolver()
+		r.Trace = JSONTrace(&buf)
+		if _, _, err := r.Format(c.f, c.t, c.in, false, 0); err != nil {
+			t.Fatalf("Format(%q): %v", c.in, err)
+		}
+		var got []TraceEvent
+		s := bufio.NewScanner(&buf)
+		for s.Scan() {
+			var e TraceEvent
+			if err := json.Unmarshal(s.Bytes(), &e); err != nil {
+				t.Fatalf("Format(%q): invalid trace line %q: %v", c.in, s.Text(), err)
+			}
+			got = append(got, e)
+		}
+		if len(got) != len(c.events) {
+			t.Errorf("Format(%q) traced %d events; want %d: %+v", c.in, len(got), len(c.events), got)
+			continue
+		}
+		for i := range got {
+			if got[i] != c.events[i] {
+				t.Errorf("Format(%q) event %d = %+v; want %+v", c.in, i, got[i], c.events[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package wslpath
+
+import (
+	"net/url"
+	"os"
+	"strings"
+)
+
+// DistroEnvVar holds the name of the active WSL distribution, which WSL
+// defines in the environment of every process.
+const DistroEnvVar = "WSL_DISTRO_NAME"
+
+// VSCodeRemoteURI returns the URI used by Visual Studio Code to address the
+// given absolute Unix path s in the given WSL distribution.
+func VSCodeRemoteURI(distro, s string) string {
+	u := url.URL{Scheme: "vscode-remote", Host: "wsl+" + distro, Path: s}
+	return u.String()
+}
+
+// FileURI returns the "file" URI of the given absolute Windows path s. Drive
+// letter paths have an empty host (e.g., "file:///C:/Users"), and UNC paths
+// use the UNC host (e.g., "file://host/share/dir").
+func FileURI(s string) string {
+	v, p := Windows.SplitVolume(s)
+	p = strings.ReplaceAll(p, string(Windows.sep()), "/")
+	u := url.URL{Scheme: "file"}
+	if len(v) > 2 && v[1] != ':' {
+		// UNC volume: host and share
+		e := Windows.Elements(v)
+		u.Host, u.Path = e[2], "/"+strings.Join(e[3:], "/")+p
+	} else {
+		u.Path = "/" + v + p
+	}
+	return u.String()
+}
+
+// VSCodeURI returns a URI suitable for opening the given file path in Visual
+// Studio Code from Windows. Unix paths are made absolute and addressed in the
+// active WSL distribution, identified by DistroEnvVar. Windows paths are
+// addressed with a plain "file" URI.
+func VSCodeURI(s string) (string, error) {
+	switch Identify(s) {
+	case Windows:
+		if !Windows.IsAbs(s) {
+			return "", errorf(ErrInvalidPath, "path is not absolute: %s", s)
+		}
+		return FileURI(Windows.Clean(s)), nil
+	default:
+		distro, ok := os.LookupEnv(DistroEnvVar)
+		if !ok || distro == "" {
+			return "", errorf(ErrEnvNotSet, "environment variable not set: %s", DistroEnvVar)
+		}
+		a, err := Unix.abspath(Unix.Clean(s))
+		if err != nil {
+			return "", err
+		}
+		return VSCodeRemoteURI(distro, a), nil
+	}
+}
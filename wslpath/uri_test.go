@@ -0,0 +1,27 @@
+package wslpath
+
+import "testing"
+
+func TestVSCodeURI(t *testing.T) {
+	isolate(t)
+	t.Setenv(DistroEnvVar, "Ubuntu-22.04")
+	for _, c := range []struct{ in, want string }{
+		{"/home/me/project", "vscode-remote://wsl+Ubuntu-22.04/home/me/project"},
+		{"/home/me/my project/a#b", "vscode-remote://wsl+Ubuntu-22.04/home/me/my%20project/a%23b"},
+		{`C:\Users\me`, "file:///C:/Users/me"},
+		{`C:\Program Files\a%b`, "file:///C:/Program%20Files/a%25b"},
+		{`\\host\share\dir\a b`, "file://host/share/dir/a%20b"},
+		{`C:\`, "file:///C:/"},
+	} {
+		if got, err := VSCodeURI(c.in); err != nil || got != c.want {
+			t.Errorf("VSCodeURI(%q) = %q, %v; want %q", c.in, got, err, c.want)
+		}
+	}
+	if got, err := VSCodeURI(`C:x`); CodeOf(err) != ErrInvalidPath {
+		t.Errorf("VSCodeURI(%q) = %q, %v; want %s", `C:x`, got, err, ErrInvalidPath)
+	}
+	unsetenv(t, DistroEnvVar)
+	if got, err := VSCodeURI("/home/me"); CodeOf(err) != ErrEnvNotSet {
+		t.Errorf("VSCodeURI(%q) = %q, %v; want %s", "/home/me", got, err, ErrEnvNotSet)
+	}
+}
@@ -0,0 +1,29 @@
+package wslpath
+
+// VolumeStyle represents an enumeration of the ways in which a Windows drive
+// may be addressed in Windows paths.
+type VolumeStyle int
+
+const (
+	// DriveStyle addresses a drive by its letter (e.g., `C:\x`).
+	DriveStyle VolumeStyle = iota
+	// UNCStyle addresses a drive by a UNC share (e.g., `\\localhost\C$\x`).
+	// The share is the UNC volume mapped to the same mount point as the
+	// drive, if any, otherwise the drive's administrative share on the local
+	// host.
+	UNCStyle
+)
+
+// driveVolume returns the Windows volume addressing the given drive, mounted
+// at the given mount point, in the receiver Resolver r's VolumeStyle.
+func (r *Resolver) driveVolume(drive byte, mount string) string {
+	if UNCStyle != r.VolumeStyle {
+		return string(upper(drive)) + ":"
+	}
+	for _, u := range r.uncMappings() {
+		if u.path == Unix.Clean(mount) {
+			return u.volume
+		}
+	}
+	return `\\localhost\` + string(upper(drive)) + "$"
+}
@@ -0,0 +1,30 @@
+package wslpath
+
+import "testing"
+
+func TestVolumeStyle(t *testing.T) {
+	isolate(t)
+	for _, c := range []struct {
+		style   VolumeStyle
+		share   bool
+		in, out string
+	}{
+		{DriveStyle, false, "/mnt/c/Users/me", `C:\Users\me`},
+		{DriveStyle, true, "/mnt/c/Users/me", `C:\Users\me`},
+		{UNCStyle, false, "/mnt/c/Users/me", `\\localhost\C$\Users\me`},
+		{UNCStyle, true, "/mnt/c/Users/me", `\\server\c\Users\me`},
+		{DriveStyle, true, "/mnt/c", `C:\`},
+		{UNCStyle, false, "/mnt/c", `\\localhost\C$\`},
+		{UNCStyle, true, "/mnt/c", `\\server\c\`},
+	} {
+		r := newResolver()
+		r.MapDrive('C', "/mnt/c")
+		if c.share {
+			r.MapUNC(`\\server\c`, "/mnt/c")
+		}
+		r.VolumeStyle = c.style
+		if got, _, err := r.Format(Unix, Windows, c.in, false, 0); err != nil || got != c.out {
+			t.Errorf("Format(%q) [style=%d share=%t] = %q, %v; want %q", c.in, c.style, c.share, got, err, c.out)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package wslpath
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// VolumeGUIDPrefix is the prefix of a Windows volume GUID path, which refers to
+// a volume independent of any drive letter (e.g., "\\?\Volume{GUID}\path").
+const VolumeGUIDPrefix = `\\?\Volume{`
+
+// splitVolumeGUID separates the given Windows path s into its volume GUID
+// (without enclosing braces) and remaining path. The returned bool is false if
+// s is not a volume GUID path.
+func splitVolumeGUID(s string) (guid, path string, ok bool) {
+	n := len(VolumeGUIDPrefix)
+	if len(s) < n || !strings.EqualFold(s[:n], VolumeGUIDPrefix) {
+		return "", s, false
+	}
+	e := strings.IndexRune(s[n:], '}')
+	if e < 0 {
+		return "", s, false
+	}
+	return strings.ToLower(s[n : n+e]), s[n+e+1:], true
+}
+
+// resolveVolumeGUID rewrites the given Windows volume GUID path s using the
+// drive letter to which its volume is mounted. Paths that are not volume GUID
+// paths are returned unchanged.
+//
+// Volumes not found in VolumeGUIDs are looked up, and the drive letters found
+// are cached privately, so that they are never confused with the volumes
+// given explicitly.
+func (r *Resolver) resolveVolumeGUID(s string) (string, error) {
+	guid, path, ok := splitVolumeGUID(s)
+	if !ok {
+		return s, nil
+	}
+	drive, ok := r.VolumeGUIDs[guid]
+	if !ok {
+		r.mu.Lock()
+		drive, ok = r.guids[guid]
+		r.mu.Unlock()
+	}
+	if !ok {
+		lookup := r.LookupVolumeGUID
+		if lookup == nil {
+			lookup = mountvol
+		}
+		// the lookup may invoke a Windows utility, so the lock is not held
+		var err error
+		if drive, err = lookup(guid); err != nil {
+			return "", errorf(ErrNoMapping, "cannot resolve volume {%s}: %v", guid, err)
+		}
+		if d, ok := driveDesignator(drive); ok {
+			r.mu.Lock()
+			if r.guids == nil {
+				r.guids = map[string]string{}
+			}
+			r.guids[guid] = d
+			r.mu.Unlock()
+		}
+	}
+	d, ok := driveDesignator(drive)
+	if !ok {
+		return "", errorf(ErrNoMapping, "cannot resolve volume {%s}: not a drive letter: %q", guid, drive)
+	}
+	return d + path, nil
+}
+
+// SetVolumeGUID associates the given volume GUID with a drive letter (e.g.,
+// "C:"). The GUID may be given with or without enclosing braces.
+func (r *Resolver) SetVolumeGUID(guid, drive string) {
+	if r.VolumeGUIDs == nil {
+		r.VolumeGUIDs = map[string]string{}
+	}
+	if d, ok := driveDesignator(drive); ok {
+		drive = d
+	}
+	r.VolumeGUIDs[strings.ToLower(strings.Trim(guid, "{}"))] = drive
+}
+
+// driveDesignator returns the drive designator (e.g., "C:") of the given drive
+// letter, which may be followed by a colon and root separator (e.g., "c",
+// "C:", or `C:\`), and false if s is not a drive letter.
+func driveDesignator(s string) (string, bool) {
+	if len(s) == 0 || !isalpha(s[0]) {
+		return "", false
+	}
+	if len(s) > 1 && (s[1] != ':' || strings.Trim(s[2:], `\/`) != "") {
+		return "", false
+	}
+	return string(upper(s[0])) + ":", true
+}
+
+// mountvol returns the drive letter to which the volume with the given GUID is
+// mounted, by way of WSL interop with the Windows mountvol utility.
+func mountvol(guid string) (string, error) {
+	out, err := exec.Command("mountvol.exe").Output()
+	if err != nil {
+		return "", errorf(ErrInterop, "mountvol.exe: %v", err)
+	}
+	// mountvol lists each volume GUID path, followed by an indented line for
+	// each of its mount points (or a note that it has none).
+	var vol string
+	s := bufio.NewScanner(bytes.NewReader(out))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if g, _, ok := splitVolumeGUID(line); ok {
+			vol = g
+			continue
+		}
+		if vol == guid && len(line) >= 2 && isalpha(line[0]) && line[1] == ':' {
+			return line[:2], nil
+		}
+	}
+	return "", fmt.Errorf("volume not mounted to any drive letter")
+}
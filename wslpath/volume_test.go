@@ -0,0 +1,77 @@
+package wslpath
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestVolumeGUID(t *testing.T) {
+	isolate(t)
+	const guid = "0b0fd6a2-55e1-4d3e-9c3a-8a1f2b6c7d8e"
+	r := newResolver()
+	t.Setenv("D"+NixPathEnvSuffix, "/mnt/d")
+	t.Setenv("E"+NixPathEnvSuffix, "/mnt/e")
+	var lookups int
+	r.LookupVolumeGUID = func(g string) (string, error) {
+		lookups++
+		if g != guid {
+			return "", errors.New("volume not mounted to any drive letter")
+		}
+		return "D:", nil
+	}
+	for _, c := range []struct{ in, want string }{
+		{`\\?\Volume{` + guid + `}\data\x`, "/mnt/d/data/x"},
+		{`\\?\volume{0B0FD6A2-55E1-4D3E-9C3A-8A1F2B6C7D8E}\`, "/mnt/d"},
+		{`\\?\Volume{00000000-0000-0000-0000-000000000000}\x`, ""},
+	} {
+		got, _, err := r.Format(Windows, Unix, c.in, true, 0)
+		if c.want == "" {
+			if CodeOf(err) != ErrNoMapping {
+				t.Errorf("Format(%q) = %q, %v; want %s", c.in, got, err, ErrNoMapping)
+			}
+		} else if err != nil || got != c.want {
+			t.Errorf("Format(%q) = %q, %v; want %q", c.in, got, err, c.want)
+		}
+	}
+	// resolved volumes are remembered
+	if lookups != 2 {
+		t.Errorf("LookupVolumeGUID called %d times; want 2", lookups)
+	}
+	// and those given explicitly are never looked up
+	r.SetVolumeGUID("{11111111-2222-3333-4444-555555555555}", "e")
+	if got, _, err := r.Format(Windows, Unix, `\\?\Volume{11111111-2222-3333-4444-555555555555}\x`, true, 0); err != nil || got != "/mnt/e/x" {
+		t.Errorf("Format() = %q, %v; want %q", got, err, "/mnt/e/x")
+	}
+	if lookups != 2 {
+		t.Errorf("LookupVolumeGUID called %d times; want 2", lookups)
+	}
+	// without ever being confused with those given explicitly
+	if _, ok := r.VolumeGUIDs[guid]; ok || len(r.VolumeGUIDs) != 1 {
+		t.Errorf("VolumeGUIDs = %q; want only the volume given explicitly", r.VolumeGUIDs)
+	}
+}
+
+func TestVolumeGUIDLookup(t *testing.T) {
+	isolate(t)
+	for _, c := range []struct{ drive, want string }{
+		{"D:", "/mnt/d/x"},
+		{"d", "/mnt/d/x"},
+		{`D:\`, "/mnt/d/x"},
+		{"", ""},
+		{"DD:", ""},
+		{`\\host\share`, ""},
+	} {
+		drive := c.drive
+		r := newResolver()
+		t.Setenv("D"+NixPathEnvSuffix, "/mnt/d")
+		r.LookupVolumeGUID = func(string) (string, error) { return drive, nil }
+		got, _, err := r.Format(Windows, Unix, `\\?\Volume{0b0fd6a2}\x`, true, 0)
+		if c.want == "" {
+			if CodeOf(err) != ErrNoMapping {
+				t.Errorf("LookupVolumeGUID() = %q: Format() = %q, %v; want %s", drive, got, err, ErrNoMapping)
+			}
+		} else if err != nil || got != c.want {
+			t.Errorf("LookupVolumeGUID() = %q: Format() = %q, %v; want %q", drive, got, err, c.want)
+		}
+	}
+}